@@ -6,12 +6,14 @@ import (
 	"address-go/helper/logging"
 	"address-go/helper/middleware"
 	"address-go/package/db"
-	"address-go/publisher"
 	"address-go/repository"
 	"address-go/server"
 	"address-go/service"
+	"context"
 	"log"
 	"net/http"
+	"outbox-go"
+	"telemetry-go"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,16 @@ func main() {
 
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "address", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
 		logger.Fatal().Err(errDB).Msg("db failed to connect")
@@ -36,12 +48,19 @@ func main() {
 		_ = sqlDB.Close()
 	}()
 
-	publisher := publisher.NewPublisher()
-	repo := repository.NewRepository(sqlDB.SQLDB, publisher)
+	// events are written to the outbox table and relayed to RabbitMQ until the server shuts down
+	pub := outbox.New(sqlDB.SQLDB, config.RabbitMQURL, "address")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pub.Run(ctx)
+
+	repo := repository.NewRepository(sqlDB.SQLDB, pub)
 	svc := service.NewService(repo)
 	handler := handler.NewHandler(svc)
 
 	router := gin.New()
+	router.Use(telemetry.Middleware("address"))
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
 	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 
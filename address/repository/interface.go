@@ -2,10 +2,11 @@ package repository
 
 import (
 	"address-go/model"
+	"context"
 )
 
 type Repositorier interface {
 	Get(userID int) (res []model.Address, err error)
-	Create(req model.AddressRequest) (res []model.Address, err error)
+	Create(ctx context.Context, req model.AddressRequest) (err error)
 	Delete(addressID int) (err error)
 }
@@ -5,7 +5,6 @@ import (
 	"address-go/publisher"
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 )
@@ -40,19 +39,12 @@ func (repo *repository) Get(userID int) (res []model.Address, err error) {
 	return
 }
 
-func (repo *repository) Create(req model.AddressRequest) (res []model.Address, err error) {
-	// publish data to RabbitMQ
-	err = repo.publisher.Publish(req, "create_address")
+// Create publishes the create_address event, consumer-worker writes it. It returns once the event is
+// stored in the outbox, before consumer-worker has written it.
+func (repo *repository) Create(ctx context.Context, req model.AddressRequest) (err error) {
+	err = repo.publisher.Publish(ctx, req, "create_address")
 	if err != nil {
-		err = fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
-		return
-	}
-
-	time.Sleep(3 * time.Second)
-
-	res, err = repo.Get(req.UserID)
-	if err != nil {
-		return []model.Address{}, errors.New("error get addresses by user id after create")
+		return fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
 	}
 	return
 }
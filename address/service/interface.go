@@ -2,10 +2,11 @@ package service
 
 import (
 	"address-go/model"
+	"context"
 )
 
 type Servicer interface {
 	Get(userID int) (res []model.Address, err error)
-	Create(req model.AddressRequest) (res []model.Address, err error)
+	Create(ctx context.Context, req model.AddressRequest) (err error)
 	Delete(userID, addressID int) (err error)
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"address-go/model"
 	"address-go/repository"
+	"context"
 	"fmt"
 )
 
@@ -20,8 +21,8 @@ func (svc *service) Get(userID int) (res []model.Address, err error) {
 	return svc.repo.Get(userID)
 }
 
-func (svc *service) Create(req model.AddressRequest) (res []model.Address, err error) {
-	return svc.repo.Create(req)
+func (svc *service) Create(ctx context.Context, req model.AddressRequest) (err error) {
+	return svc.repo.Create(ctx, req)
 }
 
 func (svc *service) Delete(userID, addressID int) (err error) {
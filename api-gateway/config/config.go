@@ -1,17 +1,34 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Debug bool   `mapstructure:"DEBUG"`
-	Port  string `mapstructure:"PORT"`
+	Debug        bool   `mapstructure:"DEBUG"`
+	Port         string `mapstructure:"PORT"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	// MetricsAddr is where /metrics is served, apart from the public PORT.
+	MetricsAddr string `mapstructure:"METRICS_ADDR"`
 
-	JWTSecretKey string `mapstructure:"JWT_SECRET_KEY"`
+	// JWKSURL is the JWKS endpoint of the auth service the tokens are verified with.
+	JWKSURL      string        `mapstructure:"JWKS_URL"`
+	JWKSCacheTTL time.Duration `mapstructure:"JWKS_CACHE_TTL"`
 
-	Database `mapstructure:",squash"`
-	Redis    `mapstructure:",squash"`
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For is trusted.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	Database  `mapstructure:",squash"`
+	Redis     `mapstructure:",squash"`
+	RabbitMQ  `mapstructure:",squash"`
+	Proxy     `mapstructure:",squash"`
+	Breaker   `mapstructure:",squash"`
+	Retry     `mapstructure:",squash"`
+	RateLimit `mapstructure:",squash"`
+	Cache     `mapstructure:",squash"`
 }
 
 type Database struct {
@@ -28,6 +45,41 @@ type Redis struct {
 	DB         int    `mapstructure:"REDIS_DB"`
 }
 
+type RabbitMQ struct {
+	URL string `mapstructure:"RABBITMQ_URL"`
+}
+
+type Proxy struct {
+	DialTimeout           time.Duration `mapstructure:"PROXY_DIAL_TIMEOUT"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"PROXY_RESPONSE_HEADER_TIMEOUT"`
+	IdleConnTimeout       time.Duration `mapstructure:"PROXY_IDLE_CONN_TIMEOUT"`
+	MaxIdleConnsPerHost   int           `mapstructure:"PROXY_MAX_IDLE_CONNS_PER_HOST"`
+}
+
+type Breaker struct {
+	FailureThreshold int           `mapstructure:"BREAKER_FAILURE_THRESHOLD"`
+	OpenTimeout      time.Duration `mapstructure:"BREAKER_OPEN_TIMEOUT"`
+	HalfOpenProbes   int           `mapstructure:"BREAKER_HALF_OPEN_PROBES"`
+}
+
+type Retry struct {
+	MaxRetries int           `mapstructure:"RETRY_MAX_RETRIES"`
+	Backoff    time.Duration `mapstructure:"RETRY_BACKOFF"`
+}
+
+// RateLimit is the limit of the endpoints whose api_managements entry leaves it empty.
+type RateLimit struct {
+	IP     int           `mapstructure:"RATE_LIMIT_IP"`
+	User   int           `mapstructure:"RATE_LIMIT_USER"`
+	Route  int           `mapstructure:"RATE_LIMIT_ROUTE"`
+	Window time.Duration `mapstructure:"RATE_LIMIT_WINDOW"`
+}
+
+type Cache struct {
+	// MaxBody is the size in bytes of the largest response that is cached.
+	MaxBody int `mapstructure:"CACHE_MAX_BODY"`
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigFile(".env")
 
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"api-gateway-go/helper/response"
+	"api-gateway-go/model"
+	"api-gateway-go/service"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteHandler is the admin API of the routes the gateway proxies, the APIManagement rows.
+type RouteHandler struct {
+	svc service.ShortenServiceI
+}
+
+func NewRouteHandler(svc service.ShortenServiceI) RouteHandlerI {
+	h := new(RouteHandler)
+	h.svc = svc
+	return h
+}
+
+func (h *RouteHandler) List(ctx *gin.Context) {
+	apiManagements, errSvc := h.svc.List()
+	if errSvc != nil {
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", apiManagements)
+}
+
+func (h *RouteHandler) GetByID(ctx *gin.Context) {
+	id, ok := routeID(ctx)
+	if !ok {
+		return
+	}
+
+	apiManagement, errSvc := h.svc.GetByID(id)
+	if errSvc != nil {
+		routeErr(ctx, errSvc)
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", apiManagement)
+}
+
+// Update replaces the route with the body, which is the one of POST /s/shorten.
+func (h *RouteHandler) Update(ctx *gin.Context) {
+	id, ok := routeID(ctx)
+	if !ok {
+		return
+	}
+
+	shortenReq := new(model.ShortenReq)
+	if errJSON := ctx.ShouldBindJSON(&shortenReq); errJSON != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", errJSON.Error())
+		return
+	}
+
+	apiManagement, errSvc := h.svc.Update(id, shortenReq)
+	if errSvc != nil {
+		routeErr(ctx, errSvc)
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", apiManagement)
+}
+
+func (h *RouteHandler) Disable(ctx *gin.Context) {
+	h.setAvailable(ctx, false)
+}
+
+func (h *RouteHandler) Enable(ctx *gin.Context) {
+	h.setAvailable(ctx, true)
+}
+
+func (h *RouteHandler) setAvailable(ctx *gin.Context, isAvailable bool) {
+	id, ok := routeID(ctx)
+	if !ok {
+		return
+	}
+
+	apiManagement, errSvc := h.svc.SetAvailable(id, isAvailable)
+	if errSvc != nil {
+		routeErr(ctx, errSvc)
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", apiManagement)
+}
+
+func (h *RouteHandler) Delete(ctx *gin.Context) {
+	id, ok := routeID(ctx)
+	if !ok {
+		return
+	}
+
+	if errSvc := h.svc.Delete(id); errSvc != nil {
+		routeErr(ctx, errSvc)
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", nil)
+}
+
+func routeID(ctx *gin.Context) (uint, bool) {
+	id, errParse := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if errParse != nil || id == 0 {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", "invalid route id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func routeErr(ctx *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		response.NewJSONResErr(ctx, http.StatusNotFound, "", "route not found")
+		return
+	}
+	_ = ctx.Error(err)
+	response.NewJSONResErr(ctx, http.StatusInternalServerError, "", err.Error())
+}
@@ -0,0 +1,204 @@
+package handler_test
+
+import (
+	"api-gateway-go/handler"
+	"api-gateway-go/mocks"
+	"api-gateway-go/model"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouteHandler_Update(t *testing.T) {
+	isAvailable, needBypass := false, true
+	validReq := &model.ShortenReq{
+		APIName:     "wislists",
+		ServiceName: "wislists",
+		EndpointURL: "http://localhost:5013/wislists",
+		IsAvailable: &isAvailable,
+		NeedBypass:  &needBypass,
+	}
+	tests := []struct {
+		name       string
+		id         string
+		req        *model.ShortenReq
+		ucRes      *model.APIManagement
+		ucErr      error
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			id:         "1",
+			req:        validReq,
+			ucRes:      &model.APIManagement{ID: 1, APIName: "wislists", NeedBypass: true},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "failed invalid id",
+			id:         "abc",
+			req:        validReq,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "failed invalid body",
+			id:         "1",
+			req:        &model.ShortenReq{APIName: "wislists"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "failed not found",
+			id:         "1",
+			req:        validReq,
+			ucErr:      sql.ErrNoRows,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "failed svc error",
+			id:         "1",
+			req:        validReq,
+			ucErr:      errors.New("svc error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockUC := mocks.NewShortenServiceI(t)
+
+			h := handler.NewRouteHandler(mockUC)
+			if tt.ucRes != nil || tt.ucErr != nil {
+				mockUC.On("Update", uint(1), mock.Anything).Return(tt.ucRes, tt.ucErr)
+			}
+
+			jsonBytes, _ := json.Marshal(tt.req)
+			req, errReq := http.NewRequest(http.MethodPut, "/admin/routes/"+tt.id, bytes.NewBuffer(jsonBytes))
+			assert.NoError(t, errReq)
+
+			res := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(res)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+			h.Update(c)
+
+			assert.Equal(t, tt.wantStatus, res.Code, "status code got: %v, want %v", res.Code, tt.wantStatus)
+		})
+	}
+}
+
+func TestRouteHandler_SetAvailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		disable     bool
+		ucRes       *model.APIManagement
+		ucErr       error
+		wantStatus  int
+		isAvailable bool
+	}{
+		{
+			name:        "success - disable",
+			disable:     true,
+			ucRes:       &model.APIManagement{ID: 1, IsAvailable: false},
+			wantStatus:  http.StatusOK,
+			isAvailable: false,
+		},
+		{
+			name:        "success - enable",
+			ucRes:       &model.APIManagement{ID: 1, IsAvailable: true},
+			wantStatus:  http.StatusOK,
+			isAvailable: true,
+		},
+		{
+			name:        "failed not found",
+			disable:     true,
+			ucErr:       sql.ErrNoRows,
+			wantStatus:  http.StatusNotFound,
+			isAvailable: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockUC := mocks.NewShortenServiceI(t)
+
+			h := handler.NewRouteHandler(mockUC)
+			mockUC.On("SetAvailable", uint(1), tt.isAvailable).Return(tt.ucRes, tt.ucErr)
+
+			req, errReq := http.NewRequest(http.MethodPost, "/admin/routes/1", nil)
+			assert.NoError(t, errReq)
+
+			res := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(res)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			if tt.disable {
+				h.Disable(c)
+			} else {
+				h.Enable(c)
+			}
+
+			assert.Equal(t, tt.wantStatus, res.Code, "status code got: %v, want %v", res.Code, tt.wantStatus)
+		})
+	}
+}
+
+func TestRouteHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		ucErr      error
+		callSvc    bool
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			id:         "1",
+			callSvc:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "failed zero id",
+			id:         "0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "failed not found",
+			id:         "1",
+			ucErr:      sql.ErrNoRows,
+			callSvc:    true,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockUC := mocks.NewShortenServiceI(t)
+
+			h := handler.NewRouteHandler(mockUC)
+			if tt.callSvc {
+				mockUC.On("Delete", uint(1)).Return(tt.ucErr)
+			}
+
+			req, errReq := http.NewRequest(http.MethodDelete, "/admin/routes/"+tt.id, nil)
+			assert.NoError(t, errReq)
+
+			res := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(res)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+			h.Delete(c)
+
+			assert.Equal(t, tt.wantStatus, res.Code, "status code got: %v, want %v", res.Code, tt.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type RouteHandlerI interface {
+	List(ctx *gin.Context)
+	GetByID(ctx *gin.Context)
+	Update(ctx *gin.Context)
+	Disable(ctx *gin.Context)
+	Enable(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
@@ -1,27 +1,44 @@
 package handler
 
 import (
+	"api-gateway-go/helper/breaker"
 	"api-gateway-go/helper/response"
-	"api-gateway-go/helper/timeout"
 	"api-gateway-go/model"
 	"api-gateway-go/service"
-	"encoding/json"
-	"io"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httputil"
+	neturl "net/url"
+	"strconv"
+	"sync"
+	"telemetry-go"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ShortenHandler struct {
-	svc service.ShortenServiceI
+	svc       service.ShortenServiceI
+	transport http.RoundTripper
+	breakers  *breaker.Group
 }
 
-func NewShortenHandler(svc service.ShortenServiceI) ShortenHandlerI {
+func NewShortenHandler(svc service.ShortenServiceI, transport http.RoundTripper, breakers *breaker.Group) ShortenHandlerI {
 	h := new(ShortenHandler)
 	h.svc = svc
+	h.transport = transport
+	h.breakers = breakers
 	return h
 }
 
+// Get proxies the request to the endpoint url resolved by HashedURLConverter. Bodies are
+// streamed both ways and the service's status, redirects included, reaches the client as is.
+// While the circuit breaker of the service, or of the endpoint when it has settings of its
+// own, is open it answers the endpoint's fallback, 503 by default, without calling the service.
 func (h *ShortenHandler) Get(ctx *gin.Context) {
 	urlCtx, _ := ctx.Get("url")
 	url, _ := urlCtx.(string)
@@ -29,57 +46,144 @@ func (h *ShortenHandler) Get(ctx *gin.Context) {
 	userIDCtx, _ := ctx.Get("userID")
 	userID, _ := userIDCtx.(string)
 
+	userRoleCtx, _ := ctx.Get("userRole")
+	userRole, _ := userRoleCtx.(string)
+
 	needBypassCtx, _ := ctx.Get("need_bypass")
 	needBypass, _ := needBypassCtx.(bool)
 
-	timeoutCtx, cancel := timeout.NewCtxTimeout()
-	defer cancel()
+	serviceNameCtx, _ := ctx.Get("service_name")
+	serviceName, _ := serviceNameCtx.(string)
 
-	reqBody := ctx.Request.Body
-	req, errReq := http.NewRequestWithContext(timeoutCtx, ctx.Request.Method, url, reqBody)
-	if errReq != nil {
-		_ = ctx.Error(errReq)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errReq.Error())
+	target, errURL := neturl.Parse(url)
+	if errURL != nil {
+		_ = ctx.Error(errURL)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errURL.Error())
 		return
 	}
-	if !needBypass {
-		req.Header.Add("user-id", userID)
-	}
 
-	resp, errResp := http.DefaultClient.Do(req)
-	if errResp != nil {
-		_ = ctx.Error(errResp)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errResp.Error())
+	cb := h.breaker(ctx, serviceName)
+	done, errOpen := cb.Allow()
+	if errOpen != nil {
+		_ = ctx.Error(fmt.Errorf("%s: %w", serviceName, errOpen))
+		telemetry.UpstreamError(serviceName, "circuit_open")
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(cb.RetryAfter().Seconds()))))
+		fallback(ctx, serviceName)
 		return
 	}
-	defer resp.Body.Close()
 
-	// Copy the response headers to the gateway response
-	h.copyHeaders(ctx.Writer.Header(), resp.Header)
+	proxy := &httputil.ReverseProxy{
+		// Rewrite already drops the hop-by-hop headers and any X-Forwarded-* the client sent
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL = target
+			pr.Out.Host = ""
+			pr.SetXForwarded()
+
+			// only the gateway tells the services who the user is
+			pr.Out.Header.Del("user-id")
+			pr.Out.Header.Del("user-role")
+			if !needBypass {
+				pr.Out.Header.Set("user-id", userID)
+				pr.Out.Header.Set("user-role", userRole)
+			}
+		},
+		Transport:     h.transport,
+		FlushInterval: 100 * time.Millisecond,
+		BufferPool:    bufferPool,
+		ModifyResponse: func(resp *http.Response) error {
+			unavailable := isUnavailable(resp.StatusCode)
+			if unavailable {
+				telemetry.UpstreamError(serviceName, "unavailable")
+			}
+			done(!unavailable)
+			return nil
+		},
+		ErrorHandler: func(_ http.ResponseWriter, req *http.Request, err error) {
+			// a client that went away says nothing about the service
+			canceled := errors.Is(req.Context().Err(), context.Canceled)
+			done(canceled)
+			_ = ctx.Error(err)
+
+			status, reason := http.StatusBadGateway, "connection"
+			if errors.Is(err, context.DeadlineExceeded) || isTimeout(err) {
+				status, reason = http.StatusGatewayTimeout, "timeout"
+			}
+			if !canceled {
+				telemetry.UpstreamError(serviceName, reason)
+			}
+			response.NewJSONResErr(ctx, status, "", err.Error())
+		},
+	}
+
+	proxy.ServeHTTP(ctx.Writer, ctx.Request)
+}
 
-	respBody, errRead := io.ReadAll(resp.Body)
-	if errRead != nil {
-		_ = ctx.Error(errRead)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errRead.Error())
-		return
+// breaker returns the breaker of the endpoint when its api_managements entry has breaker
+// settings, named after its service and api, else the one its service shares.
+func (h *ShortenHandler) breaker(ctx *gin.Context, serviceName string) *breaker.Breaker {
+	settingsCtx, _ := ctx.Get("breaker")
+	settings, _ := settingsCtx.(model.Breaker)
+	if settings.FailureThreshold == nil && settings.OpenTimeout == nil && settings.HalfOpenProbes == nil {
+		return h.breakers.Get(serviceName)
 	}
 
-	var jsonGRes response.JSONGatewayRes
-	if errJSONUn := json.Unmarshal(respBody, &jsonGRes); errJSONUn != nil {
-		_ = ctx.Error(errJSONUn)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errJSONUn.Error())
+	override := breaker.Settings{}
+	if settings.FailureThreshold != nil {
+		override.FailureThreshold = *settings.FailureThreshold
+	}
+	if settings.OpenTimeout != nil {
+		override.OpenTimeout = time.Duration(*settings.OpenTimeout) * time.Second
+	}
+	if settings.HalfOpenProbes != nil {
+		override.HalfOpenProbes = *settings.HalfOpenProbes
+	}
+	return h.breakers.GetWith(serviceName+"/"+ctx.GetString("api_name"), override)
+}
+
+// fallback answers a request the open breaker rejected with the endpoint's fallback.
+func fallback(ctx *gin.Context, serviceName string) {
+	fallbackCtx, _ := ctx.Get("fallback")
+	fb, _ := fallbackCtx.(model.Fallback)
+
+	status := http.StatusServiceUnavailable
+	if fb.Status != nil {
+		status = *fb.Status
+	}
+	if fb.Body != nil {
+		ctx.Data(status, "application/json; charset=utf-8", []byte(*fb.Body))
 		return
 	}
+	response.NewJSONResErr(ctx, status, "", serviceName+" service is unavailable, try again later")
+}
 
-	ctx.JSON(resp.StatusCode, jsonGRes)
+// isUnavailable tells the statuses of a service that is down or overloaded, counted as
+// failures by the breaker, from the ones of a request it handled, 500 included.
+func isUnavailable(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
 }
 
-func (h *ShortenHandler) copyHeaders(dst http.Header, src http.Header) {
-	for key, values := range src {
-		for _, value := range values {
-			dst.Add(key, value)
-		}
-	}
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// bufferPool shares the 32KB buffers ReverseProxy copies bodies with across requests.
+var bufferPool httputil.BufferPool = &pool{p: sync.Pool{New: func() any { return make([]byte, 32*1024) }}}
+
+type pool struct {
+	p sync.Pool
+}
+
+func (p *pool) Get() []byte {
+	b, _ := p.p.Get().([]byte)
+	return b
+}
+
+func (p *pool) Put(b []byte) {
+	//nolint:staticcheck // the slice header allocation is cheaper than the buffer
+	p.p.Put(b)
 }
 
 func (h *ShortenHandler) Shorten(ctx *gin.Context) {
@@ -2,20 +2,22 @@ package handler_test
 
 import (
 	"api-gateway-go/handler"
+	"api-gateway-go/helper/breaker"
 	"api-gateway-go/mocks"
 	"api-gateway-go/model"
+	"api-gateway-go/package/transport"
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -77,7 +79,7 @@ func TestShortenHandler_Shorten(t *testing.T) {
 			gin.SetMode(gin.TestMode)
 			mockUC := mocks.NewShortenServiceI(t)
 
-			h := handler.NewShortenHandler(mockUC)
+			h := handler.NewShortenHandler(mockUC, http.DefaultTransport, breaker.NewGroup(breaker.Settings{}))
 			if tt.ucRes != nil || tt.ucErr != nil {
 				mockUC.On("Create", mock.Anything).Return(tt.ucRes, tt.ucErr)
 			}
@@ -102,141 +104,249 @@ func TestShortenHandler_Shorten(t *testing.T) {
 	}
 }
 
-type brokenReader struct{}
+// closeNotifyRecorder is the CloseNotifier gin's writer asserts its ResponseWriter is when
+// ReverseProxy asks for it, httptest.ResponseRecorder isn't one.
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+}
 
-func (br brokenReader) Read(_ []byte) (int, error) {
-	return 0, errors.New("read error")
+func (closeNotifyRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
 }
 
 func TestShortenHandler_Get(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff}
+
+	var flaky atomic.Int32
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message":"success"}`))
+		case "/not-found":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		case "/redirect":
+			http.Redirect(w, r, "https://accounts.google.com/o/oauth2/auth?state=abc", http.StatusTemporaryRedirect)
+		case "/echo":
+			// report what reached the service
+			w.Header().Set("X-User-ID", r.Header.Get("user-id"))
+			w.Header().Set("X-User-Role", r.Header.Get("user-role"))
+			w.Header().Set("X-Got-Forwarded-For", r.Header.Get("X-Forwarded-For"))
+			w.Header().Set("X-Got-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+			w.Header().Set("X-Got-Secret", r.Header.Get("X-Secret"))
+			w.Header().Set("X-Got-Query", r.URL.RawQuery)
+			_, _ = io.Copy(w, r.Body)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/flaky":
+			// unavailable on every other call
+			if flaky.Add(1)%2 == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"message":"success"}`))
+		}
+	}))
+	defer upstream.Close()
+
 	type args struct {
-		hashedURL string
+		method     string
+		path       string
+		body       string
+		header     http.Header
+		userID     string
+		userRole   string
+		needBypass bool
+		// breakerOpen opens the service's breaker before the request
+		breakerOpen bool
+		breaker     model.Breaker
+		fallback    model.Fallback
 	}
+	ok, threshold, emptyList := http.StatusOK, 1, `{"data":[]}`
 	tests := []struct {
 		name       string
 		args       args
-		mockHTTP   func(req *http.Request) (*http.Response, error)
 		wantStatus int
+		wantBody   []byte
+		wantHeader http.Header
 	}{
-		// TODO: Add test cases.
 		{
-			name: "success",
-			args: args{
-				hashedURL: "hashedURL1",
-			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				resp := httpmock.NewStringResponse(200, `{"message":"success"}`)
-				resp.Header.Set("Content-Type", "application/json")
-				return resp, nil
-			},
+			name:       "success",
+			args:       args{path: "/json"},
 			wantStatus: http.StatusOK,
+			wantBody:   []byte(`{"message":"success"}`),
 		},
 		{
-			name: "not_found",
-			args: args{
-				hashedURL: "hashedURL2",
-			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				resp := httpmock.NewStringResponse(404, `{"message":"Not Found"}`)
-				resp.Header.Set("Content-Type", "application/json")
-				return resp, nil
-			},
+			name:       "status passed through",
+			args:       args{path: "/not-found"},
 			wantStatus: http.StatusNotFound,
+			wantBody:   []byte(`{"message":"Not Found"}`),
 		},
 		{
-			name: "internal_server_error",
-			args: args{
-				hashedURL: "hashedURL3",
-			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				resp := httpmock.NewStringResponse(500, `{"message":"Internal Server Error"}`)
-				resp.Header.Set("Content-Type", "application/json")
-				return resp, nil
-			},
-			wantStatus: http.StatusInternalServerError,
+			name:       "non json body",
+			args:       args{path: "/image"},
+			wantStatus: http.StatusOK,
+			wantBody:   png,
+			wantHeader: http.Header{"Content-Type": {"image/png"}},
+		},
+		{
+			name:       "redirect not followed",
+			args:       args{path: "/redirect"},
+			wantStatus: http.StatusTemporaryRedirect,
+			wantHeader: http.Header{"Location": {"https://accounts.google.com/o/oauth2/auth?state=abc"}},
 		},
 		{
-			name: "invalid_json_response",
+			name: "request forwarded",
 			args: args{
-				hashedURL: "hashedURL4",
+				path: "/echo?page=2",
+				body: `{"name":"red"}`,
+				header: http.Header{
+					"User-Id":         {"99"},
+					"User-Role":       {"admin"},
+					"X-Forwarded-For": {"10.0.0.1"},
+					"Connection":      {"X-Secret"},
+					"X-Secret":        {"hop"},
+				},
+				userID:   "1",
+				userRole: "user",
 			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				resp := httpmock.NewStringResponse(200, `{"message":}`)
-				resp.Header.Set("Content-Type", "application/json")
-				return resp, nil
+			wantStatus: http.StatusOK,
+			wantBody:   []byte(`{"name":"red"}`),
+			wantHeader: http.Header{
+				"X-User-Id":            {"1"},
+				"X-User-Role":          {"user"},
+				"X-Got-Forwarded-For":  {"192.0.2.1"},
+				"X-Got-Forwarded-Host": {"gateway.test"},
+				"X-Got-Secret":         {""},
+				"X-Got-Query":          {"page=2"},
 			},
-			wantStatus: http.StatusInternalServerError,
 		},
 		{
-			// give on this one lol
-			name: "timeout",
+			name: "bypass doesn't trust the client's user-id",
 			args: args{
-				hashedURL: "hashedURL5",
+				path:       "/echo",
+				header:     http.Header{"User-Id": {"99"}, "User-Role": {"admin"}},
+				needBypass: true,
 			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				time.Sleep(30 * time.Second)
-				return nil, context.DeadlineExceeded
-			},
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusOK,
+			wantHeader: http.Header{"X-User-Id": {""}, "X-User-Role": {""}},
 		},
 		{
-			name: "http_request_error",
+			name:       "response timeout",
+			args:       args{path: "/slow"},
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "service down",
+			args:       args{path: "/json"},
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "idempotent request retried",
+			args:       args{method: http.MethodGet, path: "/flaky"},
+			wantStatus: http.StatusOK,
+			wantBody:   []byte(`{"message":"success"}`),
+		},
+		{
+			name:       "other request not retried",
+			args:       args{path: "/flaky"},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "breaker open",
+			args:       args{path: "/json", breakerOpen: true},
+			wantStatus: http.StatusServiceUnavailable,
+			wantHeader: http.Header{"Retry-After": {"30"}},
+		},
+		{
+			name: "breaker open with a fallback",
 			args: args{
-				hashedURL: "hashedURL6",
-			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				return nil, errors.New("http request error")
+				path:        "/json",
+				breakerOpen: true,
+				fallback:    model.Fallback{Status: &ok, Body: &emptyList},
 			},
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusOK,
+			wantBody:   []byte(emptyList),
+			wantHeader: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
 		},
 		{
-			name: "read_body_error",
+			name: "endpoint with a breaker of its own",
 			args: args{
-				hashedURL: "hashedURL7",
-			},
-			mockHTTP: func(req *http.Request) (*http.Response, error) {
-				resp := httpmock.NewStringResponse(200, `{"message":"success"}`)
-				resp.Header.Set("Content-Type", "application/json")
-				resp.Body = io.NopCloser(brokenReader{})
-				return resp, nil
+				path:        "/json",
+				breakerOpen: true,
+				breaker:     model.Breaker{FailureThreshold: &threshold},
 			},
-			wantStatus: http.StatusInternalServerError,
+			wantStatus: http.StatusOK,
+			wantBody:   []byte(`{"message":"success"}`),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gin.SetMode(gin.TestMode)
-			// Activate the httpmock
-			httpmock.Activate()
-			defer httpmock.DeactivateAndReset()
 
-			url := "http://test.com/" + tt.args.hashedURL
+			url := upstream.URL + tt.args.path
+			if tt.wantStatus == http.StatusBadGateway {
+				url = "http://127.0.0.1:1" + tt.args.path
+			}
 
 			res := httptest.NewRecorder()
-			c, _ := gin.CreateTestContext(res)
+			c, _ := gin.CreateTestContext(closeNotifyRecorder{res})
 
-			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
-			defer cancel()
+			method := tt.args.method
+			if method == "" {
+				method = http.MethodPost
+			}
 
-			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			req := httptest.NewRequest(method, "http://gateway.test/CUbICN8VgNk", strings.NewReader(tt.args.body))
+			for key, values := range tt.args.header {
+				req.Header[key] = values
+			}
 			c.Request = req
 
-			// Setting the URL as a context value
 			c.Set("url", url)
+			c.Set("userID", tt.args.userID)
+			c.Set("userRole", tt.args.userRole)
+			c.Set("need_bypass", tt.args.needBypass)
+			c.Set("service_name", "product")
+			c.Set("api_name", "products")
+			c.Set("breaker", tt.args.breaker)
+			c.Set("fallback", tt.args.fallback)
 
-			mockUC := mocks.NewShortenServiceI(t)
+			breakers := breaker.NewGroup(breaker.Settings{FailureThreshold: 1, OpenTimeout: 30 * time.Second})
+			if tt.args.breakerOpen {
+				done, errAllow := breakers.Get("product").Allow()
+				assert.NoError(t, errAllow)
+				done(false)
+			}
 
-			h := handler.NewShortenHandler(mockUC)
+			mockUC := mocks.NewShortenServiceI(t)
 
-			// Set up httpmock to intercept the HTTP request and return a successful response
-			httpmock.RegisterResponder(http.MethodGet, url, tt.mockHTTP)
+			h := handler.NewShortenHandler(
+				mockUC,
+				transport.NewRetry(transport.NewTransport(time.Second, 100*time.Millisecond, 0, 0), 2, time.Millisecond),
+				breakers,
+			)
 
 			h.Get(c)
+			// gin writes a status without a body once the handlers return
+			c.Writer.WriteHeaderNow()
 
 			t.Log(res.Code, res.Body.String())
 			t.Log("------------------------------------\n")
 
 			assert.Equal(t, tt.wantStatus, res.Code, "status code got: %v, want %v", res.Code, tt.wantStatus)
+			if tt.wantBody != nil {
+				assert.Equal(t, tt.wantBody, res.Body.Bytes())
+			}
+			for key := range tt.wantHeader {
+				assert.Equal(t, tt.wantHeader.Get(key), res.Header().Get(key), "header %s", key)
+			}
 		})
 	}
 }
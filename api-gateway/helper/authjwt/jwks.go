@@ -0,0 +1,145 @@
+package authjwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefetch is how often a token with a kid the gateway doesn't know can fetch the JWKS.
+const minRefetch = 10 * time.Second
+
+var (
+	ErrUnknownKey      = errors.New("unknown signing key")
+	ErrKeysUnavailable = errors.New("signing keys unavailable")
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	X   string `json:"x"`
+}
+
+// JWKS is the public keys of the auth service, fetched from its JWKS endpoint and cached
+// for ttl. A token of a key that isn't cached yet fetches the set again, so a rotation
+// works even before the cache expires. When the auth service can't be reached the keys
+// already cached are kept.
+type JWKS struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]ed25519.PublicKey
+	fetchedAt time.Time
+	triedAt   time.Time
+}
+
+func NewJWKS(url string, ttl time.Duration, client *http.Client) *JWKS {
+	return &JWKS{url: url, ttl: ttl, client: client, keys: map[string]ed25519.PublicKey{}}
+}
+
+// Keyfunc finds the key of a token for jwt.Parse.
+func (j *JWKS) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, ErrUnknownKey
+		}
+		return j.key(ctx, kid)
+	}
+}
+
+func (j *JWKS) key(ctx context.Context, kid string) (ed25519.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.keys[kid]
+	stale := time.Since(j.fetchedAt) >= j.ttl
+	if ok && !stale {
+		return key, nil
+	}
+	if time.Since(j.triedAt) < minRefetch {
+		switch {
+		case ok:
+			return key, nil
+		case j.fetchedAt.Before(j.triedAt):
+			return nil, ErrKeysUnavailable
+		default:
+			return nil, ErrUnknownKey
+		}
+	}
+
+	if errFetch := j.fetch(ctx); errFetch != nil {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrKeysUnavailable, errFetch)
+	}
+
+	key, ok = j.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Refresh fetches the keys now, at start so the first requests don't wait on it.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.fetch(ctx)
+}
+
+func (j *JWKS) fetch(ctx context.Context) error {
+	j.triedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if errReq != nil {
+		return errReq
+	}
+	res, errDo := j.client.Do(req)
+	if errDo != nil {
+		return errDo
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: status %d", res.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if errDecode := json.NewDecoder(res.Body).Decode(&set); errDecode != nil {
+		return fmt.Errorf("jwks: %w", errDecode)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "OKP" || k.Crv != "Ed25519" {
+			continue
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		if errX != nil || len(x) != ed25519.PublicKeySize {
+			continue
+		}
+		keys[k.Kid] = ed25519.PublicKey(x)
+	}
+
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,72 @@
+package authjwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKS(t *testing.T) {
+	public1, private1, _ := ed25519.GenerateKey(rand.Reader)
+	public2, private2, _ := ed25519.GenerateKey(rand.Reader)
+
+	published := []jwk{{Kty: "OKP", Crv: "Ed25519", Kid: "key1", X: base64.RawURLEncoding.EncodeToString(public1)}}
+	fetches, down := 0, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": published})
+	}))
+	defer srv.Close()
+
+	jwks := NewJWKS(srv.URL, time.Minute, srv.Client())
+	require.NoError(t, jwks.Refresh(context.Background()))
+
+	parse := func(kid string, key ed25519.PrivateKey) error {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{ID: "jti"})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		_, err = jwt.Parse(signed, jwks.Keyfunc(context.Background()),
+			jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+		return err
+	}
+
+	// a cached key doesn't fetch again
+	require.NoError(t, parse("key1", private1))
+	require.Equal(t, 1, fetches)
+
+	// a key of another kid doesn't verify
+	require.ErrorIs(t, parse("key1", private2), jwt.ErrTokenSignatureInvalid)
+
+	// the kid of a rotation isn't known yet, it fetches once it may again
+	published = append(published, jwk{Kty: "OKP", Crv: "Ed25519", Kid: "key2", X: base64.RawURLEncoding.EncodeToString(public2)})
+	require.ErrorIs(t, parse("key2", private2), ErrUnknownKey)
+	require.Equal(t, 1, fetches)
+
+	jwks.triedAt = jwks.triedAt.Add(-minRefetch)
+	require.NoError(t, parse("key2", private2))
+	require.Equal(t, 2, fetches)
+
+	// the cached keys are used when the auth service is down
+	down = true
+	jwks.fetchedAt = jwks.fetchedAt.Add(-time.Minute)
+	jwks.triedAt = jwks.triedAt.Add(-minRefetch)
+	require.NoError(t, parse("key1", private1))
+	require.Equal(t, 3, fetches)
+	require.ErrorIs(t, parse("key3", private1), ErrKeysUnavailable)
+
+	require.ErrorIs(t, parse("", private1), ErrUnknownKey)
+}
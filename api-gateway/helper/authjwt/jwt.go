@@ -1,11 +1,31 @@
 package authjwt
 
 import (
+	"context"
+	"errors"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
 )
 
+// AudienceAccess is the audience of the auth service's access tokens.
+const AudienceAccess = "access"
+
 type CustomClaims struct {
 	UserID   string `json:"user_id"`
 	UserRole string `json:"user_role"`
 	jwt.RegisteredClaims
 }
+
+// IsRevoked tells whether a logout revoked the token with the id (jti), the auth service
+// denylists the access tokens of a session it ends until they expire.
+func IsRevoked(ctx context.Context, rdb *redis.Client, tokenID string) (bool, error) {
+	errGet := rdb.Get(ctx, "access_token_denylist:"+tokenID).Err()
+	if errors.Is(errGet, redis.Nil) {
+		return false, nil
+	}
+	if errGet != nil {
+		return false, errGet
+	}
+	return true, nil
+}
@@ -0,0 +1,224 @@
+// Package breaker keeps the gateway from waiting on a service that is down. A Breaker
+// opens after a run of failed requests, rejects everything while open, then lets a few
+// probe requests through and closes again once they succeed.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type Settings struct {
+	// FailureThreshold is the number of failures in a row that opens the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker rejects requests before probing the service.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of requests let through while half-open, all of them
+	// must succeed to close the breaker.
+	HalfOpenProbes int
+	// OnStateChange is called with the breaker's name on every transition, it may be nil.
+	// It runs under the breaker's lock and must not call back into it.
+	OnStateChange func(name string, from, to State)
+}
+
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	probes    int
+	successes int
+	openedAt  time.Time
+	// generation changes on every transition so a request started in an earlier state
+	// doesn't count towards the current one
+	generation uint64
+}
+
+func New(name string, settings Settings) *Breaker {
+	return &Breaker{
+		name:     name,
+		settings: settings.withDefaults(),
+		now:      time.Now,
+	}
+}
+
+func (s Settings) withDefaults() Settings {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = 30 * time.Second
+	}
+	if s.HalfOpenProbes <= 0 {
+		s.HalfOpenProbes = 1
+	}
+	return s
+}
+
+// Allow asks to send a request. It returns ErrOpen when the request must fail fast,
+// otherwise done has to be called once with whether the service handled it.
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(HalfOpen)
+	}
+
+	switch b.state {
+	case Open:
+		return nil, ErrOpen
+	case HalfOpen:
+		if b.probes >= b.settings.HalfOpenProbes {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	return func(success bool) { b.done(generation, success) }, nil
+}
+
+func (b *Breaker) done(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		if !success {
+			b.setState(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenProbes {
+			b.setState(Closed)
+		}
+	}
+}
+
+// RetryAfter is how long until the breaker lets a request through again, 0 when it does now.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if wait := b.settings.OpenTimeout - b.now().Sub(b.openedAt); wait > 0 {
+			return wait
+		}
+	case HalfOpen:
+		// the probes are in flight, their outcome is only known once they're back
+		if b.probes >= b.settings.HalfOpenProbes {
+			return time.Second
+		}
+	}
+	return 0
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// setState must be called with mu held.
+func (b *Breaker) setState(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if to == Open {
+		b.openedAt = b.now()
+	}
+
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.name, from, to)
+	}
+}
+
+// Group holds one Breaker per upstream service, created the first time it is asked for.
+type Group struct {
+	settings Settings
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewGroup(settings Settings) *Group {
+	return &Group{
+		settings: settings,
+		breakers: map[string]*Breaker{},
+	}
+}
+
+func (g *Group) Get(name string) *Breaker {
+	return g.GetWith(name, Settings{})
+}
+
+// GetWith is Get for a breaker whose FailureThreshold, OpenTimeout and HalfOpenProbes
+// replace the group's where they are set. A breaker asked for with other settings than
+// the ones it has is replaced by a closed one, so a changed endpoint takes effect.
+func (g *Group) GetWith(name string, override Settings) *Breaker {
+	settings := g.settings
+	if override.FailureThreshold > 0 {
+		settings.FailureThreshold = override.FailureThreshold
+	}
+	if override.OpenTimeout > 0 {
+		settings.OpenTimeout = override.OpenTimeout
+	}
+	if override.HalfOpenProbes > 0 {
+		settings.HalfOpenProbes = override.HalfOpenProbes
+	}
+	settings = settings.withDefaults()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.breakers[name]
+	if !ok || b.settings.FailureThreshold != settings.FailureThreshold ||
+		b.settings.OpenTimeout != settings.OpenTimeout ||
+		b.settings.HalfOpenProbes != settings.HalfOpenProbes {
+		b = New(name, settings)
+		g.breakers[name] = b
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2023, 6, 6, 10, 20, 0, 0, time.UTC)
+	b := New("product", Settings{FailureThreshold: 2, OpenTimeout: 10 * time.Second, HalfOpenProbes: 1})
+	b.now = func() time.Time { return now }
+
+	request := func(success bool) error {
+		done, err := b.Allow()
+		if err != nil {
+			return err
+		}
+		done(success)
+		return nil
+	}
+
+	// a success resets the run of failures
+	require.NoError(t, request(false))
+	require.NoError(t, request(true))
+	require.NoError(t, request(false))
+	require.Equal(t, Closed, b.State())
+
+	require.NoError(t, request(false))
+	require.Equal(t, Open, b.State())
+	require.ErrorIs(t, request(true), ErrOpen)
+	require.Equal(t, 10*time.Second, b.RetryAfter())
+
+	// half-open lets a single probe through and a failed one opens it again
+	now = now.Add(10 * time.Second)
+	done, err := b.Allow()
+	require.NoError(t, err)
+	require.Equal(t, HalfOpen, b.State())
+	require.ErrorIs(t, request(true), ErrOpen)
+	done(false)
+	require.Equal(t, Open, b.State())
+
+	now = now.Add(10 * time.Second)
+	require.NoError(t, request(true))
+	require.Equal(t, Closed, b.State())
+}
+
+func TestBreakerStaleDone(t *testing.T) {
+	b := New("product", Settings{FailureThreshold: 1})
+
+	// started while closed, finished after the breaker opened and closed again
+	stale, err := b.Allow()
+	require.NoError(t, err)
+	require.NoError(t, func() error { done, err := b.Allow(); done(false); return err }())
+	require.Equal(t, Open, b.State())
+
+	b.mu.Lock()
+	b.setState(Closed)
+	b.mu.Unlock()
+
+	stale(false)
+	require.Equal(t, Closed, b.State())
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(Settings{FailureThreshold: 1})
+	require.Same(t, g.Get("product"), g.Get("product"))
+	require.NotSame(t, g.Get("product"), g.Get("cart"))
+
+	done, err := g.Get("product").Allow()
+	require.NoError(t, err)
+	done(false)
+	require.Equal(t, Open, g.Get("product").State())
+	require.Equal(t, Closed, g.Get("cart").State())
+}
+
+func TestGroup_GetWith(t *testing.T) {
+	g := NewGroup(Settings{FailureThreshold: 5})
+	b := g.GetWith("product/login", Settings{FailureThreshold: 1})
+	require.Same(t, b, g.GetWith("product/login", Settings{FailureThreshold: 1}))
+	require.Equal(t, 1, b.settings.FailureThreshold)
+	require.Equal(t, 30*time.Second, b.settings.OpenTimeout)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false)
+	require.Equal(t, Open, b.State())
+
+	// the endpoint's settings changed
+	changed := g.GetWith("product/login", Settings{FailureThreshold: 3})
+	require.NotSame(t, b, changed)
+	require.Equal(t, Closed, changed.State())
+	require.Equal(t, 3, changed.settings.FailureThreshold)
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"api-gateway-go/helper/respcache"
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedHeaders are the headers of a response that are served again from the cache, the
+// rest belong to the request that got it: cookies, rate limits, request ids.
+var cachedHeaders = []string{"Content-Type", "Content-Encoding", "Content-Language", "Last-Modified"}
+
+// Cache serves the GET requests of an endpoint with a cache_ttl from Redis. Responses are
+// cached per path and query, and per user unless the endpoint bypasses authentication.
+// The response that fills the cache and the ones served from it carry an ETag, the
+// service's own or one of its body, an If-None-Match that matches it gets a 304.
+// Responses over maxBody bytes are passed through without being cached.
+func Cache(cache *respcache.Cache, maxBody int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		cacheTTLCtx, _ := ctx.Get("cache_ttl")
+		cacheTTL, _ := cacheTTLCtx.(int)
+		if ctx.Request.Method != http.MethodGet || cacheTTL <= 0 {
+			ctx.Next()
+			return
+		}
+
+		serviceName := ctx.GetString("service_name")
+		userID := ""
+		if !ctx.GetBool("need_bypass") {
+			userID = ctx.GetString("userID")
+		}
+
+		key, errKey := cache.Key(ctx.Request.Context(), serviceName, ctx.Param("hash"), ctx.Request.URL.RawQuery, userID)
+		if errKey != nil {
+			// without Redis the request goes to the service
+			_ = ctx.Error(errKey)
+			ctx.Next()
+			return
+		}
+
+		entry, errGet := cache.Get(ctx.Request.Context(), key)
+		if errGet != nil {
+			_ = ctx.Error(errGet)
+		}
+		if entry != nil {
+			serveCached(ctx, entry)
+			return
+		}
+
+		writer := &captureWriter{ResponseWriter: ctx.Writer, limit: maxBody}
+		ctx.Writer = writer
+		ctx.Header("X-Cache", "MISS")
+
+		ctx.Next()
+
+		ctx.Writer = writer.ResponseWriter
+		if writer.overflow {
+			return
+		}
+
+		if writer.Status() != http.StatusOK || !isCacheable(writer.Header(), userID != "") {
+			writer.flush()
+			return
+		}
+
+		entry = &respcache.Entry{
+			Status: writer.Status(),
+			Header: http.Header{},
+			Body:   writer.body.Bytes(),
+			ETag:   writer.Header().Get("ETag"),
+		}
+		if entry.ETag == "" {
+			entry.ETag = respcache.NewETag(entry.Body)
+		}
+		for _, name := range cachedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+
+		errSet := cache.Set(ctx.Request.Context(), key, entry, time.Duration(cacheTTL)*time.Second)
+		if errSet != nil {
+			_ = ctx.Error(errSet)
+		}
+
+		// the first response carries the ETag the next ones are served with
+		writer.Header().Set("ETag", entry.ETag)
+		if respcache.MatchETag(ctx.GetHeader("If-None-Match"), entry.ETag) {
+			writer.WriteHeader(http.StatusNotModified)
+			writer.WriteHeaderNow()
+			return
+		}
+		writer.flush()
+	}
+}
+
+func serveCached(ctx *gin.Context, entry *respcache.Entry) {
+	ctx.Header("ETag", entry.ETag)
+	ctx.Header("X-Cache", "HIT")
+
+	if respcache.MatchETag(ctx.GetHeader("If-None-Match"), entry.ETag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	for name, values := range entry.Header {
+		for _, value := range values {
+			ctx.Writer.Header().Add(name, value)
+		}
+	}
+	ctx.Data(entry.Status, entry.Header.Get("Content-Type"), entry.Body)
+	ctx.Abort()
+}
+
+// isCacheable tells whether the service allows its response to be stored, a private one
+// only when it is cached for the user it belongs to.
+func isCacheable(header http.Header, perUser bool) bool {
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") {
+		return false
+	}
+	return perUser || !strings.Contains(cacheControl, "private")
+}
+
+// captureWriter holds back the body of the response, up to limit bytes, so the headers
+// can still be set once it is complete. A larger body is written through as it comes.
+type captureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.overflow {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.body.Len()+len(b) > w.limit {
+		w.overflow = true
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			return 0, err
+		}
+		w.body = bytes.Buffer{}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush holds back the headers and body too until the body is known to be too large.
+func (w *captureWriter) Flush() {
+	if w.overflow {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// flush writes the held back response.
+func (w *captureWriter) flush() {
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
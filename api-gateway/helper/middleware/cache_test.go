@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"api-gateway-go/helper/respcache"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Miss(t *testing.T) {
+	body := []byte(`{"data":[]}`)
+	etag := respcache.NewETag(body)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "carries the ETag",
+			wantStatus: http.StatusOK,
+			wantBody:   string(body),
+		},
+		{
+			name:        "matching If-None-Match",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			db, mock := redismock.NewClientMock()
+			defer db.Close()
+
+			sum := sha256.Sum256([]byte("CUbICN8VgNk\x00\x00"))
+			key := "response_cache:product:0:" + hex.EncodeToString(sum[:])
+			entry, _ := json.Marshal(&respcache.Entry{
+				Status: http.StatusOK,
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   body,
+				ETag:   etag,
+			})
+			mock.ExpectGet("response_cache_version:product").RedisNil()
+			mock.ExpectGet(key).RedisNil()
+			mock.ExpectSet(key, entry, time.Minute).SetVal("OK")
+
+			router := gin.New()
+			router.Use(func(ctx *gin.Context) {
+				ctx.Set("cache_ttl", 60)
+				ctx.Set("service_name", "product")
+				ctx.Set("need_bypass", true)
+			})
+			router.Use(Cache(respcache.New(db), 1<<20))
+			router.GET("/:hash", func(ctx *gin.Context) {
+				ctx.Data(http.StatusOK, "application/json", body)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/CUbICN8VgNk", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			require.Equal(t, tt.wantStatus, res.Code)
+			require.Equal(t, tt.wantBody, res.Body.String())
+			require.Equal(t, etag, res.Header().Get("ETag"))
+			require.Equal(t, "MISS", res.Header().Get("X-Cache"))
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
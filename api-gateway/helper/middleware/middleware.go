@@ -2,15 +2,18 @@ package middleware
 
 import (
 	"api-gateway-go/helper/authjwt"
+	"api-gateway-go/helper/ratelimit"
 	"api-gateway-go/helper/response"
+	"api-gateway-go/model"
 	"api-gateway-go/service"
-	"context"
 	"database/sql"
 	"errors"
 	"net/http"
 	"net/url"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"telemetry-go"
 	"time"
 
 	"github.com/casbin/casbin/v2"
@@ -69,34 +72,93 @@ func Logger(logger *zerolog.Logger) gin.HandlerFunc {
 	}
 }
 
-// note: request counter can be rate limiter ;D
-func RequestCounter(redis *redis.Client) gin.HandlerFunc {
+// Recovery is gin.Recovery that lets http.ErrAbortHandler through. The proxy panics with
+// it when a service's body breaks after the status was sent, net/http then drops the
+// connection so the client sees a cut response instead of a complete looking one.
+func Recovery(logger *zerolog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, err any) {
+		if err == http.ErrAbortHandler { //nolint:errorlint // the sentinel is panicked as is
+			logger.Warn().Str("endpoint_url", ctx.GetString("url")).Msg("upstream body aborted")
+			panic(err)
+		}
+
+		logger.Error().Interface("panic", err).Bytes("stack", debug.Stack()).Msg("panic recovered")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// RateLimiter limits the requests to an endpoint per client IP, per user and in total,
+// with the limits of its api_managements entry or defaults where the entry has none.
+// A Redis failure lets the request through, losing the gateway over the limiter would
+// take every service down with it.
+func RateLimiter(limiter *ratelimit.Limiter, defaults model.RateLimit) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		ctxBG := context.Background()
-		clientIP := ctx.ClientIP()
-
-		// Increment the request count
-		errIncr := redis.Incr(ctxBG, clientIP).Err()
-		if errIncr != nil {
-			_ = ctx.Error(errIncr)
-			ctx.AbortWithStatus(http.StatusInternalServerError)
-			return
+		hashedURL := ctx.Param("hash")
+
+		rateLimitCtx, _ := ctx.Get("rate_limit")
+		rateLimit, _ := rateLimitCtx.(model.RateLimit)
+
+		window := time.Duration(orDefault(rateLimit.Window, defaults.Window)) * time.Second
+		limits := []ratelimit.Limit{
+			{
+				Key:    "ip:" + hashedURL + ":" + ctx.ClientIP(),
+				Limit:  orDefault(rateLimit.IP, defaults.IP),
+				Window: window,
+			},
+			{
+				Key:    "route:" + hashedURL,
+				Limit:  orDefault(rateLimit.Route, defaults.Route),
+				Window: window,
+			},
+		}
+
+		// bypassed endpoints have no user
+		if userID := ctx.GetString("userID"); userID != "" {
+			limits = append(limits, ratelimit.Limit{
+				Key:    "user:" + hashedURL + ":" + userID,
+				Limit:  orDefault(rateLimit.User, defaults.User),
+				Window: window,
+			})
 		}
 
-		// Get the updated request count
-		count, errGet := redis.Get(ctxBG, clientIP).Int64()
-		if errGet != nil {
-			_ = ctx.Error(errGet)
-			ctx.AbortWithStatus(http.StatusInternalServerError)
+		result, errAllow := limiter.Allow(ctx.Request.Context(), limits...)
+		if errAllow != nil {
+			_ = ctx.Error(errAllow)
+			ctx.Next()
 			return
 		}
 
-		ctx.Header("X-Request-Count", strconv.FormatInt(count, 10))
+		if result.Limit > 0 {
+			ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+			ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		}
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			response.NewJSONResErr(ctx, http.StatusTooManyRequests, "", "rate limit exceeded")
+			ctx.Abort()
+			return
+		}
 
 		ctx.Next()
 	}
 }
 
+func orDefault(value, def *int) int {
+	if value != nil {
+		return *value
+	}
+	if def != nil {
+		return *def
+	}
+	return 0
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
 // HashedURLConverter convert hashed url that have been save to database
 // into endpoint url.
 func HashedURLConverter(svc service.ShortenServiceI) gin.HandlerFunc {
@@ -121,6 +183,13 @@ func HashedURLConverter(svc service.ShortenServiceI) gin.HandlerFunc {
 			return
 		}
 
+		// a disabled route is not there as far as the clients know
+		if !apiManagement.IsAvailable {
+			response.NewJSONResErr(ctx, http.StatusNotFound, "", "")
+			ctx.Abort()
+			return
+		}
+
 		url := apiManagement.EndpointURL
 		rawQuery := ctx.Request.URL.RawQuery
 		if rawQuery != "" {
@@ -130,13 +199,26 @@ func HashedURLConverter(svc service.ShortenServiceI) gin.HandlerFunc {
 		// Pass the real url and raw query into to the AuthMiddleware
 		ctx.Set("url", url)
 		ctx.Set("need_bypass", apiManagement.NeedBypass)
+		ctx.Set("service_name", apiManagement.ServiceName)
+		telemetry.SetRoute(ctx, apiManagement.APIName)
+		ctx.Set("api_name", apiManagement.APIName)
+		ctx.Set("rate_limit", apiManagement.RateLimit)
+		ctx.Set("breaker", apiManagement.Breaker)
+		ctx.Set("fallback", apiManagement.Fallback)
+		if apiManagement.CacheTTL != nil {
+			ctx.Set("cache_ttl", *apiManagement.CacheTTL)
+		}
 		ctx.Next()
 	}
 }
 
-// If path is the allowed, next to handler.
+// If path is the allowed, next to handler. The gateway's own routes, which have no
+// endpoint url, are authorized by their path.
 func isAllowedPath(ctx *gin.Context) (string, bool) {
-	urlCtx, _ := ctx.Get("url")
+	urlCtx, exists := ctx.Get("url")
+	if !exists {
+		return strings.TrimPrefix(ctx.Request.URL.Path, "/"), false
+	}
 	uri, _ := urlCtx.(string)
 
 	needBypassCtx, _ := ctx.Get("need_bypass")
@@ -153,8 +235,10 @@ func isAllowedPath(ctx *gin.Context) (string, bool) {
 	return path, false
 }
 
-// AuthMiddleware validate jwt token.
-func AuthMiddleware(jwtSecretKey string) gin.HandlerFunc {
+// AuthMiddleware validate jwt token against the keys of the auth service JWKS. A token
+// without the access audience, without an id (jti) or with one a logout revoked is turned
+// away, so is every token while the keys or the denylist can't be read.
+func AuthMiddleware(keys *authjwt.JWKS, denylist *redis.Client) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		path, shouldReturn := isAllowedPath(ctx)
 		if shouldReturn {
@@ -173,16 +257,23 @@ func AuthMiddleware(jwtSecretKey string) gin.HandlerFunc {
 		token, errParse := jwt.ParseWithClaims(
 			tokenString,
 			&authjwt.CustomClaims{},
-			func(token *jwt.Token) (interface{}, error) {
-				return []byte(jwtSecretKey), nil
-			},
+			keys.Keyfunc(ctx.Request.Context()),
+			jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
 			jwt.WithIssuer("ecommerce"),
+			// refresh tokens and emailed tokens are no access tokens
+			jwt.WithAudience(authjwt.AudienceAccess),
 			jwt.WithIssuedAt(),
 			jwt.WithLeeway(5*time.Second),
 		)
 
 		if errParse != nil {
-			if errors.Is(errParse, jwt.ErrSignatureInvalid) {
+			if errors.Is(errParse, authjwt.ErrKeysUnavailable) {
+				_ = ctx.Error(errParse)
+				response.NewJSONResErr(ctx, http.StatusServiceUnavailable, "", "unable to check token")
+				ctx.Abort()
+				return
+			}
+			if errors.Is(errParse, jwt.ErrTokenSignatureInvalid) || errors.Is(errParse, authjwt.ErrUnknownKey) {
 				response.NewJSONResErr(ctx, http.StatusUnauthorized, "", "Invalid token signature")
 				ctx.Abort()
 				return
@@ -199,6 +290,25 @@ func AuthMiddleware(jwtSecretKey string) gin.HandlerFunc {
 			return
 		}
 
+		if claims.ID == "" {
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "", "Invalid token claims")
+			ctx.Abort()
+			return
+		}
+
+		revoked, errRevoked := authjwt.IsRevoked(ctx.Request.Context(), denylist, claims.ID)
+		if errRevoked != nil {
+			_ = ctx.Error(errRevoked)
+			response.NewJSONResErr(ctx, http.StatusServiceUnavailable, "", "unable to check token")
+			ctx.Abort()
+			return
+		}
+		if revoked {
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "", "Token revoked")
+			ctx.Abort()
+			return
+		}
+
 		// Pass the userID, userRole to AuthzMiddleware
 		ctx.Set("userID", claims.UserID)
 		ctx.Set("userRole", claims.UserRole)
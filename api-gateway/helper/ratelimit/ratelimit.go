@@ -0,0 +1,116 @@
+// Package ratelimit limits requests with token buckets kept in Redis, shared by every
+// gateway instance. A bucket holds Limit tokens and refills them evenly over Window.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// script takes a token from every bucket in KEYS or from none of them, so a request
+// denied by one limit doesn't use up the others. It reads the clock from Redis so the
+// gateway instances agree on it.
+// ARGV holds the limit and the window in ms of each key, in the same order.
+// It returns allowed, then the limit, remaining tokens and ms until full of the bucket
+// closest to empty, then the ms until a denied request can be retried.
+var script = redis.NewScript(`
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+local allowed = 1
+local buckets = {}
+for i, key in ipairs(KEYS) do
+  local limit = tonumber(ARGV[i * 2 - 1])
+  local window = tonumber(ARGV[i * 2])
+  local b = redis.call('HMGET', key, 'tokens', 'ts')
+  local tokens = tonumber(b[1])
+  local ts = tonumber(b[2])
+  if tokens == nil or ts == nil then
+    tokens, ts = limit, now
+  end
+  local rate = limit / window
+  tokens = math.min(limit, tokens + math.max(0, now - ts) * rate)
+  if tokens < 1 then
+    allowed = 0
+  end
+  buckets[i] = {key, limit, window, rate, tokens}
+end
+
+local limit, remaining, reset, retry = 0, -1, 0, 0
+for _, b in ipairs(buckets) do
+  local key, l, window, rate, tokens = b[1], b[2], b[3], b[4], b[5]
+  if allowed == 1 then
+    tokens = tokens - 1
+  elseif tokens < 1 then
+    retry = math.max(retry, math.ceil((1 - tokens) / rate))
+  end
+  redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+  redis.call('PEXPIRE', key, window)
+  if remaining == -1 or math.floor(tokens) < remaining then
+    limit, remaining, reset = l, math.floor(tokens), math.ceil((l - tokens) / rate)
+  end
+end
+
+return {allowed, limit, remaining, reset, retry}
+`)
+
+type Limit struct {
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket closest to empty is full again.
+	Reset time.Duration
+	// RetryAfter is how long a denied request has to wait.
+	RetryAfter time.Duration
+}
+
+type Limiter struct {
+	redis *redis.Client
+}
+
+func New(redis *redis.Client) *Limiter {
+	return &Limiter{redis: redis}
+}
+
+// Allow takes a token for the request from the bucket of every limit, a limit of 0 or
+// less is no limit. Without any limit left the request is allowed without calling Redis.
+func (l *Limiter) Allow(ctx context.Context, limits ...Limit) (Result, error) {
+	keys := make([]string, 0, len(limits))
+	args := make([]interface{}, 0, len(limits)*2)
+	for _, limit := range limits {
+		if limit.Limit <= 0 || limit.Window <= 0 {
+			continue
+		}
+		keys = append(keys, "rate_limit:"+limit.Key)
+		args = append(args, limit.Limit, limit.Window.Milliseconds())
+	}
+	if len(keys) == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	values, errRun := script.Run(ctx, l.redis, keys, args...).Int64Slice()
+	if errRun != nil {
+		return Result{}, errRun
+	}
+	if len(values) != 5 {
+		return Result{}, errors.New("rate limit script returned " + strconv.Itoa(len(values)) + " values")
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      int(values[1]),
+		Remaining:  int(values[2]),
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+		RetryAfter: time.Duration(values[4]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limits := []Limit{
+		{Key: "ip:CUbICN8VgNk:10.0.0.1", Limit: 10, Window: time.Minute},
+		{Key: "user:CUbICN8VgNk:1", Limit: 0, Window: time.Minute},
+		{Key: "route:CUbICN8VgNk", Limit: 100, Window: time.Second},
+	}
+	keys := []string{"rate_limit:ip:CUbICN8VgNk:10.0.0.1", "rate_limit:route:CUbICN8VgNk"}
+	args := []interface{}{10, int64(60000), 100, int64(1000)}
+
+	tests := []struct {
+		name    string
+		val     []interface{}
+		err     error
+		want    Result
+		wantErr bool
+	}{
+		{
+			name: "allowed",
+			val:  []interface{}{int64(1), int64(10), int64(9), int64(6000), int64(0)},
+			want: Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 6 * time.Second},
+		},
+		{
+			name: "denied",
+			val:  []interface{}{int64(0), int64(10), int64(0), int64(60000), int64(1500)},
+			want: Result{Limit: 10, Reset: time.Minute, RetryAfter: 1500 * time.Millisecond},
+		},
+		{
+			name:    "redis error",
+			err:     errors.New("redis error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := redismock.NewClientMock()
+			defer db.Close()
+
+			expect := mock.ExpectEvalSha(script.Hash(), keys, args...)
+			if tt.err != nil {
+				expect.SetErr(tt.err)
+			} else {
+				expect.SetVal(tt.val)
+			}
+
+			got, err := New(db).Allow(context.Background(), limits...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLimiter_AllowWithoutLimits(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	defer db.Close()
+
+	got, err := New(db).Allow(context.Background(), Limit{Key: "ip:CUbICN8VgNk:10.0.0.1"})
+	require.NoError(t, err)
+	require.True(t, got.Allowed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package respcache
+
+import (
+	"api-gateway-go/helper/timeout"
+	"encoding/json"
+	"events-go"
+
+	"github.com/rs/zerolog"
+	"github.com/wagslane/go-rabbitmq"
+)
+
+type invalidation struct {
+	ServiceName string `json:"service_name"`
+}
+
+// InvalidationHandler consumes the invalidate_cache events the services publish after
+// changing the data behind their responses.
+func InvalidationHandler(cache *Cache, logger *zerolog.Logger) rabbitmq.Handler {
+	return func(d rabbitmq.Delivery) rabbitmq.Action {
+		env, errDecode := events.Decode(d.Body, "invalidate_cache")
+		if errDecode != nil {
+			logger.Error().Err(errDecode).Msg("invalid invalidate_cache event")
+			return rabbitmq.NackDiscard
+		}
+
+		var data invalidation
+		if errJSONUn := json.Unmarshal(env.Payload, &data); errJSONUn != nil {
+			logger.Error().Err(errJSONUn).Msg("invalid invalidate_cache event")
+			return rabbitmq.NackDiscard
+		}
+
+		ctx, cancel := timeout.NewCtxTimeout()
+		defer cancel()
+
+		// requeueing while Redis is down would spin on the message, the entries it was
+		// about still expire with their TTL
+		if errInv := cache.Invalidate(ctx, data.ServiceName); errInv != nil {
+			logger.Error().Err(errInv).Msgf("failed to invalidate cache of %s", data.ServiceName)
+			return rabbitmq.Ack
+		}
+
+		logger.Debug().Msgf("cache of %s invalidated", data.ServiceName)
+		return rabbitmq.Ack
+	}
+}
@@ -0,0 +1,103 @@
+// Package respcache keeps the gateway's cached responses in Redis. The keys of a service
+// carry its version number, invalidating the service bumps it so none of its old entries
+// is read again and they expire with their TTL.
+package respcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	ETag   string      `json:"etag"`
+}
+
+type Cache struct {
+	redis *redis.Client
+}
+
+func New(redis *redis.Client) *Cache {
+	return &Cache{redis: redis}
+}
+
+// Key is the key of a response of serviceName, varying on parts.
+func (c *Cache) Key(ctx context.Context, serviceName string, parts ...string) (string, error) {
+	version, errGet := c.redis.Get(ctx, versionKey(serviceName)).Int64()
+	if errGet != nil && !errors.Is(errGet, redis.Nil) {
+		return "", errGet
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return "response_cache:" + serviceName + ":" + strconv.FormatInt(version, 10) + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the entry at key, nil when there is none.
+func (c *Cache) Get(ctx context.Context, key string) (*Entry, error) {
+	data, errGet := c.redis.Get(ctx, key).Bytes()
+	if errors.Is(errGet, redis.Nil) {
+		return nil, nil
+	}
+	if errGet != nil {
+		return nil, errGet
+	}
+
+	entry := new(Entry)
+	if errJSONUn := json.Unmarshal(data, entry); errJSONUn != nil {
+		return nil, errJSONUn
+	}
+	return entry, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	data, errJSON := json.Marshal(entry)
+	if errJSON != nil {
+		return errJSON
+	}
+	return c.redis.Set(ctx, key, data, ttl).Err()
+}
+
+// Invalidate drops every cached response of serviceName.
+func (c *Cache) Invalidate(ctx context.Context, serviceName string) error {
+	return c.redis.Incr(ctx, versionKey(serviceName)).Err()
+}
+
+func versionKey(serviceName string) string {
+	return "response_cache_version:" + serviceName
+}
+
+// NewETag is the strong ETag of body.
+func NewETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// MatchETag tells whether an If-None-Match header matches etag, with the weak comparison
+// RFC 9110 asks for.
+func MatchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
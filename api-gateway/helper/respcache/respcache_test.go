@@ -0,0 +1,54 @@
+package respcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{name: "same", ifNoneMatch: `"abc"`, etag: `"abc"`, want: true},
+		{name: "in a list", ifNoneMatch: `"xyz", "abc"`, etag: `"abc"`, want: true},
+		{name: "weak", ifNoneMatch: `W/"abc"`, etag: `"abc"`, want: true},
+		{name: "any", ifNoneMatch: `*`, etag: `"abc"`, want: true},
+		{name: "other", ifNoneMatch: `"xyz"`, etag: `"abc"`},
+		{name: "no header", etag: `"abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MatchETag(tt.ifNoneMatch, tt.etag))
+		})
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	defer db.Close()
+	cache := New(db)
+	ctx := context.Background()
+
+	mock.ExpectGet("response_cache_version:product").RedisNil()
+	before, err := cache.Key(ctx, "product", "CUbICN8VgNk", "page=2", "")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(before, "response_cache:product:0:"))
+
+	mock.ExpectIncr("response_cache_version:product").SetVal(1)
+	require.NoError(t, cache.Invalidate(ctx, "product"))
+
+	mock.ExpectGet("response_cache_version:product").SetVal("1")
+	after, err := cache.Key(ctx, "product", "CUbICN8VgNk", "page=2", "")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(after, "response_cache:product:1:"))
+	require.Equal(t, strings.TrimPrefix(before, "response_cache:product:0:"), strings.TrimPrefix(after, "response_cache:product:1:"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,7 +1,6 @@
 package response
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,13 +13,6 @@ type JSONRes struct {
 	Data    any    `json:"data,omitempty"`
 }
 
-type JSONGatewayRes struct {
-	Status  int             `json:"status,omitempty"`
-	Message string          `json:"message,omitempty"`
-	Error   string          `json:"error,omitempty"`
-	Data    json.RawMessage `json:"data,omitempty"`
-}
-
 func NewJSONRes(c *gin.Context, statusCode int, message string, data any) {
 	if message == "" {
 		message = http.StatusText(statusCode)
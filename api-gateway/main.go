@@ -3,22 +3,31 @@ package main
 import (
 	"api-gateway-go/config"
 	"api-gateway-go/handler"
+	"api-gateway-go/helper/authjwt"
+	"api-gateway-go/helper/breaker"
 	"api-gateway-go/helper/logging"
 	"api-gateway-go/helper/middleware"
+	"api-gateway-go/helper/ratelimit"
+	"api-gateway-go/helper/respcache"
+	"api-gateway-go/model"
 	"api-gateway-go/package/db"
 	"api-gateway-go/package/redisclient"
+	"api-gateway-go/package/rmq"
+	"api-gateway-go/package/transport"
 	"api-gateway-go/repository"
 	"api-gateway-go/server"
 	"api-gateway-go/service"
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"telemetry-go"
 	"time"
 
 	"github.com/casbin/casbin/v2"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
-	"github.com/gin-gonic/gin"
 )
 
 //nolint:funlen // hard to avoid
@@ -32,6 +41,16 @@ func main() {
 	// * setup logger config
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "api-gateway", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	// * connect to postgres using gorm
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
@@ -50,6 +69,13 @@ func main() {
 	}
 	logger.Debug().Msg("redis connected")
 
+	// * connect to rabbitmq, the services tell the gateway when its cached responses are stale
+	rmqConn, errRmq := rmq.NewConn(config.RabbitMQ.URL)
+	if errRmq != nil {
+		logger.Fatal().Err(errRmq).Msg("rabbitmq failed to connect")
+	}
+	logger.Debug().Msg("rabbitmq connected")
+
 	// * Initialize the Casbin Gorm adapter and the Casbin enforcer with the model
 	if !config.Debug {
 		gormadapter.TurnOffAutoMigrate(sqlDB.Gorm)
@@ -74,20 +100,81 @@ func main() {
 			logger.Fatal().Err(errRedisC).Msg("redis failed to closed")
 		}
 		logger.Debug().Msg("redis closed")
+
+		errRmqC := rmqConn.Close()
+		if errRmqC != nil {
+			logger.Fatal().Err(errRmqC).Msg("rabbitmq failed to closed")
+		}
+		logger.Debug().Msg("rabbitmq closed")
 	}()
 
+	responseCache := respcache.New(redisClient.Redis)
+	invalidationConsumer, errCon := rmq.NewConsumer(
+		rmqConn, respcache.InvalidationHandler(responseCache, logger),
+		"invalidate_cache", "",
+		true, false,
+		1, 10,
+		"", "", false,
+	)
+	if errCon != nil {
+		logger.Fatal().Err(errCon).Msg("invalidate_cache consumer failed")
+	}
+	defer invalidationConsumer.Close()
+
 	pingHandler := handler.NewPingGinHandler()
 
 	shortenRepo := repository.NewShortenRepo(sqlDB.SQLDB, redisClient.Redis)
 	shortenSvc := service.NewShortenService(shortenRepo)
-	shortenHandler := handler.NewShortenHandler(shortenSvc)
+	upstream := transport.NewTransport(
+		config.Proxy.DialTimeout, config.Proxy.ResponseHeaderTimeout,
+		config.Proxy.IdleConnTimeout, config.Proxy.MaxIdleConnsPerHost,
+	)
+	defer upstream.CloseIdleConnections()
+	breakers := breaker.NewGroup(breaker.Settings{
+		FailureThreshold: config.Breaker.FailureThreshold,
+		OpenTimeout:      config.Breaker.OpenTimeout,
+		HalfOpenProbes:   config.Breaker.HalfOpenProbes,
+		OnStateChange: func(name string, from, to breaker.State) {
+			logger.Warn().Str("service_name", name).Msgf("circuit breaker %s -> %s", from, to)
+			telemetry.BreakerState(name, int(to))
+		},
+	})
+	routeHandler := handler.NewRouteHandler(shortenSvc)
+	shortenHandler := handler.NewShortenHandler(
+		shortenSvc,
+		transport.NewRetry(telemetry.Transport(upstream), config.Retry.MaxRetries, config.Retry.Backoff),
+		breakers,
+	)
+
+	// the metrics aren't for the clients, they get a listener of their own
+	metricsAddr := config.MetricsAddr
+	if metricsAddr == "" {
+		metricsAddr = ":9100"
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Handler())
+	metricsSrv := &http.Server{Addr: metricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if errSrv := metricsSrv.ListenAndServe(); errSrv != nil && !errors.Is(errSrv, http.ErrServerClosed) {
+			logger.Error().Err(errSrv).Msg("metrics server failed")
+		}
+	}()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = metricsSrv.Shutdown(ctx)
+	}()
 
-	router := gin.New()
+	router, errRouter := server.NewRouter(config.TrustedProxies)
+	if errRouter != nil {
+		logger.Fatal().Err(errRouter).Msg("invalid trusted proxies")
+	}
+	router.Use(telemetry.Middleware("api-gateway"))
 	router.Use(cors.Default())
 
 	// * 9. Error and panic handling
 	router.Use(middleware.Logger(logger))
-	router.Use(gin.Recovery())
+	router.Use(middleware.Recovery(logger))
 
 	if config.Debug {
 		sGroup := router.Group("/s")
@@ -97,6 +184,31 @@ func main() {
 		}
 	}
 
+	// * 3. Authentication keys, the public keys of the auth service
+	jwksTTL := config.JWKSCacheTTL
+	if jwksTTL <= 0 {
+		jwksTTL = 5 * time.Minute
+	}
+	jwks := authjwt.NewJWKS(config.JWKSURL, jwksTTL, &http.Client{Transport: telemetry.Transport(http.DefaultTransport)})
+	if errJWKS := jwks.Refresh(context.Background()); errJWKS != nil {
+		logger.Warn().Err(errJWKS).Msg("jwks failed to load, retrying on the first request")
+	}
+
+	// * 13. Admin API of the routes, a route that changes is seen on the next request
+	adminGroup := router.Group("/admin",
+		middleware.AuthMiddleware(jwks, redisClient.Redis),
+		middleware.AuthzMiddleware(enforcer),
+	)
+	{
+		adminGroup.GET("/routes", routeHandler.List)
+		adminGroup.POST("/routes", shortenHandler.Shorten)
+		adminGroup.GET("/routes/:id", routeHandler.GetByID)
+		adminGroup.PUT("/routes/:id", routeHandler.Update)
+		adminGroup.POST("/routes/:id/disable", routeHandler.Disable)
+		adminGroup.POST("/routes/:id/enable", routeHandler.Enable)
+		adminGroup.DELETE("/routes/:id", routeHandler.Delete)
+	}
+
 	// * 1. Paramater validation,
 	// * 6. Dynamic routing using path parameters,
 	// * 7. Service discovery using database,
@@ -112,31 +224,48 @@ func main() {
 	// 	"login/cms",
 	// }
 	// * 3. Authentication
-	router.Use(middleware.AuthMiddleware(config.JWTSecretKey))
+	router.Use(middleware.AuthMiddleware(jwks, redisClient.Redis))
 	// * 4. Authorization
 	router.Use(middleware.AuthzMiddleware(enforcer))
 
 	router.Use(requestid.New())
-	// * 5. Request counter
-	router.Use(middleware.RequestCounter(redisClient.Redis))
+	// * 5. Rate limiter
+	window := int(config.RateLimit.Window.Seconds())
+	if window <= 0 {
+		window = 60
+	}
+	router.Use(middleware.RateLimiter(ratelimit.New(redisClient.Redis), model.RateLimit{
+		IP:     &config.RateLimit.IP,
+		User:   &config.RateLimit.User,
+		Route:  &config.RateLimit.Route,
+		Window: &window,
+	}))
 
 	// * things outside my capabilities:
 	// * 2. Allow-list based on IPs
-	// * 10. Circuit Breaker
-	// * 11. Monitoring
-	// * 12. Cache
 
 	// if config.Debug {
 	// 	pprof.Register(short)
 	// }
 
+	// * 12. Cache of GET responses, per endpoint
+	cacheMaxBody := config.Cache.MaxBody
+	if cacheMaxBody <= 0 {
+		cacheMaxBody = 1 << 20
+	}
+	router.Use(middleware.Cache(responseCache, cacheMaxBody))
+
+	// * 10. Circuit Breaker, per service, with retries of idempotent requests
 	router.Any("/:hash", shortenHandler.Get)
 
 	srv := &http.Server{
-		Addr:         ":" + config.Port,
-		Handler:      router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		Addr:    ":" + config.Port,
+		Handler: router,
+		// no ReadTimeout or WriteTimeout, they would cut bodies streamed to and from the
+		// services; a slow service is bounded by PROXY_RESPONSE_HEADER_TIMEOUT and the
+		// request's context is canceled when the client goes away
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
 	}
 
 	logger.Debug().Msgf("service will be start at port: %v", config.Port)
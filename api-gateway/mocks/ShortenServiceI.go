@@ -39,6 +39,20 @@ func (_m *ShortenServiceI) Create(shortenReq *model.ShortenReq) (*model.APIManag
 	return r0, r1
 }
 
+// Delete provides a mock function with given fields: id
+func (_m *ShortenServiceI) Delete(id uint) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: hashedURL
 func (_m *ShortenServiceI) Get(hashedURL string) (*model.APIManagement, error) {
 	ret := _m.Called(hashedURL)
@@ -65,6 +79,110 @@ func (_m *ShortenServiceI) Get(hashedURL string) (*model.APIManagement, error) {
 	return r0, r1
 }
 
+// GetByID provides a mock function with given fields: id
+func (_m *ShortenServiceI) GetByID(id uint) (*model.APIManagement, error) {
+	ret := _m.Called(id)
+
+	var r0 *model.APIManagement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint) (*model.APIManagement, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(uint) *model.APIManagement); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIManagement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields:
+func (_m *ShortenServiceI) List() ([]model.APIManagement, error) {
+	ret := _m.Called()
+
+	var r0 []model.APIManagement
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.APIManagement, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.APIManagement); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.APIManagement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetAvailable provides a mock function with given fields: id, isAvailable
+func (_m *ShortenServiceI) SetAvailable(id uint, isAvailable bool) (*model.APIManagement, error) {
+	ret := _m.Called(id, isAvailable)
+
+	var r0 *model.APIManagement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, bool) (*model.APIManagement, error)); ok {
+		return rf(id, isAvailable)
+	}
+	if rf, ok := ret.Get(0).(func(uint, bool) *model.APIManagement); ok {
+		r0 = rf(id, isAvailable)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIManagement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, bool) error); ok {
+		r1 = rf(id, isAvailable)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: id, shortenReq
+func (_m *ShortenServiceI) Update(id uint, shortenReq *model.ShortenReq) (*model.APIManagement, error) {
+	ret := _m.Called(id, shortenReq)
+
+	var r0 *model.APIManagement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uint, *model.ShortenReq) (*model.APIManagement, error)); ok {
+		return rf(id, shortenReq)
+	}
+	if rf, ok := ret.Get(0).(func(uint, *model.ShortenReq) *model.APIManagement); ok {
+		r0 = rf(id, shortenReq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIManagement)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, *model.ShortenReq) error); ok {
+		r1 = rf(id, shortenReq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewShortenServiceI interface {
 	mock.TestingT
 	Cleanup(func())
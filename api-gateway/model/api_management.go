@@ -5,13 +5,17 @@ import (
 )
 
 type APIManagement struct {
-	ID                uint      `gorm:"primaryKey;" json:"id"`
-	APIName           string    `gorm:"not null;" json:"api_name"`
-	ServiceName       string    `gorm:"not null;" json:"service_name"`
-	EndpointURL       string    `gorm:"not null;" json:"endpoint_url"`
-	HashedEndpointURL string    `gorm:"unique;uniqueIndex;not null;" json:"hashed_endpoint_url"`
-	IsAvailable       bool      `gorm:"not null;default:false" json:"is_available"`
-	NeedBypass        bool      `gorm:"not null;default:false" json:"need_bypass"`
+	ID                uint   `gorm:"primaryKey;" json:"id"`
+	APIName           string `gorm:"not null;" json:"api_name"`
+	ServiceName       string `gorm:"not null;" json:"service_name"`
+	EndpointURL       string `gorm:"not null;" json:"endpoint_url"`
+	HashedEndpointURL string `gorm:"unique;uniqueIndex;not null;" json:"hashed_endpoint_url"`
+	IsAvailable       bool   `gorm:"not null;default:false" json:"is_available"`
+	NeedBypass        bool   `gorm:"not null;default:false" json:"need_bypass"`
+	RateLimit         `gorm:"embedded"`
+	CacheTTL          *int `json:"cache_ttl"`
+	Breaker           `gorm:"embedded"`
+	Fallback          `gorm:"embedded"`
 	CreatedAt         time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt         time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
@@ -22,4 +26,35 @@ type ShortenReq struct {
 	EndpointURL string `json:"endpoint_url" binding:"required,url"`
 	IsAvailable *bool  `json:"is_available" binding:"required"`
 	NeedBypass  *bool  `json:"need_bypass" binding:"required"`
+	RateLimit
+	// CacheTTL caches the endpoint's GET responses for that many seconds, nil or 0 doesn't.
+	CacheTTL *int `json:"cache_ttl" binding:"omitempty,min=0"`
+	Breaker
+	Fallback
+}
+
+// RateLimit is the number of requests an endpoint takes per client IP, per user and in
+// total within Window seconds. A nil field falls back to the gateway's default, 0 is no
+// limit.
+type RateLimit struct {
+	IP     *int `json:"rate_limit_ip" binding:"omitempty,min=0"`
+	User   *int `json:"rate_limit_user" binding:"omitempty,min=0"`
+	Route  *int `json:"rate_limit_route" binding:"omitempty,min=0"`
+	Window *int `json:"rate_limit_window" binding:"omitempty,min=1"`
+}
+
+// Breaker is the circuit breaker of an endpoint that doesn't behave like the rest of its
+// service, OpenTimeout is in seconds. A nil field falls back to the gateway's default; an
+// endpoint that sets any of them gets a breaker of its own.
+type Breaker struct {
+	FailureThreshold *int `json:"breaker_failure_threshold" binding:"omitempty,min=1"`
+	OpenTimeout      *int `json:"breaker_open_timeout" binding:"omitempty,min=1"`
+	HalfOpenProbes   *int `json:"breaker_half_open_probes" binding:"omitempty,min=1"`
+}
+
+// Fallback is the response of an endpoint while its breaker is open, Body is JSON. A nil
+// Status answers 503 and a nil Body the gateway's error message.
+type Fallback struct {
+	Status *int    `json:"fallback_status" binding:"omitempty,min=200,max=599"`
+	Body   *string `json:"fallback_body" binding:"omitempty,json"`
 }
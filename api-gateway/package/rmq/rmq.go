@@ -0,0 +1,64 @@
+package rmq
+
+import (
+	"github.com/wagslane/go-rabbitmq"
+)
+
+func NewConn(url string) (*rabbitmq.Conn, error) {
+	return rabbitmq.NewConn(
+		url,
+		rabbitmq.WithConnectionOptionsLogging,
+	)
+}
+
+func NewConsumer(
+	conn *rabbitmq.Conn,
+	handler rabbitmq.Handler,
+	queueName, routingKeyCon string,
+	queueDurable, autoAck bool,
+	concurrency, prefetchCount int,
+	exchangeName, exchangeType string, exchangeDeclare bool,
+) (*rabbitmq.Consumer, error) {
+	opt := []func(*rabbitmq.ConsumerOptions){
+		rabbitmq.WithConsumerOptionsLogging,
+	}
+
+	if routingKeyCon != "" {
+		opt = append(opt, rabbitmq.WithConsumerOptionsRoutingKey(routingKeyCon))
+	}
+
+	if queueDurable {
+		opt = append(opt, rabbitmq.WithConsumerOptionsQueueDurable)
+	}
+
+	if autoAck {
+		opt = append(opt, rabbitmq.WithConsumerOptionsConsumerAutoAck(autoAck))
+	}
+
+	if concurrency != 0 {
+		opt = append(opt, rabbitmq.WithConsumerOptionsConcurrency(concurrency))
+	}
+
+	if prefetchCount != 0 {
+		opt = append(opt, rabbitmq.WithConsumerOptionsQOSPrefetch(prefetchCount))
+	}
+
+	if exchangeName != "" {
+		opt = append(opt, rabbitmq.WithConsumerOptionsExchangeName(exchangeName))
+	}
+
+	if exchangeType != "" {
+		opt = append(opt, rabbitmq.WithConsumerOptionsExchangeKind(exchangeType))
+	}
+
+	if exchangeDeclare {
+		opt = append(opt, rabbitmq.WithConsumerOptionsExchangeDeclare)
+	}
+
+	return rabbitmq.NewConsumer(
+		conn,
+		handler,
+		queueName,
+		opt...,
+	)
+}
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Retry sends a request again when the service didn't get to handle it: the connection
+// couldn't be made or it answered 502/503. Only idempotent methods without a body to
+// replay are retried, a streamed body can't be sent twice, and a timeout isn't, the
+// gateway would just wait on a slow service again.
+type Retry struct {
+	next       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+}
+
+func NewRetry(next http.RoundTripper, maxRetries int, backoff time.Duration) *Retry {
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+
+	return &Retry{
+		next:       next,
+		maxRetries: maxRetries,
+		backoff:    backoff,
+	}
+}
+
+func (r *Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.maxRetries <= 0 || !isRetryable(req) {
+		return r.next.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := r.next.RoundTrip(req)
+		if attempt >= r.maxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(r.backoff << attempt)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+}
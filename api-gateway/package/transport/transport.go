@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewTransport builds the transport the gateway proxies the services with. Every service
+// sits behind a handful of hosts so the idle pool is kept per host, and only the wait for
+// the response headers is bounded, a body may stream for as long as the service sends it.
+func NewTransport(dialTimeout, responseHeaderTimeout, idleConnTimeout time.Duration, maxIdleConnsPerHost int) *http.Transport {
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+	if responseHeaderTimeout <= 0 {
+		responseHeaderTimeout = 10 * time.Second
+	}
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = 90 * time.Second
+	}
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = 64
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          0, // no global cap, MaxIdleConnsPerHost bounds the pool
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   dialTimeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		// the services' bodies are passed on as they are, gzip included
+		DisableCompression: true,
+	}
+}
@@ -5,4 +5,9 @@ import "api-gateway-go/model"
 type ShortenRepoI interface {
 	Get(hashedURL string) (*model.APIManagement, error)
 	Create(apiManagement *model.APIManagement) (*model.APIManagement, error)
+	List() ([]model.APIManagement, error)
+	GetByID(id uint) (*model.APIManagement, error)
+	Update(apiManagement *model.APIManagement) (*model.APIManagement, error)
+	SetAvailable(id uint, isAvailable bool) (*model.APIManagement, error)
+	Delete(id uint) error
 }
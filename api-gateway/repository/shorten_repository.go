@@ -12,6 +12,13 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// apiManagementColumns are the columns scanAPIManagement reads, in its order.
+const apiManagementColumns = `id, api_name, service_name, endpoint_url, 
+				 hashed_endpoint_url, is_available, need_bypass,
+				 rate_limit_ip, rate_limit_user, rate_limit_route, rate_limit_window,
+				 cache_ttl, breaker_failure_threshold, breaker_open_timeout, breaker_half_open_probes,
+				 fallback_status, fallback_body, created_at, updated_at`
+
 type ShortenRepo struct {
 	db    *sql.DB
 	redis *redis.Client
@@ -65,16 +72,16 @@ func (repo *ShortenRepo) Get(hashedURL string) (*model.APIManagement, error) {
 	return apiManagement, nil
 }
 
+// getHashURLFromDatabase also finds the disabled routes, HashedURLConverter turns them away
+// so that they are cached like the others and enabling one is a cache invalidation away.
 func (repo *ShortenRepo) getHashURLFromDatabase(hashedURL string) (*model.APIManagement, error) {
 	ctx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
 	sqlQuery := `
-	SELECT id, api_name, service_name, endpoint_url, 
-				 hashed_endpoint_url, is_available, need_bypass,
-				 created_at, updated_at 
+	SELECT ` + apiManagementColumns + `
 	FROM api_managements 
-	WHERE hashed_endpoint_url = $1 AND is_available = TRUE 
+	WHERE hashed_endpoint_url = $1 
 	LIMIT 1
 	`
 	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
@@ -83,18 +90,7 @@ func (repo *ShortenRepo) getHashURLFromDatabase(hashedURL string) (*model.APIMan
 	}
 	defer stmt.Close()
 
-	apiManagement := new(model.APIManagement)
-	row := stmt.QueryRowContext(ctx, hashedURL)
-	scanErr := row.Scan(
-		&apiManagement.ID, &apiManagement.APIName, &apiManagement.ServiceName, &apiManagement.EndpointURL,
-		&apiManagement.HashedEndpointURL, &apiManagement.IsAvailable, &apiManagement.NeedBypass,
-		&apiManagement.CreatedAt, &apiManagement.UpdatedAt,
-	)
-	if scanErr != nil {
-		return nil, scanErr
-	}
-
-	return apiManagement, nil
+	return scanAPIManagement(stmt.QueryRowContext(ctx, hashedURL))
 }
 
 func (repo *ShortenRepo) Create(apiManagement *model.APIManagement) (*model.APIManagement, error) {
@@ -102,8 +98,13 @@ func (repo *ShortenRepo) Create(apiManagement *model.APIManagement) (*model.APIM
 	defer cancel()
 
 	sqlQuery := `
-	INSERT INTO api_managements (api_name, service_name, endpoint_url, hashed_endpoint_url, is_available, need_bypass)
-	VALUES ($1, $2, $3, $4, $5, $6)
+	INSERT INTO api_managements (
+		api_name, service_name, endpoint_url, hashed_endpoint_url, is_available, need_bypass,
+		rate_limit_ip, rate_limit_user, rate_limit_route, rate_limit_window, cache_ttl,
+		breaker_failure_threshold, breaker_open_timeout, breaker_half_open_probes,
+		fallback_status, fallback_body
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	RETURNING id, created_at, updated_at
 	`
 	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
@@ -117,6 +118,12 @@ func (repo *ShortenRepo) Create(apiManagement *model.APIManagement) (*model.APIM
 		&apiManagement.APIName, &apiManagement.ServiceName,
 		&apiManagement.EndpointURL, &apiManagement.HashedEndpointURL,
 		&apiManagement.IsAvailable, &apiManagement.NeedBypass,
+		apiManagement.RateLimit.IP, apiManagement.RateLimit.User,
+		apiManagement.RateLimit.Route, apiManagement.RateLimit.Window,
+		apiManagement.CacheTTL,
+		apiManagement.Breaker.FailureThreshold, apiManagement.Breaker.OpenTimeout,
+		apiManagement.Breaker.HalfOpenProbes,
+		apiManagement.Fallback.Status, apiManagement.Fallback.Body,
 	).Scan(
 		&apiManagement.ID, &apiManagement.CreatedAt, &apiManagement.UpdatedAt,
 	)
@@ -126,3 +133,189 @@ func (repo *ShortenRepo) Create(apiManagement *model.APIManagement) (*model.APIM
 
 	return apiManagement, nil
 }
+
+func (repo *ShortenRepo) List() ([]model.APIManagement, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	SELECT ` + apiManagementColumns + `
+	FROM api_managements 
+	ORDER BY id
+	`
+	rows, errQuery := repo.db.QueryContext(ctx, sqlQuery)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	defer rows.Close()
+
+	apiManagements := []model.APIManagement{}
+	for rows.Next() {
+		apiManagement, scanErr := scanAPIManagement(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		apiManagements = append(apiManagements, *apiManagement)
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return nil, errRows
+	}
+
+	return apiManagements, nil
+}
+
+func (repo *ShortenRepo) GetByID(id uint) (*model.APIManagement, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	SELECT ` + apiManagementColumns + `
+	FROM api_managements 
+	WHERE id = $1
+	`
+	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
+	if errStmt != nil {
+		return nil, errStmt
+	}
+	defer stmt.Close()
+
+	return scanAPIManagement(stmt.QueryRowContext(ctx, id))
+}
+
+// Update replaces the route with the id of apiManagement. The cached lookup of its old
+// and new hashed url is dropped, the gateway sees the change on the next request.
+func (repo *ShortenRepo) Update(apiManagement *model.APIManagement) (*model.APIManagement, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	// old is read before the update, its hashed url is the cache key to drop
+	sqlQuery := `
+	UPDATE api_managements AS a SET
+		api_name = $2, service_name = $3, endpoint_url = $4, hashed_endpoint_url = $5,
+		is_available = $6, need_bypass = $7,
+		rate_limit_ip = $8, rate_limit_user = $9, rate_limit_route = $10, rate_limit_window = $11,
+		cache_ttl = $12, breaker_failure_threshold = $13, breaker_open_timeout = $14,
+		breaker_half_open_probes = $15, fallback_status = $16, fallback_body = $17,
+		updated_at = CURRENT_TIMESTAMP
+	FROM (SELECT id, hashed_endpoint_url FROM api_managements WHERE id = $1) AS old
+	WHERE a.id = old.id
+	RETURNING old.hashed_endpoint_url, a.created_at, a.updated_at
+	`
+	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
+	if errStmt != nil {
+		return nil, errStmt
+	}
+	defer stmt.Close()
+
+	var oldHashedURL string
+	scanErr := stmt.QueryRowContext(
+		ctx,
+		apiManagement.ID,
+		apiManagement.APIName, apiManagement.ServiceName,
+		apiManagement.EndpointURL, apiManagement.HashedEndpointURL,
+		apiManagement.IsAvailable, apiManagement.NeedBypass,
+		apiManagement.RateLimit.IP, apiManagement.RateLimit.User,
+		apiManagement.RateLimit.Route, apiManagement.RateLimit.Window,
+		apiManagement.CacheTTL,
+		apiManagement.Breaker.FailureThreshold, apiManagement.Breaker.OpenTimeout,
+		apiManagement.Breaker.HalfOpenProbes,
+		apiManagement.Fallback.Status, apiManagement.Fallback.Body,
+	).Scan(
+		&oldHashedURL, &apiManagement.CreatedAt, &apiManagement.UpdatedAt,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	if errDel := repo.invalidate(ctx, oldHashedURL, apiManagement.HashedEndpointURL); errDel != nil {
+		return nil, errDel
+	}
+
+	return apiManagement, nil
+}
+
+// SetAvailable enables or disables the route id and drops its cached lookup.
+func (repo *ShortenRepo) SetAvailable(id uint, isAvailable bool) (*model.APIManagement, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	UPDATE api_managements 
+	SET is_available = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1
+	RETURNING ` + apiManagementColumns + `
+	`
+	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
+	if errStmt != nil {
+		return nil, errStmt
+	}
+	defer stmt.Close()
+
+	apiManagement, scanErr := scanAPIManagement(stmt.QueryRowContext(ctx, id, isAvailable))
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	if errDel := repo.invalidate(ctx, apiManagement.HashedEndpointURL); errDel != nil {
+		return nil, errDel
+	}
+
+	return apiManagement, nil
+}
+
+// Delete removes the route id and drops its cached lookup.
+func (repo *ShortenRepo) Delete(id uint) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	DELETE FROM api_managements 
+	WHERE id = $1
+	RETURNING hashed_endpoint_url
+	`
+	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
+	if errStmt != nil {
+		return errStmt
+	}
+	defer stmt.Close()
+
+	var hashedURL string
+	scanErr := stmt.QueryRowContext(ctx, id).Scan(&hashedURL)
+	if scanErr != nil {
+		return scanErr
+	}
+
+	return repo.invalidate(ctx, hashedURL)
+}
+
+// invalidate drops the cached lookups of hashedURLs that Get keeps for 10 minutes.
+func (repo *ShortenRepo) invalidate(ctx context.Context, hashedURLs ...string) error {
+	keys := make([]string, 0, len(hashedURLs))
+	for _, hashedURL := range hashedURLs {
+		keys = append(keys, "hashed_path:"+hashedURL)
+	}
+	return repo.redis.Del(ctx, keys...).Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIManagement(row scanner) (*model.APIManagement, error) {
+	apiManagement := new(model.APIManagement)
+	scanErr := row.Scan(
+		&apiManagement.ID, &apiManagement.APIName, &apiManagement.ServiceName, &apiManagement.EndpointURL,
+		&apiManagement.HashedEndpointURL, &apiManagement.IsAvailable, &apiManagement.NeedBypass,
+		&apiManagement.RateLimit.IP, &apiManagement.RateLimit.User,
+		&apiManagement.RateLimit.Route, &apiManagement.RateLimit.Window,
+		&apiManagement.CacheTTL,
+		&apiManagement.Breaker.FailureThreshold, &apiManagement.Breaker.OpenTimeout,
+		&apiManagement.Breaker.HalfOpenProbes,
+		&apiManagement.Fallback.Status, &apiManagement.Fallback.Body,
+		&apiManagement.CreatedAt, &apiManagement.UpdatedAt,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return apiManagement, nil
+}
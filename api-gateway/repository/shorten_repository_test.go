@@ -156,11 +156,15 @@ func (s *ShortenRepoSuite) TestShortenRepo_Get() {
 					[]string{
 						"id", "api_name", "service_name", "endpoint_url",
 						"hashed_endpoint_url", "is_available", "need_bypass",
-						"created_at", "updated_at",
+						"rate_limit_ip", "rate_limit_user", "rate_limit_route", "rate_limit_window",
+						"cache_ttl", "breaker_failure_threshold", "breaker_open_timeout", "breaker_half_open_probes",
+						"fallback_status", "fallback_body", "created_at", "updated_at",
 					}).
 					AddRow(
 						apiManagement.ID, apiManagement.APIName, apiManagement.ServiceName, apiManagement.EndpointURL,
 						apiManagement.HashedEndpointURL, apiManagement.IsAvailable, apiManagement.NeedBypass,
+						nil, nil, nil, nil, nil,
+						nil, nil, nil, nil, nil,
 						apiManagement.CreatedAt, apiManagement.UpdatedAt,
 					)
 
@@ -220,11 +224,15 @@ func (s *ShortenRepoSuite) TestShortenRepo_Get() {
 					[]string{
 						"id", "api_name", "service_name", "endpoint_url",
 						"hashed_endpoint_url", "is_available", "need_bypass",
-						"created_at", "updated_at",
+						"rate_limit_ip", "rate_limit_user", "rate_limit_route", "rate_limit_window",
+						"cache_ttl", "breaker_failure_threshold", "breaker_open_timeout", "breaker_half_open_probes",
+						"fallback_status", "fallback_body", "created_at", "updated_at",
 					}).
 					AddRow(
 						apiManagement.ID, apiManagement.APIName, apiManagement.ServiceName, apiManagement.EndpointURL,
 						apiManagement.HashedEndpointURL, apiManagement.IsAvailable, apiManagement.NeedBypass,
+						nil, nil, nil, nil, nil,
+						nil, nil, nil, nil, nil,
 						nil, apiManagement.UpdatedAt,
 					).
 					RowError(1, errors.New("scan error"))
@@ -260,11 +268,15 @@ func (s *ShortenRepoSuite) TestShortenRepo_Get() {
 					[]string{
 						"id", "api_name", "service_name", "endpoint_url",
 						"hashed_endpoint_url", "is_available", "need_bypass",
-						"created_at", "updated_at",
+						"rate_limit_ip", "rate_limit_user", "rate_limit_route", "rate_limit_window",
+						"cache_ttl", "breaker_failure_threshold", "breaker_open_timeout", "breaker_half_open_probes",
+						"fallback_status", "fallback_body", "created_at", "updated_at",
 					}).
 					AddRow(
 						apiManagement.ID, apiManagement.APIName, apiManagement.ServiceName, apiManagement.EndpointURL,
 						apiManagement.HashedEndpointURL, apiManagement.IsAvailable, apiManagement.NeedBypass,
+						nil, nil, nil, nil, nil,
+						nil, nil, nil, nil, nil,
 						apiManagement.CreatedAt, apiManagement.UpdatedAt,
 					)
 
@@ -326,7 +338,7 @@ func (s *ShortenRepoSuite) TestShortenRepo_Create() {
 
 				s.ExpectPrepare(regexp.QuoteMeta(query)).
 					ExpectQuery().
-					WithArgs("wislists", "wislists", "http://localhost:5013/wislists", "CUbICN8VgNk", true, false).
+					WithArgs("wislists", "wislists", "http://localhost:5013/wislists", "CUbICN8VgNk", true, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
 					WillReturnRows(rows)
 			},
 			want: &model.APIManagement{
@@ -378,7 +390,7 @@ func (s *ShortenRepoSuite) TestShortenRepo_Create() {
 
 				s.ExpectPrepare(regexp.QuoteMeta(query)).
 					ExpectQuery().
-					WithArgs("wislists", "wislists", "http://localhost:5013/wislists", "CUbICN8VgNk", true, false).
+					WithArgs("wislists", "wislists", "http://localhost:5013/wislists", "CUbICN8VgNk", true, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
 					WillReturnRows(rows)
 			},
 			wantErr: true,
@@ -387,8 +399,13 @@ func (s *ShortenRepoSuite) TestShortenRepo_Create() {
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
 			sqlQuery := `
-			INSERT INTO api_managements (api_name, service_name, endpoint_url, hashed_endpoint_url, is_available, need_bypass)
-			VALUES ($1, $2, $3, $4, $5, $6)
+			INSERT INTO api_managements (
+				api_name, service_name, endpoint_url, hashed_endpoint_url, is_available, need_bypass,
+				rate_limit_ip, rate_limit_user, rate_limit_route, rate_limit_window, cache_ttl,
+				breaker_failure_threshold, breaker_open_timeout, breaker_half_open_probes,
+				fallback_status, fallback_body
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 			RETURNING id, created_at, updated_at
 			`
 			if tt.beforeTest != nil {
@@ -405,3 +422,174 @@ func (s *ShortenRepoSuite) TestShortenRepo_Create() {
 		})
 	}
 }
+
+func (s *ShortenRepoSuite) TestShortenRepo_SetAvailable() {
+	apiManagement := model.APIManagement{
+		ID:                1,
+		APIName:           "api1",
+		ServiceName:       "service1",
+		EndpointURL:       "http://localhost:5013/api1",
+		HashedEndpointURL: "http://localhost:5013/api1",
+		IsAvailable:       false,
+		CreatedAt:         time.Date(2023, 6, 6, 10, 20, 0, 0, time.Local),
+		UpdatedAt:         time.Date(2023, 6, 7, 10, 20, 0, 0, time.Local),
+	}
+	tests := []struct {
+		name       string
+		beforeTest func(sqlmock.Sqlmock, redismock.ClientMock)
+		want       *model.APIManagement
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			beforeTest: func(s sqlmock.Sqlmock, r redismock.ClientMock) {
+				row := s.NewRows(
+					[]string{
+						"id", "api_name", "service_name", "endpoint_url",
+						"hashed_endpoint_url", "is_available", "need_bypass",
+						"rate_limit_ip", "rate_limit_user", "rate_limit_route", "rate_limit_window",
+						"cache_ttl", "breaker_failure_threshold", "breaker_open_timeout", "breaker_half_open_probes",
+						"fallback_status", "fallback_body", "created_at", "updated_at",
+					}).
+					AddRow(
+						apiManagement.ID, apiManagement.APIName, apiManagement.ServiceName, apiManagement.EndpointURL,
+						apiManagement.HashedEndpointURL, apiManagement.IsAvailable, apiManagement.NeedBypass,
+						nil, nil, nil, nil, nil,
+						nil, nil, nil, nil, nil,
+						apiManagement.CreatedAt, apiManagement.UpdatedAt,
+					)
+
+				s.ExpectPrepare("UPDATE api_managements .* RETURNING .*").
+					ExpectQuery().
+					WithArgs(uint(1), false).
+					WillReturnRows(row)
+
+				r.ExpectDel("hashed_path:" + apiManagement.HashedEndpointURL).SetVal(1)
+			},
+			want: &apiManagement,
+		},
+		{
+			name: "failed - not found",
+			beforeTest: func(s sqlmock.Sqlmock, r redismock.ClientMock) {
+				s.ExpectPrepare("UPDATE api_managements .* RETURNING .*").
+					ExpectQuery().
+					WithArgs(uint(1), false).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed - redis - del",
+			beforeTest: func(s sqlmock.Sqlmock, r redismock.ClientMock) {
+				row := s.NewRows(
+					[]string{
+						"id", "api_name", "service_name", "endpoint_url",
+						"hashed_endpoint_url", "is_available", "need_bypass",
+						"rate_limit_ip", "rate_limit_user", "rate_limit_route", "rate_limit_window",
+						"cache_ttl", "breaker_failure_threshold", "breaker_open_timeout", "breaker_half_open_probes",
+						"fallback_status", "fallback_body", "created_at", "updated_at",
+					}).
+					AddRow(
+						apiManagement.ID, apiManagement.APIName, apiManagement.ServiceName, apiManagement.EndpointURL,
+						apiManagement.HashedEndpointURL, apiManagement.IsAvailable, apiManagement.NeedBypass,
+						nil, nil, nil, nil, nil,
+						nil, nil, nil, nil, nil,
+						apiManagement.CreatedAt, apiManagement.UpdatedAt,
+					)
+
+				s.ExpectPrepare("UPDATE api_managements .* RETURNING .*").
+					ExpectQuery().
+					WithArgs(uint(1), false).
+					WillReturnRows(row)
+
+				r.ExpectDel("hashed_path:" + apiManagement.HashedEndpointURL).SetErr(errors.New("redis error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			if tt.beforeTest != nil {
+				tt.beforeTest(s.mockSQL, s.mockRedis)
+			}
+			got, err := s.repo.SetAvailable(1, false)
+			if (err != nil) != tt.wantErr {
+				s.T().Errorf("ShortenRepo.SetAvailable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				s.T().Errorf("ShortenRepo.SetAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (s *ShortenRepoSuite) TestShortenRepo_Update() {
+	apiManagement := &model.APIManagement{
+		ID:                1,
+		APIName:           "api1",
+		ServiceName:       "service1",
+		EndpointURL:       "http://localhost:5013/api2",
+		HashedEndpointURL: "http://localhost:5013/api2",
+		IsAvailable:       true,
+		NeedBypass:        true,
+	}
+
+	row := s.mockSQL.NewRows([]string{"hashed_endpoint_url", "created_at", "updated_at"}).
+		AddRow("http://localhost:5013/api1", time.Date(2023, 6, 6, 10, 20, 0, 0, time.Local), time.Date(2023, 6, 7, 10, 20, 0, 0, time.Local))
+
+	s.mockSQL.ExpectPrepare("UPDATE api_managements .* FROM .* RETURNING .*").
+		ExpectQuery().
+		WithArgs(
+			uint(1), "api1", "service1", "http://localhost:5013/api2", "http://localhost:5013/api2",
+			true, true, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil,
+		).
+		WillReturnRows(row)
+
+	// the old and the new hashed url may both be cached
+	s.mockRedis.ExpectDel("hashed_path:http://localhost:5013/api1", "hashed_path:http://localhost:5013/api2").SetVal(1)
+
+	got, err := s.repo.Update(apiManagement)
+	s.Require().NoError(err)
+	s.Require().Equal(time.Date(2023, 6, 7, 10, 20, 0, 0, time.Local), got.UpdatedAt)
+}
+
+func (s *ShortenRepoSuite) TestShortenRepo_Delete() {
+	tests := []struct {
+		name       string
+		beforeTest func(sqlmock.Sqlmock, redismock.ClientMock)
+		wantErr    error
+	}{
+		{
+			name: "success",
+			beforeTest: func(s sqlmock.Sqlmock, r redismock.ClientMock) {
+				s.ExpectPrepare("DELETE FROM api_managements .*").
+					ExpectQuery().
+					WithArgs(uint(1)).
+					WillReturnRows(s.NewRows([]string{"hashed_endpoint_url"}).AddRow("hashedURL1"))
+
+				r.ExpectDel("hashed_path:hashedURL1").SetVal(1)
+			},
+		},
+		{
+			name: "failed - not found",
+			beforeTest: func(s sqlmock.Sqlmock, r redismock.ClientMock) {
+				s.ExpectPrepare("DELETE FROM api_managements .*").
+					ExpectQuery().
+					WithArgs(uint(1)).
+					WillReturnRows(s.NewRows([]string{"hashed_endpoint_url"}))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			if tt.beforeTest != nil {
+				tt.beforeTest(s.mockSQL, s.mockRedis)
+			}
+			err := s.repo.Delete(1)
+			s.Require().ErrorIs(err, tt.wantErr)
+		})
+	}
+}
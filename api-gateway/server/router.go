@@ -0,0 +1,17 @@
+package server
+
+import "github.com/gin-gonic/gin"
+
+// NewRouter returns the gin engine of the gateway. gin trusts the X-Forwarded-For of every
+// peer by default, which would let a client pick the IP it is rate limited by, so only the
+// proxies in trustedProxies are trusted; without any ClientIP is the peer's address.
+func NewRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.New()
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
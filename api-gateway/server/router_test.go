@@ -0,0 +1,57 @@
+package server
+
+import (
+	"api-gateway-go/helper/middleware"
+	"api-gateway-go/helper/ratelimit"
+	"api-gateway-go/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRouter_RateLimitKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantKey        string
+	}{
+		{
+			name:    "spoofed X-Forwarded-For",
+			wantKey: "rate_limit:ip:CUbICN8VgNk:192.0.2.1",
+		},
+		{
+			name:           "trusted proxy",
+			trustedProxies: []string{"192.0.2.1"},
+			wantKey:        "rate_limit:ip:CUbICN8VgNk:203.0.113.7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			db, mock := redismock.NewClientMock()
+			defer db.Close()
+			mock.Regexp().ExpectEvalSha(".*", []string{tt.wantKey}, 10, int64(60000)).
+				SetVal([]interface{}{int64(1), int64(10), int64(9), int64(6000), int64(0)})
+
+			router, err := NewRouter(tt.trustedProxies)
+			require.NoError(t, err)
+
+			limit, window := 10, 60
+			router.Use(middleware.RateLimiter(ratelimit.New(db), model.RateLimit{IP: &limit, Window: &window}))
+			router.GET("/:hash", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+			// httptest requests come from 192.0.2.1
+			req := httptest.NewRequest(http.MethodGet, "/CUbICN8VgNk", nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			require.Equal(t, http.StatusOK, res.Code)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -6,4 +6,9 @@ import "api-gateway-go/model"
 type ShortenServiceI interface {
 	Get(hashedURL string) (*model.APIManagement, error)
 	Create(shortenReq *model.ShortenReq) (*model.APIManagement, error)
+	List() ([]model.APIManagement, error)
+	GetByID(id uint) (*model.APIManagement, error)
+	Update(id uint, shortenReq *model.ShortenReq) (*model.APIManagement, error)
+	SetAvailable(id uint, isAvailable bool) (*model.APIManagement, error)
+	Delete(id uint) error
 }
@@ -23,18 +23,48 @@ func (svc *ShortenService) Get(hashedURL string) (*model.APIManagement, error) {
 }
 
 func (svc *ShortenService) Create(shortenReq *model.ShortenReq) (*model.APIManagement, error) {
+	return svc.repo.Create(svc.newAPIManagement(shortenReq))
+}
+
+func (svc *ShortenService) List() ([]model.APIManagement, error) {
+	return svc.repo.List()
+}
+
+func (svc *ShortenService) GetByID(id uint) (*model.APIManagement, error) {
+	return svc.repo.GetByID(id)
+}
+
+// Update replaces every field of the route id with shortenReq, as Create would set them.
+func (svc *ShortenService) Update(id uint, shortenReq *model.ShortenReq) (*model.APIManagement, error) {
+	apiManagement := svc.newAPIManagement(shortenReq)
+	apiManagement.ID = id
+	return svc.repo.Update(apiManagement)
+}
+
+func (svc *ShortenService) SetAvailable(id uint, isAvailable bool) (*model.APIManagement, error) {
+	return svc.repo.SetAvailable(id, isAvailable)
+}
+
+func (svc *ShortenService) Delete(id uint) error {
+	return svc.repo.Delete(id)
+}
+
+func (svc *ShortenService) newAPIManagement(shortenReq *model.ShortenReq) *model.APIManagement {
 	url := shortenReq.EndpointURL
 
 	url = svc.shorten.EnforceHTTP(url)
 	// hashedURL := svc.shorten.Encode(url)
 	hashedURL := url
-	apiManagement := &model.APIManagement{
+	return &model.APIManagement{
 		APIName:           shortenReq.APIName,
 		ServiceName:       shortenReq.ServiceName,
 		EndpointURL:       url,
 		HashedEndpointURL: hashedURL,
 		IsAvailable:       *shortenReq.IsAvailable,
 		NeedBypass:        *shortenReq.NeedBypass,
+		RateLimit:         shortenReq.RateLimit,
+		CacheTTL:          shortenReq.CacheTTL,
+		Breaker:           shortenReq.Breaker,
+		Fallback:          shortenReq.Fallback,
 	}
-	return svc.repo.Create(apiManagement)
 }
@@ -1,21 +1,45 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Debug bool   `mapstructure:"DEBUG"`
-	Port  string `mapstructure:"PORT"`
+	Debug        bool   `mapstructure:"DEBUG"`
+	Port         string `mapstructure:"PORT"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 
-	JWTSecretKey string `mapstructure:"JWT_SECRET_KEY"`
+	// JWTKeyEncryptionKey seals the signing keys in the database, base64 of 32 bytes.
+	JWTKeyEncryptionKey string        `mapstructure:"JWT_KEY_ENCRYPTION_KEY"`
+	JWTKeyRotation      time.Duration `mapstructure:"JWT_KEY_ROTATION"`
 
 	GoogleClientID     string `mapstructure:"GOOGLE_CLIENT_ID"`
 	GoogleClientSecret string `mapstructure:"GOOGLE_CLIENT_SECRET"`
 
+	// OAuthRedirectBaseURL is where the providers send the browser back to, the callback of
+	// a provider is /auth/<name>/callback under it.
+	OAuthRedirectBaseURL string `mapstructure:"OAUTH_REDIRECT_BASE_URL"`
+	// OIDCProviders names the OpenID Connect providers next to google, comma separated. Each
+	// one is set up by OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and _SCOPES.
+	OIDCProviders string         `mapstructure:"OIDC_PROVIDERS"`
+	Providers     []OIDCProvider `mapstructure:"-"`
+
+	// AppURL is the storefront the links of the emails open, /verify-email and
+	// /reset-password with the token in the query.
+	AppURL string `mapstructure:"APP_URL"`
+
+	// MFARequiredRoles are the roles that have to log in with a TOTP code, comma separated,
+	// admin and seller by default. A user of another role can enroll all the same.
+	MFARequiredRoles string   `mapstructure:"MFA_REQUIRED_ROLES"`
+	MFARoles         []string `mapstructure:"-"`
+
 	Database `mapstructure:",squash"`
 	Redis    `mapstructure:",squash"`
 	RabbitMQ `mapstructure:",squash"`
+	SMTP     `mapstructure:",squash"`
 }
 
 type Database struct {
@@ -36,8 +60,24 @@ type RabbitMQ struct {
 	URL string `mapstructure:"RABBITMQ_URL"`
 }
 
+type SMTP struct {
+	Addr     string `mapstructure:"SMTP_ADDR"`
+	Username string `mapstructure:"SMTP_USERNAME"`
+	Password string `mapstructure:"SMTP_PASSWORD"`
+	From     string `mapstructure:"SMTP_FROM"`
+}
+
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigFile(".env")
+	viper.SetDefault("MFA_REQUIRED_ROLES", "admin,seller")
 
 	errRead := viper.ReadInConfig()
 	if errRead != nil {
@@ -49,5 +89,34 @@ func LoadConfig() (*Config, error) {
 	if errUn != nil {
 		return nil, errUn
 	}
+
+	if config.GoogleClientID != "" {
+		config.Providers = append(config.Providers, OIDCProvider{
+			Name:         "google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     config.GoogleClientID,
+			ClientSecret: config.GoogleClientSecret,
+			Scopes:       []string{"email", "profile"},
+		})
+	}
+	for _, name := range strings.Split(config.OIDCProviders, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		config.Providers = append(config.Providers, OIDCProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(strings.ReplaceAll(viper.GetString(prefix+"SCOPES"), ",", " ")),
+		})
+	}
+	for _, role := range strings.Split(config.MFARequiredRoles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			config.MFARoles = append(config.MFARoles, role)
+		}
+	}
 	return config, nil
 }
@@ -4,32 +4,25 @@ import (
 	"auth-go/helper/authjwt"
 	"auth-go/helper/response"
 	"auth-go/model"
+	"auth-go/package/oidcprovider"
+	"auth-go/repository"
 	"auth-go/service"
-	"context"
+	"crypto/subtle"
 	"database/sql"
-	"encoding/json"
 	"errors"
-	"io"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/oauth2"
 )
 
 type AuthHandler struct {
-	svc          service.AuthServiceI
-	jwtSecretKey string
-	gauth        *oauth2.Config
+	svc service.AuthServiceI
 }
 
-func NewAuthHandler(svc service.AuthServiceI, jwtSecretKey string, gauth *oauth2.Config) AuthHandlerI {
+func NewAuthHandler(svc service.AuthServiceI) AuthHandlerI {
 	h := new(AuthHandler)
 	h.svc = svc
-	h.jwtSecretKey = jwtSecretKey
-	h.gauth = gauth
 	return h
 }
 
@@ -71,209 +64,283 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 			response.NewJSONResErr(ctx, http.StatusBadRequest, "", "email didn't existed")
 			return
 		}
+		if errors.Is(errSvc, service.ErrAccountNotVerified) {
+			response.NewJSONResErr(ctx, http.StatusForbidden, "", errSvc.Error())
+			return
+		}
 		_ = ctx.Error(errSvc)
 		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	accessTokenDur := 24 * time.Hour
-	accessToken, errGenAccToken := authjwt.GenerateToken(
-		h.jwtSecretKey, accessTokenDur,
-		user.ID, user.Role,
-	)
-	if errGenAccToken != nil {
-		_ = ctx.Error(errGenAccToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errGenAccToken.Error())
+	result, errLogin := h.svc.Login(user)
+	if errLogin != nil {
+		_ = ctx.Error(errLogin)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errLogin.Error())
 		return
 	}
-
-	refreshTokenDur := 7 * 24 * time.Hour
-	refreshToken, errGenRefToken := authjwt.GenerateToken(
-		h.jwtSecretKey, refreshTokenDur,
-		user.ID, user.Role,
-	)
-	if errGenRefToken != nil {
-		_ = ctx.Error(errGenRefToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errGenRefToken.Error())
+	if result.Tokens == nil {
+		mfaChallenge(ctx, result)
 		return
 	}
+	tokens := result.Tokens
 
 	// ctx.SetCookie("user_id", strconv.FormatUint(uint64(user.ID), 10), 0, "/", "", true, true)
 	// ctx.SetCookie("user_role", user.Role, 0, "", "", true, true)
 
-	ctx.SetCookie("access_token", accessToken, 0, "/", "", false, true)
-
-	// Store the refresh token in Redis
-	dataByte, _ := json.Marshal(model.RefreshToken{
-		UserID:   user.ID,
-		UserRole: user.Role,
-	})
-	errSetRefToken := h.svc.SetRefreshToken(refreshToken, dataByte, refreshTokenDur)
-	if errSetRefToken != nil {
-		_ = ctx.Error(errSetRefToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSetRefToken.Error())
-		return
-	}
-
-	ctx.SetCookie("refresh_token", refreshToken, 0, "/", "", false, true)
+	setTokenCookies(ctx, tokens)
 
 	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
 		"user":          user,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
 	})
 }
 
-func (h *AuthHandler) RefreshToken(ctx *gin.Context) {
-	// Get the refresh token from the request cookies
-	cookie, errCookie := ctx.Cookie("refresh_token")
-	if errCookie != nil {
-		response.NewJSONResErr(ctx, http.StatusBadRequest, "Refresh token required", errCookie.Error())
+// VerifyEmail verifies the email of the token of a verification link.
+func (h *AuthHandler) VerifyEmail(ctx *gin.Context) {
+	verifyReq := new(model.VerifyEmailReq)
+	if bindErr := ctx.ShouldBindJSON(&verifyReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
 		return
 	}
 
-	refreshToken, errSvc := h.svc.GetByRefreshToken(cookie)
+	errSvc := h.svc.VerifyEmail(verifyReq.Token)
 	if errSvc != nil {
-		response.NewJSONResErr(ctx, http.StatusBadRequest, "Invalid refresh token", errSvc.Error())
+		if isInvalidActionToken(errSvc) {
+			response.NewJSONResErr(ctx, http.StatusBadRequest, "", errSvc.Error())
+			return
+		}
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	accessTokenDur := 24 * time.Hour
-	accessToken, errGenAccToken := authjwt.GenerateToken(
-		h.jwtSecretKey, accessTokenDur,
-		refreshToken.UserID, refreshToken.UserRole,
-	)
-	if errGenAccToken != nil {
-		_ = ctx.Error(errGenAccToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errGenAccToken.Error())
+	response.NewJSONRes(ctx, http.StatusOK, "success", nil)
+}
+
+// ForgotPassword emails a reset link, the response is the same whether the email has an
+// account or not.
+func (h *AuthHandler) ForgotPassword(ctx *gin.Context) {
+	forgotReq := new(model.ForgotPasswordReq)
+	if bindErr := ctx.ShouldBindJSON(&forgotReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
 		return
 	}
 
-	ctx.SetCookie("access_token", accessToken, 0, "", "", true, true)
-
-	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
-		"access_token": accessToken,
-	})
-}
+	h.svc.ForgotPassword(forgotReq.Email)
 
-func (h *AuthHandler) GoogleLogin(ctx *gin.Context) {
-	url := h.gauth.AuthCodeURL("state", oauth2.AccessTypeOffline)
-	ctx.Redirect(http.StatusTemporaryRedirect, url)
+	response.NewJSONRes(ctx, http.StatusOK, "if the email has an account, a reset link was sent", nil)
 }
 
-func (h *AuthHandler) GoogleCallback(ctx *gin.Context) {
-	code, exist := ctx.GetQuery("code")
-	if !exist {
-		response.NewJSONResErr(ctx, http.StatusBadRequest, "", "code required")
+// ResetPassword sets the password with the token of a reset link, every session of the
+// user ends.
+func (h *AuthHandler) ResetPassword(ctx *gin.Context) {
+	resetReq := new(model.ResetPasswordReq)
+	if bindErr := ctx.ShouldBindJSON(&resetReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
 		return
 	}
 
-	token, errExc := h.gauth.Exchange(context.Background(), code)
-	if errExc != nil {
-		response.NewJSONResErr(ctx, http.StatusBadRequest, "Failed to exchange token", errExc.Error())
+	errSvc := h.svc.ResetPassword(resetReq.Token, resetReq.Password)
+	if errSvc != nil {
+		if isInvalidActionToken(errSvc) || errors.Is(errSvc, sql.ErrNoRows) {
+			response.NewJSONResErr(ctx, http.StatusBadRequest, "", errSvc.Error())
+			return
+		}
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	userReq := new(model.UserReq)
+	clearTokenCookies(ctx)
+	response.NewJSONRes(ctx, http.StatusOK, "success", nil)
+}
 
-	shouldReturn := h.getUserReq(ctx, token, userReq)
-	if shouldReturn {
+func isInvalidActionToken(err error) bool {
+	return errors.Is(err, authjwt.ErrInvalidActionToken) || errors.Is(err, repository.ErrActionTokenUsed)
+}
+
+// RefreshToken rotates the refresh token, every refresh returns a new one.
+func (h *AuthHandler) RefreshToken(ctx *gin.Context) {
+	// Get the refresh token from the request cookies
+	cookie, errCookie := ctx.Cookie("refresh_token")
+	if errCookie != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "Refresh token required", errCookie.Error())
 		return
 	}
 
-	user, errSvc := h.svc.FirstOrCreate("google", userReq)
+	tokens, errSvc := h.svc.Refresh(cookie)
 	if errSvc != nil {
+		if errors.Is(errSvc, repository.ErrRefreshTokenReused) {
+			clearTokenCookies(ctx)
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "Refresh token reused, log in again", errSvc.Error())
+			return
+		}
+		if errors.Is(errSvc, repository.ErrInvalidRefreshToken) {
+			response.NewJSONResErr(ctx, http.StatusBadRequest, "Invalid refresh token", errSvc.Error())
+			return
+		}
+		if errors.Is(errSvc, service.ErrMFARequired) {
+			clearTokenCookies(ctx)
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "", errSvc.Error())
+			return
+		}
 		_ = ctx.Error(errSvc)
 		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	accessTokenDur := 24 * time.Hour
-	accessToken, errGenAccToken := authjwt.GenerateToken(
-		h.jwtSecretKey, accessTokenDur,
-		user.ID, user.Role,
-	)
-	if errGenAccToken != nil {
-		_ = ctx.Error(errGenAccToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errGenAccToken.Error())
+	setTokenCookies(ctx, tokens)
+
+	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	})
+}
+
+// Logout ends the session of the request's access token, or refresh token, at once. The
+// other sessions of the user stay logged in.
+func (h *AuthHandler) Logout(ctx *gin.Context) {
+	refreshToken, _ := ctx.Cookie("refresh_token")
+
+	errSvc := h.svc.Logout(accessToken(ctx), refreshToken)
+	if errSvc != nil {
+		if errors.Is(errSvc, service.ErrNoSession) {
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "", errSvc.Error())
+			return
+		}
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	refreshTokenDur := 7 * 24 * time.Hour
-	refreshToken, errGenRefToken := authjwt.GenerateToken(
-		h.jwtSecretKey, refreshTokenDur,
-		user.ID, user.Role,
-	)
-	if errGenRefToken != nil {
-		_ = ctx.Error(errGenRefToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errGenRefToken.Error())
+	clearTokenCookies(ctx)
+	response.NewJSONRes(ctx, http.StatusOK, "success", nil)
+}
+
+// LogoutAll ends every session of the user of the request's access token.
+func (h *AuthHandler) LogoutAll(ctx *gin.Context) {
+	errSvc := h.svc.LogoutAll(accessToken(ctx))
+	if errSvc != nil {
+		if errors.Is(errSvc, service.ErrNoSession) {
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "", errSvc.Error())
+			return
+		}
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	ctx.SetCookie("user_id", strconv.FormatUint(uint64(user.ID), 10), 0, "", "", true, true)
-	ctx.SetCookie("user_role", user.Role, 0, "", "", true, true)
+	clearTokenCookies(ctx)
+	response.NewJSONRes(ctx, http.StatusOK, "success", nil)
+}
 
-	ctx.SetCookie("access_token", accessToken, 0, "", "", true, true)
+// accessToken is the bearer token of the request, or its access_token cookie.
+func accessToken(ctx *gin.Context) string {
+	if authHeader := ctx.GetHeader("Authorization"); authHeader != "" {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	cookie, _ := ctx.Cookie("access_token")
+	return cookie
+}
 
-	// Store the refresh token in Redis
-	dataByte, _ := json.Marshal(model.RefreshToken{
-		UserID:   user.ID,
-		UserRole: user.Role,
-	})
-	errSetRefToken := h.svc.SetRefreshToken(refreshToken, dataByte, refreshTokenDur)
-	if errSetRefToken != nil {
-		_ = ctx.Error(errSetRefToken)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSetRefToken.Error())
+// setTokenCookies and clearTokenCookies are the only places the token cookies are
+// written, so they always share their path and flags and a logout clears what a login set.
+func setTokenCookies(ctx *gin.Context, tokens *model.Tokens) {
+	ctx.SetCookie("access_token", tokens.AccessToken, 0, "/", "", false, true)
+	ctx.SetCookie("refresh_token", tokens.RefreshToken, 0, "/", "", false, true)
+}
+
+func clearTokenCookies(ctx *gin.Context) {
+	ctx.SetCookie("access_token", "", -1, "/", "", false, true)
+	ctx.SetCookie("refresh_token", "", -1, "/", "", false, true)
+}
+
+// oauthStateCookie binds a login at a provider to the browser that started it, a callback
+// with a state of another browser is turned away. That keeps a login of the attacker's
+// account from being slipped into the victim's browser.
+const oauthStateCookie = "oauth_state"
+
+// OAuthLogin redirects to the login of the provider.
+func (h *AuthHandler) OAuthLogin(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	authURL, state, errSvc := h.svc.OAuthLogin(provider)
+	if errSvc != nil {
+		if errors.Is(errSvc, oidcprovider.ErrUnknownProvider) {
+			response.NewJSONResErr(ctx, http.StatusNotFound, "", errSvc.Error())
+			return
+		}
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
 		return
 	}
 
-	ctx.SetCookie("refresh_token", refreshToken, 0, "", "", true, true)
-
-	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
-		"user":          user,
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	// lax, the provider sends the browser back with a top level redirect
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookie, state, 600, "/auth/"+provider, "", false, true)
+	ctx.Redirect(http.StatusTemporaryRedirect, authURL)
 }
 
-func (h *AuthHandler) getUserReq(ctx *gin.Context, token *oauth2.Token, userReq *model.UserReq) bool {
-	req, errReq := http.NewRequestWithContext(
-		context.Background(),
-		http.MethodGet, "https://www.googleapis.com/oauth2/v3/userinfo", nil,
-	)
-	if errReq != nil {
-		_ = ctx.Error(errReq)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errReq.Error())
-		return true
+// OAuthCallback logs in the user of the provider account the browser comes back with.
+func (h *AuthHandler) OAuthCallback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	state := ctx.Query("state")
+	cookieState, _ := ctx.Cookie(oauthStateCookie)
+	ctx.SetCookie(oauthStateCookie, "", -1, "/auth/"+provider, "", false, true)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", "invalid state")
+		return
+	}
+
+	if errProvider, exist := ctx.GetQuery("error"); exist {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, errProvider, ctx.Query("error_description"))
+		return
 	}
 
-	client := h.gauth.Client(context.Background(), token)
-	resp, errResp := client.Do(req)
-	if errResp != nil {
-		_ = ctx.Error(errResp)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errResp.Error())
-		return true
+	code, exist := ctx.GetQuery("code")
+	if !exist {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", "code required")
+		return
 	}
-	defer resp.Body.Close()
 
-	body, errRead := io.ReadAll(resp.Body)
-	if errRead != nil {
-		_ = ctx.Error(errRead)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errRead.Error())
-		return true
+	user, errSvc := h.svc.OAuthCallback(ctx.Request.Context(), provider, state, code)
+	if errSvc != nil {
+		switch {
+		case errors.Is(errSvc, oidcprovider.ErrUnknownProvider):
+			response.NewJSONResErr(ctx, http.StatusNotFound, "", errSvc.Error())
+		case errors.Is(errSvc, repository.ErrInvalidOAuthState):
+			response.NewJSONResErr(ctx, http.StatusBadRequest, "", errSvc.Error())
+		case errors.Is(errSvc, oidcprovider.ErrVerify):
+			response.NewJSONResErr(ctx, http.StatusUnauthorized, "Failed to exchange token", errSvc.Error())
+		case errors.Is(errSvc, service.ErrNoEmail), errors.Is(errSvc, service.ErrEmailNotVerified):
+			response.NewJSONResErr(ctx, http.StatusForbidden, "", errSvc.Error())
+		default:
+			_ = ctx.Error(errSvc)
+			response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
+		}
+		return
 	}
 
-	var data map[string]interface{}
-	errJSONUn := json.Unmarshal(body, &data)
-	if errJSONUn != nil {
-		_ = ctx.Error(errJSONUn)
-		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errJSONUn.Error())
-		return true
+	result, errLogin := h.svc.Login(user)
+	if errLogin != nil {
+		_ = ctx.Error(errLogin)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errLogin.Error())
+		return
 	}
+	if result.Tokens == nil {
+		mfaChallenge(ctx, result)
+		return
+	}
+	tokens := result.Tokens
+
+	setTokenCookies(ctx, tokens)
 
-	userReq.Username, _ = data["name"].(string)
-	userReq.Email, _ = data["email"].(string)
-	userReq.FullName, _ = data["name"].(string)
-	userReq.ImageURL, _ = data["picture"].(string)
-	return false
+	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
+		"user":          user,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	})
 }
@@ -5,8 +5,17 @@ import "github.com/gin-gonic/gin"
 type AuthHandlerI interface {
 	Register(ctx *gin.Context)
 	Login(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
 	RefreshToken(ctx *gin.Context)
+	Logout(ctx *gin.Context)
+	LogoutAll(ctx *gin.Context)
 
-	GoogleLogin(ctx *gin.Context)
-	GoogleCallback(ctx *gin.Context)
+	VerifyMFA(ctx *gin.Context)
+	EnrollMFA(ctx *gin.Context)
+	ConfirmMFA(ctx *gin.Context)
+
+	OAuthLogin(ctx *gin.Context)
+	OAuthCallback(ctx *gin.Context)
 }
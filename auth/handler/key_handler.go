@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"auth-go/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeyHandler struct {
+	svc service.KeyServiceI
+}
+
+func NewKeyHandler(svc service.KeyServiceI) KeyHandlerI {
+	h := new(KeyHandler)
+	h.svc = svc
+	return h
+}
+
+type KeyHandlerI interface {
+	JWKS(ctx *gin.Context)
+}
+
+// JWKS is the public keys tokens are verified with, as a plain JWK set without the
+// response envelope so any JWT library can read it.
+func (h *KeyHandler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.svc.JWKS())
+}
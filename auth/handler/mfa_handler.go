@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/helper/response"
+	"auth-go/model"
+	"auth-go/repository"
+	"auth-go/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mfaChallenge answers a correct password of a user that has to give a TOTP code, or enroll
+// first. The login goes on at /auth/mfa with the MFA token, no cookie is set yet.
+func mfaChallenge(ctx *gin.Context, result *model.LoginResult) {
+	response.NewJSONRes(ctx, http.StatusOK, "mfa required", map[string]any{
+		"mfa_required":        true,
+		"mfa_token":           result.MFAToken,
+		"enrollment_required": result.EnrollmentRequired,
+	})
+}
+
+// VerifyMFA finishes a login with a TOTP code, or a recovery code, for its MFA token.
+func (h *AuthHandler) VerifyMFA(ctx *gin.Context) {
+	verifyReq := new(model.MFAVerifyReq)
+	if bindErr := ctx.ShouldBindJSON(&verifyReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
+		return
+	}
+
+	tokens, errSvc := h.svc.VerifyMFA(verifyReq.MFAToken, verifyReq.Code)
+	if errSvc != nil {
+		mfaError(ctx, errSvc)
+		return
+	}
+
+	setTokenCookies(ctx, tokens)
+	response.NewJSONRes(ctx, http.StatusOK, "", map[string]any{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	})
+}
+
+// EnrollMFA starts a TOTP enrollment of the user of the MFA token of a login, or of the
+// request's access token. The secret is only used once a code of it is confirmed.
+func (h *AuthHandler) EnrollMFA(ctx *gin.Context) {
+	enrollReq := new(model.MFAEnrollReq)
+	if bindErr := ctx.ShouldBindJSON(&enrollReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
+		return
+	}
+
+	enrollment, errSvc := h.svc.EnrollMFA(accessToken(ctx), enrollReq.MFAToken)
+	if errSvc != nil {
+		mfaError(ctx, errSvc)
+		return
+	}
+
+	response.NewJSONRes(ctx, http.StatusOK, "", enrollment)
+}
+
+// ConfirmMFA enables the enrollment with a code of its secret and returns the recovery
+// codes, with the tokens of the login when it was confirmed with an MFA token.
+func (h *AuthHandler) ConfirmMFA(ctx *gin.Context) {
+	confirmReq := new(model.MFAConfirmReq)
+	if bindErr := ctx.ShouldBindJSON(&confirmReq); bindErr != nil {
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", bindErr.Error())
+		return
+	}
+
+	confirmation, errSvc := h.svc.ConfirmMFA(accessToken(ctx), confirmReq.MFAToken, confirmReq.Code)
+	if errSvc != nil {
+		mfaError(ctx, errSvc)
+		return
+	}
+
+	data := map[string]any{"recovery_codes": confirmation.RecoveryCodes}
+	if tokens := confirmation.Tokens; tokens != nil {
+		setTokenCookies(ctx, tokens)
+		data["access_token"] = tokens.AccessToken
+		data["refresh_token"] = tokens.RefreshToken
+	}
+	response.NewJSONRes(ctx, http.StatusOK, "", data)
+}
+
+func mfaError(ctx *gin.Context, errSvc error) {
+	switch {
+	case errors.Is(errSvc, service.ErrInvalidMFACode),
+		errors.Is(errSvc, service.ErrNoSession),
+		errors.Is(errSvc, authjwt.ErrInvalidActionToken),
+		errors.Is(errSvc, repository.ErrActionTokenUsed):
+		response.NewJSONResErr(ctx, http.StatusUnauthorized, "", errSvc.Error())
+	case errors.Is(errSvc, service.ErrTooManyMFAFailures):
+		response.NewJSONResErr(ctx, http.StatusTooManyRequests, "", errSvc.Error())
+	case errors.Is(errSvc, repository.ErrMFAEnabled):
+		response.NewJSONResErr(ctx, http.StatusConflict, "", errSvc.Error())
+	case errors.Is(errSvc, service.ErrMFANotEnrolled):
+		response.NewJSONResErr(ctx, http.StatusBadRequest, "", errSvc.Error())
+	default:
+		_ = ctx.Error(errSvc)
+		response.NewJSONResErr(ctx, http.StatusInternalServerError, "", errSvc.Error())
+	}
+}
@@ -1,6 +1,10 @@
 package authjwt
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -10,21 +14,95 @@ import (
 type CustomClaims struct {
 	UserID   string `json:"user_id"`
 	UserRole string `json:"user_role"`
+	// SessionID is the login the token was issued for, every refresh of it shares the id.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a token for a given token duration, user ID, and user Role.
-func GenerateToken(jwtSecretKey string, tokenDur time.Duration, userID uint, userRole string) (string, error) {
+// AudienceAccess is the audience of access tokens, the only EdDSA tokens the gateway and
+// ParseToken take.
+const AudienceAccess = "access"
+
+// GenerateToken generates an access token for a given token duration, user ID, user Role
+// and session ID, signed with the signing key of keys. Every token gets a random ID, the
+// jti a logout denylists it by.
+func GenerateToken(
+	keys *KeySet, tokenDur time.Duration,
+	userID uint, userRole, sessionID string,
+) (string, *CustomClaims, error) {
+	tokenID, errID := NewID()
+	if errID != nil {
+		return "", nil, errID
+	}
+
+	signingKey, errKey := keys.SigningKey()
+	if errKey != nil {
+		return "", nil, errKey
+	}
+
+	now := time.Now()
 	claims := &CustomClaims{
-		UserID:   strconv.FormatUint(uint64(userID), 10),
-		UserRole: userRole,
+		UserID:    strconv.FormatUint(uint64(userID), 10),
+		UserRole:  userRole,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			Issuer:    "ecommerce",
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenDur)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			Audience:  jwt.ClaimStrings{AudienceAccess},
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenDur)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = signingKey.ID
+	signed, errSign := token.SignedString(signingKey.PrivateKey)
+	if errSign != nil {
+		return "", nil, errSign
+	}
+	return signed, claims, nil
+}
+
+// ParseToken validates a token of GenerateToken the way the gateway does.
+func ParseToken(keys *KeySet, tokenString string) (*CustomClaims, error) {
+	claims := new(CustomClaims)
+	_, errParse := jwt.ParseWithClaims(
+		tokenString, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			publicKey, ok := keys.PublicKey(kid)
+			if !ok {
+				return nil, fmt.Errorf("unknown signing key %q", kid)
+			}
+			return publicKey, nil
 		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer("ecommerce"),
+		jwt.WithAudience(AudienceAccess),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(5*time.Second),
+	)
+	if errParse != nil {
+		return nil, errParse
+	}
+	return claims, nil
+}
+
+// NewID returns a random id for a token or a session.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewRefreshToken returns a random refresh token. It is opaque, only its entry in Redis
+// gives it a meaning, so it can't be mistaken for an access token.
+func NewRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecretKey))
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,201 @@
+package authjwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoSigningKey is a token asked for before any key was loaded.
+var ErrNoSigningKey = errors.New("no signing key")
+
+// SigningKey is an Ed25519 key tokens are signed with, the ID is the kid of their header.
+type SigningKey struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+	CreatedAt  time.Time
+}
+
+// JWK is the public half of a SigningKey, as the JWKS endpoint publishes it.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	X   string `json:"x"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet holds the keys that are live, it's replaced as a whole each time they're loaded.
+// A new key is published for publishDelay before it signs, so a verifier with a cached
+// JWKS already knows it by the time it sees a token of it.
+type KeySet struct {
+	mu           sync.RWMutex
+	keys         []*SigningKey
+	publishDelay time.Duration
+}
+
+func NewKeySet(publishDelay time.Duration) *KeySet {
+	return &KeySet{publishDelay: publishDelay}
+}
+
+// Set replaces the keys of the set.
+func (ks *KeySet) Set(keys []*SigningKey) {
+	sorted := make([]*SigningKey, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	ks.mu.Lock()
+	ks.keys = sorted
+	ks.mu.Unlock()
+}
+
+// Newest is the latest key of the set, nil when it's empty.
+func (ks *KeySet) Newest() *SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(ks.keys) == 0 {
+		return nil
+	}
+	return ks.keys[0]
+}
+
+// SigningKey is the newest key that has been published long enough. When none has, like
+// on the first start, the oldest key signs.
+func (ks *KeySet) SigningKey() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(ks.keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	published := time.Now().Add(-ks.publishDelay)
+	for _, key := range ks.keys {
+		if !key.CreatedAt.After(published) {
+			return key, nil
+		}
+	}
+	return ks.keys[len(ks.keys)-1], nil
+}
+
+// PublicKey is the public key of the key with the id, to verify a token with.
+func (ks *KeySet) PublicKey(kid string) (ed25519.PublicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for _, key := range ks.keys {
+		if key.ID == kid {
+			return key.PrivateKey.Public().(ed25519.PublicKey), true
+		}
+	}
+	return nil, false
+}
+
+// JWKS is every key of the set, the ones that don't sign yet or anymore included.
+func (ks *KeySet) JWKS() *JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	jwks := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			Kid: key.ID,
+			Use: "sig",
+			Alg: "EdDSA",
+			X:   base64.RawURLEncoding.EncodeToString(key.PrivateKey.Public().(ed25519.PublicKey)),
+		})
+	}
+	return jwks
+}
+
+// NewSigningKey generates a key with a random id.
+func NewSigningKey() (*SigningKey, error) {
+	kid, errID := NewID()
+	if errID != nil {
+		return nil, errID
+	}
+	_, private, errGen := ed25519.GenerateKey(rand.Reader)
+	if errGen != nil {
+		return nil, errGen
+	}
+	return &SigningKey{ID: kid, PrivateKey: private, CreatedAt: time.Now()}, nil
+}
+
+// DecodeKEK decodes the key the secrets of the service are encrypted with at rest, base64
+// of 32 bytes.
+func DecodeKEK(s string) ([]byte, error) {
+	kek, errDecode := base64.StdEncoding.DecodeString(s)
+	if errDecode != nil {
+		return nil, fmt.Errorf("key encryption key: %w", errDecode)
+	}
+	if len(kek) != 32 {
+		return nil, fmt.Errorf("key encryption key: want 32 bytes, got %d", len(kek))
+	}
+	return kek, nil
+}
+
+// SealKey encrypts the seed of the key, so whoever reads the database can't sign with it.
+// The kid is authenticated along, a sealed key can't be moved to another id.
+func SealKey(kek []byte, key *SigningKey) ([]byte, error) {
+	return Seal(kek, []byte(key.ID), key.PrivateKey.Seed())
+}
+
+// OpenKey decrypts a key of SealKey.
+func OpenKey(kek []byte, kid string, createdAt time.Time, sealed []byte) (*SigningKey, error) {
+	seed, errOpen := Open(kek, []byte(kid), sealed)
+	if errOpen != nil {
+		return nil, fmt.Errorf("signing key %s: %w", kid, errOpen)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key %s: bad seed size %d", kid, len(seed))
+	}
+	return &SigningKey{ID: kid, PrivateKey: ed25519.NewKeyFromSeed(seed), CreatedAt: createdAt}, nil
+}
+
+// Seal encrypts a secret kept at rest with AES-GCM under the key encryption key, aad is
+// what it belongs to and has to be the same to open it.
+func Seal(kek, aad, plaintext []byte) ([]byte, error) {
+	gcm, errGCM := newGCM(kek)
+	if errGCM != nil {
+		return nil, errGCM
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open decrypts a secret of Seal.
+func Open(kek, aad, sealed []byte) ([]byte, error) {
+	gcm, errGCM := newGCM(kek)
+	if errGCM != nil {
+		return nil, errGCM
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed secret too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(kek []byte) (cipher.AEAD, error) {
+	block, errBlock := aes.NewCipher(kek)
+	if errBlock != nil {
+		return nil, errBlock
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,120 @@
+package authjwt_test
+
+import (
+	"auth-go/helper/authjwt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T, age time.Duration) *authjwt.SigningKey {
+	key, err := authjwt.NewSigningKey()
+	require.NoError(t, err)
+	key.CreatedAt = time.Now().Add(-age)
+	return key
+}
+
+func TestKeySet_SigningKey(t *testing.T) {
+	old := newKey(t, 48*time.Hour)
+	published := newKey(t, time.Hour)
+	fresh := newKey(t, time.Minute)
+
+	tests := []struct {
+		name    string
+		keys    []*authjwt.SigningKey
+		want    *authjwt.SigningKey
+		wantErr error
+	}{
+		{
+			name:    "failed - no keys",
+			wantErr: authjwt.ErrNoSigningKey,
+		},
+		{
+			name: "newest published key",
+			keys: []*authjwt.SigningKey{old, fresh, published},
+			want: published,
+		},
+		{
+			name: "only unpublished keys - oldest",
+			keys: []*authjwt.SigningKey{fresh},
+			want: fresh,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := authjwt.NewKeySet(10 * time.Minute)
+			keys.Set(tt.keys)
+
+			got, err := keys.SigningKey()
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	current, retired := newKey(t, time.Hour), newKey(t, 48*time.Hour)
+	keys := authjwt.NewKeySet(10 * time.Minute)
+	keys.Set([]*authjwt.SigningKey{retired})
+
+	oldToken, _, err := authjwt.GenerateToken(keys, time.Hour, 7, "user", "session1")
+	require.NoError(t, err)
+
+	keys.Set([]*authjwt.SigningKey{current, retired})
+	token, _, err := authjwt.GenerateToken(keys, time.Hour, 7, "user", "session1")
+	require.NoError(t, err)
+
+	// a token of the key before the rotation stays valid while its key is in the set
+	for _, tokenString := range []string{oldToken, token} {
+		claims, errParse := authjwt.ParseToken(keys, tokenString)
+		require.NoError(t, errParse)
+		require.Equal(t, "7", claims.UserID)
+		require.Equal(t, "session1", claims.SessionID)
+	}
+
+	keys.Set([]*authjwt.SigningKey{current})
+	_, err = authjwt.ParseToken(keys, oldToken)
+	require.Error(t, err)
+
+	require.Len(t, keys.JWKS().Keys, 1)
+	require.Equal(t, current.ID, keys.JWKS().Keys[0].Kid)
+
+	// a token of the same key without the access audience isn't an access token
+	now := time.Now()
+	other := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &authjwt.CustomClaims{
+		UserID: "7",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "token1",
+			Issuer:    "ecommerce",
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	other.Header["kid"] = current.ID
+	otherToken, err := other.SignedString(current.PrivateKey)
+	require.NoError(t, err)
+	_, err = authjwt.ParseToken(keys, otherToken)
+	require.Error(t, err)
+}
+
+func TestSealKey(t *testing.T) {
+	kek, err := authjwt.DecodeKEK("yZIxAxObXKxIkOMmpqBGueqkEVeIS+Zth55YHgiIOCo=")
+	require.NoError(t, err)
+	key := newKey(t, 0)
+
+	sealed, err := authjwt.SealKey(kek, key)
+	require.NoError(t, err)
+
+	opened, err := authjwt.OpenKey(kek, key.ID, key.CreatedAt, sealed)
+	require.NoError(t, err)
+	require.Equal(t, key.PrivateKey, opened.PrivateKey)
+
+	// the sealed key is bound to its kid
+	_, err = authjwt.OpenKey(kek, "another", key.CreatedAt, sealed)
+	require.Error(t, err)
+
+	_, err = authjwt.DecodeKEK("c2hvcnQ=")
+	require.Error(t, err)
+}
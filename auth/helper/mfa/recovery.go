@@ -0,0 +1,47 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// recoveryEncoding leaves out l, o, 0 and 1, the ones easy to mix up.
+var recoveryEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// RecoveryHasher hashes recovery codes with HMAC keyed from the key encryption key, the
+// hashes in the database can't be guessed at without it.
+type RecoveryHasher struct {
+	key []byte
+}
+
+func NewRecoveryHasher(kek []byte) *RecoveryHasher {
+	mac := hmac.New(sha256.New, kek)
+	mac.Write([]byte("recovery code"))
+	return &RecoveryHasher{key: mac.Sum(nil)}
+}
+
+// Hash is the hash of a code as the user typed it, dashes, spaces and case don't matter.
+func (h *RecoveryHasher) Hash(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewRecoveryCodes is n random codes of 50 bits, as xxxxx-xxxxx.
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := recoveryEncoding.EncodeToString(b)[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
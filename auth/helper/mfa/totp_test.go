@@ -0,0 +1,92 @@
+package mfa_test
+
+import (
+	"auth-go/helper/mfa"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the SHA1 secret of the test vectors of RFC 6238
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			require.Equal(t, tt.want, mfa.Code(rfcSecret, tt.unix/30))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := now.Unix() / 30
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current code", code: "081804", wantStep: step, wantOK: true},
+		{name: "code of the last period", code: mfa.Code(rfcSecret, step-1), wantStep: step - 1, wantOK: true},
+		{name: "code of the next period", code: mfa.Code(rfcSecret, step+1), wantStep: step + 1, wantOK: true},
+		{name: "failed - code of two periods ago", code: mfa.Code(rfcSecret, step-2)},
+		{name: "failed - wrong code", code: "000000"},
+		{name: "failed - not 6 digits", code: "81804"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := mfa.Validate(rfcSecret, tt.code, now)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantStep, gotStep)
+		})
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+
+	uri, err := url.Parse(mfa.ProvisioningURI("ecommerce", "budi@mail.com", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/ecommerce:budi@mail.com", uri.Path)
+	require.Equal(t, mfa.EncodeSecret(secret), uri.Query().Get("secret"))
+	require.Equal(t, "ecommerce", uri.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := mfa.NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	hasher := mfa.NewRecoveryHasher([]byte("0123456789abcdef0123456789abcdef"))
+	seen := map[string]bool{}
+	for _, code := range codes {
+		require.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, code)
+		require.False(t, seen[code])
+		seen[code] = true
+
+		// typed without the dash or in capitals, it's the same code
+		typed := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+		require.Equal(t, hasher.Hash(code), hasher.Hash(typed))
+	}
+
+	other := mfa.NewRecoveryHasher([]byte("another key of 32 bytes, not it!"))
+	require.NotEqual(t, hasher.Hash(codes[0]), other.Hash(codes[0]))
+}
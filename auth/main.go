@@ -3,21 +3,24 @@ package main
 import (
 	"auth-go/config"
 	"auth-go/handler"
+	"auth-go/helper/authjwt"
 	"auth-go/helper/logging"
 	"auth-go/helper/middleware"
 	"auth-go/package/db"
+	"auth-go/package/mailer"
+	"auth-go/package/oidcprovider"
 	"auth-go/package/redisclient"
 	"auth-go/package/rmq"
 	"auth-go/repository"
 	"auth-go/server"
 	"auth-go/service"
+	"context"
 	"log"
 	"net/http"
+	"telemetry-go"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 //nolint:funlen // hard to avoid
@@ -31,6 +34,16 @@ func main() {
 	// setup logger config
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "auth", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	// connect to postgres using gorm
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
@@ -74,38 +87,79 @@ func main() {
 		logger.Debug().Msg("rabbitmq closed")
 	}()
 
-	// setup google oauth2 config
-	gauth := &oauth2.Config{
-		ClientID:     config.GoogleClientID,
-		ClientSecret: config.GoogleClientSecret,
-		RedirectURL:  "http://localhost:" + config.Port + "/auth/google/callback",
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-		Endpoint: google.Endpoint,
+	// discover the OpenID Connect providers users can log in with
+	providers := oidcprovider.NewRegistry()
+	redirectBaseURL := config.OAuthRedirectBaseURL
+	if redirectBaseURL == "" {
+		redirectBaseURL = "http://localhost:" + config.Port
+	}
+	for _, providerConf := range config.Providers {
+		provider, errProvider := oidcprovider.New(context.Background(), providerConf, redirectBaseURL)
+		if errProvider != nil {
+			logger.Error().Err(errProvider).Msg("oidc provider failed to load")
+			continue
+		}
+		providers.Add(provider)
+	}
+
+	// load the signing keys, making the first one when there is none, and keep rotating them
+	kek, errKEK := authjwt.DecodeKEK(config.JWTKeyEncryptionKey)
+	if errKEK != nil {
+		logger.Fatal().Err(errKEK).Msg("invalid JWT_KEY_ENCRYPTION_KEY")
+	}
+	keyRotation := config.JWTKeyRotation
+	if keyRotation <= 0 {
+		keyRotation = 30 * 24 * time.Hour
+	}
+	keys := authjwt.NewKeySet(service.KeyPublishDelay)
+	keyRepo := repository.NewKeyRepository(sqlDB.SQLDB)
+	keySvc := service.NewKeyService(keyRepo, keys, kek, keyRotation)
+	if errRotate := keySvc.Rotate(); errRotate != nil {
+		logger.Fatal().Err(errRotate).Msg("signing keys failed to load")
 	}
+	rotateCtx, stopRotate := context.WithCancel(context.Background())
+	defer stopRotate()
+	go keySvc.Run(rotateCtx)
+	keyHandler := handler.NewKeyHandler(keySvc)
 
 	authRepo := repository.NewAuthRepository(sqlDB.SQLDB, redisClient.Redis, rmqConn)
-	authSvc := service.NewAuthService(authRepo)
-	authHandler := handler.NewAuthHandler(authSvc, config.JWTSecretKey, gauth)
+	mail := mailer.NewSMTP(config.SMTP.Addr, config.SMTP.Username, config.SMTP.Password, config.SMTP.From)
+	authSvc := service.NewAuthService(
+		authRepo, keys, providers,
+		authjwt.NewActionSigner(kek), mail, config.AppURL,
+		kek, config.MFARoles,
+	)
+	authHandler := handler.NewAuthHandler(authSvc)
 
 	pingHandler := handler.NewPingGinHandler()
 
 	router := gin.New()
+	router.Use(telemetry.Middleware("auth"))
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
 	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 
+	router.GET("/.well-known/jwks.json", keyHandler.JWKS)
+
 	authRouter := router.Group("/auth")
 	{
 		authRouter.GET("/ping", pingHandler.Ping)
 
 		authRouter.POST("/register", authHandler.Register)
 		authRouter.POST("/login", authHandler.Login)
+		authRouter.POST("/verify-email", authHandler.VerifyEmail)
+		authRouter.POST("/forgot-password", authHandler.ForgotPassword)
+		authRouter.POST("/reset-password", authHandler.ResetPassword)
 		authRouter.POST("/refresh-token", authHandler.RefreshToken)
+		authRouter.POST("/logout", authHandler.Logout)
+		authRouter.POST("/logout-all", authHandler.LogoutAll)
+
+		authRouter.POST("/mfa/verify", authHandler.VerifyMFA)
+		authRouter.POST("/mfa/enroll", authHandler.EnrollMFA)
+		authRouter.POST("/mfa/enroll/confirm", authHandler.ConfirmMFA)
 
-		authRouter.GET("/google/login", authHandler.GoogleLogin)
-		authRouter.GET("/google/callback", authHandler.GoogleCallback)
+		authRouter.GET("/:provider/login", authHandler.OAuthLogin)
+		authRouter.GET("/:provider/callback", authHandler.OAuthCallback)
 	}
 
 	srv := &http.Server{
@@ -0,0 +1,19 @@
+package model
+
+// Identity is the account of a user at an OpenID Connect provider, as its ID token tells.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+	Picture       string
+}
+
+// OAuthState is what a login at a provider has to come back with to its callback.
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// MFA is the TOTP second factor of a user. The secret is sealed under the key encryption
+// key, it's pending until the first code of it confirms the enrollment.
+type MFA struct {
+	UserID       uint
+	Secret       []byte
+	EnabledAt    *time.Time
+	LastUsedStep int64
+}
+
+// LoginResult is the end of a correct password, or provider login. Either the tokens of a
+// new session, or an MFA token the login goes on with at /auth/mfa.
+type LoginResult struct {
+	Tokens *Tokens
+	// MFAToken is the challenge of a user that has to give a TOTP code, it lasts minutes.
+	MFAToken string
+	// EnrollmentRequired is a user whose role needs MFA but that hasn't enrolled yet, the
+	// MFA token enrolls.
+	EnrollmentRequired bool
+}
+
+// MFAEnrollment is the secret of a pending enrollment, for an authenticator app to scan
+// as a QR code of the URI or to type in.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAConfirmation is an enabled MFA, the recovery codes are shown this one time. The
+// tokens are of the login an MFA token confirmed.
+type MFAConfirmation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Tokens        *Tokens  `json:"-"`
+}
+
+type MFAVerifyReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is a TOTP code or a recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAEnrollReq enrolls the user of the MFA token of a login, or without it the user of the
+// access token.
+type MFAEnrollReq struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type MFAConfirmReq struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" binding:"required"`
+}
@@ -4,4 +4,11 @@ type RefreshToken struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	UserID       uint   `json:"user_id,omitempty"`
 	UserRole     string `json:"user_role,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`
+}
+
+// Tokens are the tokens of a login or of a refresh, the refresh token can be used once.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// SigningKey is a row of signing_keys, the private key is sealed with the key encryption key.
+type SigningKey struct {
+	ID         string
+	PrivateKey []byte
+	CreatedAt  time.Time
+}
@@ -15,6 +15,11 @@ type User struct {
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 
+	// EmailVerifiedAt is nil until the user follows the link of the verification email.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// MFAEnabled is a user that logs in with a TOTP code after the password.
+	MFAEnabled bool `json:"mfa_enabled"`
+
 	UserSetting UserSetting `json:"user_setting,omitempty"`
 }
 
@@ -24,14 +29,20 @@ type RegisterReq struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type VerifyEmailReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
 type LoginReq struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
-
-type UserReq struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	FullName string `json:"full_name"`
-	ImageURL string `json:"image_url"`
-}
@@ -0,0 +1,162 @@
+package oidcprovider
+
+import (
+	"auth-go/config"
+	"auth-go/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown provider")
+	// ErrVerify is a code that can't be exchanged or an ID token that doesn't verify.
+	ErrVerify = errors.New("failed to verify the provider login")
+)
+
+// Provider is an OpenID Connect provider users log in with, by the authorization code flow
+// with PKCE. Its endpoints and keys are discovered from the issuer.
+type Provider struct {
+	name     string
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// New discovers the provider of cfg, its callback is /auth/<name>/callback of redirectBaseURL.
+func New(ctx context.Context, cfg config.OIDCProvider, redirectBaseURL string) (*Provider, error) {
+	provider, errDiscover := oidc.NewProvider(ctx, cfg.Issuer)
+	if errDiscover != nil {
+		return nil, fmt.Errorf("provider %s: %w", cfg.Name, errDiscover)
+	}
+
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range cfg.Scopes {
+		if scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &Provider{
+		name: cfg.Name,
+		oauth2: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  strings.TrimSuffix(redirectBaseURL, "/") + "/auth/" + cfg.Name + "/callback",
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL is where the browser logs in, the provider sends it back with the state.
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	return p.oauth2.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+}
+
+// Exchange trades the code for an ID token and verifies it was issued for the login of
+// nonce and codeVerifier.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*model.Identity, error) {
+	token, errExchange := p.oauth2.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if errExchange != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVerify, errExchange)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no id_token", ErrVerify)
+	}
+	idToken, errVerify := p.verifier.Verify(ctx, rawIDToken)
+	if errVerify != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVerify, errVerify)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrVerify)
+	}
+
+	var claims struct {
+		Email             string       `json:"email"`
+		EmailVerified     flexibleBool `json:"email_verified"`
+		Name              string       `json:"name"`
+		PreferredUsername string       `json:"preferred_username"`
+		Picture           string       `json:"picture"`
+	}
+	if errClaims := idToken.Claims(&claims); errClaims != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVerify, errClaims)
+	}
+
+	return &model.Identity{
+		Provider:      p.name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Username:      claims.PreferredUsername,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// flexibleBool reads email_verified, that a few providers send as a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(v == "true")
+	}
+	return nil
+}
+
+// NewCodeVerifier is a random PKCE code verifier.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Registry is the providers users can log in with, by name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]*Provider{}}
+}
+
+func (r *Registry) Add(p *Provider) {
+	r.providers[p.name] = p
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return p, nil
+}
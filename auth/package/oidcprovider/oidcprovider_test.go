@@ -0,0 +1,181 @@
+package oidcprovider_test
+
+import (
+	"auth-go/config"
+	"auth-go/model"
+	"auth-go/package/oidcprovider"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDC is an OpenID Connect provider that logs in whoever asks for the code "code1",
+// the nonce and PKCE challenge are the ones of the last authorization URL it was given.
+type mockOIDC struct {
+	srv       *httptest.Server
+	key       *rsa.PrivateKey
+	nonce     string
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	m := &mockOIDC{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.srv.URL,
+			"authorization_endpoint":                m.srv.URL + "/authorize",
+			"token_endpoint":                        m.srv.URL + "/token",
+			"jwks_uri":                              m.srv.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA", "kid": "key1", "alg": "RS256", "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "code1" ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != m.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   m.srv.URL,
+			"aud":   "client1",
+			"sub":   "subject1",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": m.nonce,
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key1"
+		idToken, errSign := token.SignedString(key)
+		require.NoError(t, errSign)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access1",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// login is the user logging in at the provider with the authorization URL.
+func (m *mockOIDC) login(t *testing.T, authURL string) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	m.nonce = u.Query().Get("nonce")
+	m.challenge = u.Query().Get("code_challenge")
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		code     string
+		verifier string
+		nonce    string
+		want     *model.Identity
+		wantErr  error
+	}{
+		{
+			name:   "success",
+			claims: jwt.MapClaims{"email": "budi@mail.com", "email_verified": "true", "name": "Budi"},
+			want: &model.Identity{
+				Provider: "mock", Subject: "subject1",
+				Email: "budi@mail.com", EmailVerified: true, Name: "Budi",
+			},
+		},
+		{
+			name:     "failed - wrong code verifier",
+			verifier: "another-verifier-of-an-attacker-0000000000000",
+			wantErr:  oidcprovider.ErrVerify,
+		},
+		{
+			name:    "failed - wrong nonce",
+			nonce:   "another-nonce",
+			wantErr: oidcprovider.ErrVerify,
+		},
+		{
+			name:    "failed - wrong audience",
+			claims:  jwt.MapClaims{"aud": "another-client"},
+			wantErr: oidcprovider.ErrVerify,
+		},
+		{
+			name:    "failed - unknown code",
+			code:    "code2",
+			wantErr: oidcprovider.ErrVerify,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			m.claims = tt.claims
+
+			provider, err := oidcprovider.New(context.Background(), config.OIDCProvider{
+				Name: "mock", Issuer: m.srv.URL, ClientID: "client1", ClientSecret: "secret1",
+			}, "http://localhost:5002")
+			require.NoError(t, err)
+
+			verifier, err := oidcprovider.NewCodeVerifier()
+			require.NoError(t, err)
+			m.login(t, provider.AuthCodeURL("state1", "nonce1", verifier))
+
+			code, nonce := "code1", "nonce1"
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			got, err := provider.Exchange(context.Background(), code, verifier, nonce)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistry_Get(t *testing.T) {
+	_, err := oidcprovider.NewRegistry().Get("google")
+	require.ErrorIs(t, err, oidcprovider.ErrUnknownProvider)
+}
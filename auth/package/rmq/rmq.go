@@ -4,6 +4,7 @@ package rmq
 import (
 	"context"
 	"log"
+	"telemetry-go"
 
 	"github.com/wagslane/go-rabbitmq"
 )
@@ -86,6 +87,9 @@ func PublishWithContext(
 		opt = append(opt, rabbitmq.WithPublishOptionsExchange(exchangeName))
 	}
 
+	// consumers continue the trace of ctx from the message headers
+	opt = append(opt, rabbitmq.WithPublishOptionsHeaders(telemetry.Inject(ctx, nil)))
+
 	errPub := publisher.PublishWithContext(
 		ctx,
 		dataBytes,
@@ -93,6 +97,9 @@ func PublishWithContext(
 		opt...,
 	)
 	if errPub != nil {
+		for _, key := range routingKeysPub {
+			telemetry.PublishFailed(key)
+		}
 		return errPub
 	}
 
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"auth-go/helper/timeout"
+	"errors"
+	"time"
+)
+
+// ErrActionTokenUsed is an emailed token that was used already.
+var ErrActionTokenUsed = errors.New("token already used")
+
+// UseActionToken marks the token with the id used, until it expires. Using it again is
+// ErrActionTokenUsed.
+func (repo *AuthRepository) UseActionToken(tokenID string, expiresAt time.Time) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return ErrActionTokenUsed
+	}
+
+	set, errSet := repo.redis.SetNX(ctx, "used_action_token:"+tokenID, 1, ttl).Result()
+	if errSet != nil {
+		return errSet
+	}
+	if !set {
+		return ErrActionTokenUsed
+	}
+	return nil
+}
+
+// MarkEmailVerified marks the email of the user verified. With dropUnverifiedPassword, a
+// password set while it wasn't is removed since whoever set it never showed they own the
+// email, the owner can set one by a password reset.
+func (repo *AuthRepository) MarkEmailVerified(email string, dropUnverifiedPassword bool) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	UPDATE users
+	SET email_verified_at = now(),
+	    password = CASE WHEN $2 THEN '' ELSE password END,
+	    updated_at = now()
+	WHERE email = $1 AND email_verified_at IS NULL
+	`
+	_, errExec := repo.db.ExecContext(ctx, sqlQuery, email, dropUnverifiedPassword)
+	return errExec
+}
+
+// UpdatePassword replaces the password oldHashedPassword of the user of the email and
+// returns its id, the email is verified too since only its owner gets a reset.
+// sql.ErrNoRows when no user has it or the password is no longer oldHashedPassword.
+func (repo *AuthRepository) UpdatePassword(email, oldHashedPassword, hashedPassword string) (uint, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	UPDATE users
+	SET password = $2,
+	    email_verified_at = COALESCE(email_verified_at, now()),
+	    updated_at = now()
+	WHERE email = $1 AND password = $3
+	RETURNING id
+	`
+	var userID uint
+	errScan := repo.db.QueryRowContext(ctx, sqlQuery, email, hashedPassword, oldHashedPassword).Scan(&userID)
+	if errScan != nil {
+		return 0, errScan
+	}
+	return userID, nil
+}
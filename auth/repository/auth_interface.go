@@ -8,8 +8,31 @@ import (
 type AuthRepositoryI interface {
 	Create(user *model.User) error
 	LoginByEmail(email string) (*model.User, error)
+	GetByID(id uint) (*model.User, error)
 	FirstOrCreate(user *model.User) (*model.User, error)
 
-	SetRefreshToken(refreshToken string, dataByte []byte, refreshTokenDur time.Duration) error
+	SetOAuthState(state string, oauthState *model.OAuthState, stateDur time.Duration) error
+	TakeOAuthState(state string) (*model.OAuthState, error)
+	GetByIdentity(provider, subject string) (*model.User, error)
+	LinkIdentity(userID uint, identity *model.Identity) error
+
+	UseActionToken(tokenID string, expiresAt time.Time) error
+	MarkEmailVerified(email string, dropUnverifiedPassword bool) error
+	UpdatePassword(email, oldHashedPassword, hashedPassword string) (uint, error)
+
+	GetMFA(userID uint) (*model.MFA, error)
+	SetPendingMFA(userID uint, secret []byte) error
+	EnableMFA(userID uint, step int64, codeHashes []string) error
+	UseTOTPStep(userID uint, step int64) error
+	UseRecoveryCode(userID uint, codeHash string) error
+	MFAFailures(userID uint) (int64, error)
+	AddMFAFailure(userID uint, window time.Duration) error
+
+	SetRefreshToken(token string, refreshToken *model.RefreshToken, refreshTokenDur time.Duration) error
+	AddAccessToken(sessionID, tokenID string, expiresAt time.Time) error
 	GetByRefreshToken(token string) (*model.RefreshToken, error)
+	RotateRefreshToken(token string) (*model.RefreshToken, error)
+	SessionExists(sessionID string) (bool, error)
+	RevokeSession(sessionID string) error
+	RevokeUserSessions(userID uint) error
 }
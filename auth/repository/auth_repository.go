@@ -173,7 +173,8 @@ func (repo *AuthRepository) LoginByEmail(email string) (*model.User, error) {
 
 	sqlQuery := `
 	SELECT id, username, email, password, role, provider,
-	    	  full_name, age, image_url, created_at, updated_at
+	    	  full_name, age, image_url, email_verified_at, created_at, updated_at,
+	       EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = users.id AND m.enabled_at IS NOT NULL)
 	FROM users 
 	WHERE email = $1
 	LIMIT 1
@@ -189,7 +190,8 @@ func (repo *AuthRepository) LoginByEmail(email string) (*model.User, error) {
 	scanErr := row.Scan(
 		&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.Provider,
 		&user.FullName, &user.Age, &user.ImageURL,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt,
+		&user.MFAEnabled,
 	)
 	if scanErr != nil {
 		return nil, scanErr
@@ -198,27 +200,28 @@ func (repo *AuthRepository) LoginByEmail(email string) (*model.User, error) {
 	return user, nil
 }
 
-func (repo *AuthRepository) SetRefreshToken(token string, dataByte []byte, refreshTokenDur time.Duration) error {
-	return repo.redis.Set(
-		context.Background(),
-		"refresh_token:"+token, dataByte, refreshTokenDur,
-	).Err()
-}
-
-func (repo *AuthRepository) GetByRefreshToken(token string) (*model.RefreshToken, error) {
-	refreshToken := new(model.RefreshToken)
-
-	cachedData, errGetCache := repo.redis.Get(
-		context.Background(), "refresh_token:"+token,
-	).Bytes()
-	if errGetCache != nil {
-		return nil, errGetCache
-	}
+// GetByID is the user of the id, without its password. sql.ErrNoRows when there is none.
+func (repo *AuthRepository) GetByID(id uint) (*model.User, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
 
-	errJSONUn := json.Unmarshal(cachedData, &refreshToken)
-	if errJSONUn != nil {
-		return nil, errJSONUn
+	sqlQuery := `
+	SELECT id, username, email, role, provider,
+	       full_name, age, image_url, email_verified_at, created_at, updated_at,
+	       EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = users.id AND m.enabled_at IS NOT NULL)
+	FROM users
+	WHERE id = $1
+	`
+	user := new(model.User)
+	scanErr := repo.db.QueryRowContext(ctx, sqlQuery, id).Scan(
+		&user.ID, &user.Username, &user.Email, &user.Role, &user.Provider,
+		&user.FullName, &user.Age, &user.ImageURL,
+		&user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt,
+		&user.MFAEnabled,
+	)
+	if scanErr != nil {
+		return nil, scanErr
 	}
 
-	return refreshToken, nil
+	return user, nil
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"auth-go/model"
+	"time"
+)
+
+type KeyRepositoryI interface {
+	List() ([]*model.SigningKey, error)
+	CreateIfDue(key *model.SigningKey, rotateEvery time.Duration) (bool, error)
+	DeleteRetired(retiredBefore time.Time) (int64, error)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"auth-go/helper/timeout"
+	"auth-go/model"
+	"database/sql"
+	"time"
+)
+
+type KeyRepository struct {
+	db *sql.DB
+}
+
+func NewKeyRepository(db *sql.DB) KeyRepositoryI {
+	repo := new(KeyRepository)
+	repo.db = db
+	return repo
+}
+
+// List is every key, the newest first.
+func (repo *KeyRepository) List() ([]*model.SigningKey, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	SELECT kid, private_key, created_at
+	FROM signing_keys
+	ORDER BY created_at DESC
+	`
+	rows, errQuery := repo.db.QueryContext(ctx, sqlQuery)
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	defer rows.Close()
+
+	keys := []*model.SigningKey{}
+	for rows.Next() {
+		key := new(model.SigningKey)
+		if errScan := rows.Scan(&key.ID, &key.PrivateKey, &key.CreatedAt); errScan != nil {
+			return nil, errScan
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// CreateIfDue inserts key unless a key was created within rotateEvery. Instances of the
+// service rotate in a lock, only one of them adds the key of a rotation.
+func (repo *KeyRepository) CreateIfDue(key *model.SigningKey, rotateEvery time.Duration) (bool, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	tx, errTx := repo.db.BeginTx(ctx, nil)
+	if errTx != nil {
+		return false, errTx
+	}
+	defer tx.Rollback()
+
+	if _, errLock := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('signing_keys'))`); errLock != nil {
+		return false, errLock
+	}
+
+	sqlQuery := `
+	INSERT INTO signing_keys (kid, private_key)
+	SELECT $1, $2
+	WHERE NOT EXISTS (
+		SELECT 1 FROM signing_keys
+		WHERE created_at > now() - $3 * interval '1 second'
+	)
+	`
+	res, errExec := tx.ExecContext(ctx, sqlQuery, key.ID, key.PrivateKey, rotateEvery.Seconds())
+	if errExec != nil {
+		return false, errExec
+	}
+	created, errRows := res.RowsAffected()
+	if errRows != nil {
+		return false, errRows
+	}
+
+	return created > 0, tx.Commit()
+}
+
+// DeleteRetired deletes the keys a newer key took over from before retiredBefore, a key
+// is still needed for as long as the tokens it signed are valid.
+func (repo *KeyRepository) DeleteRetired(retiredBefore time.Time) (int64, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	DELETE FROM signing_keys k
+	WHERE EXISTS (
+		SELECT 1 FROM signing_keys n
+		WHERE n.created_at > k.created_at AND n.created_at < $1
+	)
+	`
+	res, errExec := repo.db.ExecContext(ctx, sqlQuery, retiredBefore)
+	if errExec != nil {
+		return 0, errExec
+	}
+	return res.RowsAffected()
+}
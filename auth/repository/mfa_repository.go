@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"auth-go/helper/timeout"
+	"auth-go/model"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrMFAEnabled is an enrollment of a user whose MFA is enabled already.
+	ErrMFAEnabled = errors.New("mfa already enabled")
+	// ErrMFACodeUsed is a TOTP code of a time step that logged in already, or a recovery
+	// code that is unknown or used.
+	ErrMFACodeUsed = errors.New("mfa code already used")
+)
+
+// GetMFA is the MFA of the user, enabled or pending. sql.ErrNoRows when it never enrolled.
+func (repo *AuthRepository) GetMFA(userID uint) (*model.MFA, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	SELECT user_id, secret, enabled_at, last_used_step
+	FROM user_mfa
+	WHERE user_id = $1
+	`
+	mfa := new(model.MFA)
+	scanErr := repo.db.QueryRowContext(ctx, sqlQuery, userID).Scan(
+		&mfa.UserID, &mfa.Secret, &mfa.EnabledAt, &mfa.LastUsedStep,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return mfa, nil
+}
+
+// SetPendingMFA starts an enrollment with the sealed secret, an enrollment that wasn't
+// confirmed is replaced. ErrMFAEnabled when the user's MFA is enabled.
+func (repo *AuthRepository) SetPendingMFA(userID uint, secret []byte) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	INSERT INTO user_mfa (user_id, secret)
+	VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE
+	SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+	WHERE user_mfa.enabled_at IS NULL
+	`
+	result, errExec := repo.db.ExecContext(ctx, sqlQuery, userID, secret)
+	if errExec != nil {
+		return errExec
+	}
+	affected, errAffected := result.RowsAffected()
+	if errAffected != nil {
+		return errAffected
+	}
+	if affected == 0 {
+		return ErrMFAEnabled
+	}
+	return nil
+}
+
+// EnableMFA enables the pending MFA of the user, the step of the code that confirmed it
+// is taken. The recovery codes of an earlier enrollment are replaced by codeHashes.
+func (repo *AuthRepository) EnableMFA(userID uint, step int64, codeHashes []string) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	tx, errTx := repo.db.BeginTx(ctx, nil)
+	if errTx != nil {
+		return errTx
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after commit
+
+	result, errExec := tx.ExecContext(ctx, `
+	UPDATE user_mfa
+	SET enabled_at = now(), last_used_step = $2
+	WHERE user_id = $1 AND enabled_at IS NULL
+	`, userID, step)
+	if errExec != nil {
+		return errExec
+	}
+	affected, errAffected := result.RowsAffected()
+	if errAffected != nil {
+		return errAffected
+	}
+	if affected == 0 {
+		return ErrMFAEnabled
+	}
+
+	_, errDel := tx.ExecContext(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID)
+	if errDel != nil {
+		return errDel
+	}
+	for _, codeHash := range codeHashes {
+		_, errInsert := tx.ExecContext(ctx, `
+		INSERT INTO user_mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)
+		`, userID, codeHash)
+		if errInsert != nil {
+			return errInsert
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep takes the time step of a TOTP code for the user, a step at or before the last
+// one taken is ErrMFACodeUsed. That keeps a code seen over a shoulder from logging in again.
+func (repo *AuthRepository) UseTOTPStep(userID uint, step int64) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	UPDATE user_mfa
+	SET last_used_step = $2
+	WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2
+	`
+	return repo.execOnce(ctx, sqlQuery, userID, step)
+}
+
+// UseRecoveryCode uses up the recovery code of the hash, ErrMFACodeUsed when the user has
+// no such code left.
+func (repo *AuthRepository) UseRecoveryCode(userID uint, codeHash string) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	UPDATE user_mfa_recovery_codes
+	SET used_at = now()
+	WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	return repo.execOnce(ctx, sqlQuery, userID, codeHash)
+}
+
+func (repo *AuthRepository) execOnce(ctx context.Context, sqlQuery string, args ...any) error {
+	result, errExec := repo.db.ExecContext(ctx, sqlQuery, args...)
+	if errExec != nil {
+		return errExec
+	}
+	affected, errAffected := result.RowsAffected()
+	if errAffected != nil {
+		return errAffected
+	}
+	if affected == 0 {
+		return ErrMFACodeUsed
+	}
+	return nil
+}
+
+// MFAFailures is how many wrong codes were given for the user lately.
+func (repo *AuthRepository) MFAFailures(userID uint) (int64, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	failures, errGet := repo.redis.Get(ctx, mfaFailuresKey(userID)).Int64()
+	if errGet != nil && !errors.Is(errGet, redis.Nil) {
+		return 0, errGet
+	}
+	return failures, nil
+}
+
+// AddMFAFailure counts a wrong code for the user, the count is dropped window after the
+// first one.
+func (repo *AuthRepository) AddMFAFailure(userID uint, window time.Duration) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	key := mfaFailuresKey(userID)
+	failures, errIncr := repo.redis.Incr(ctx, key).Result()
+	if errIncr != nil {
+		return errIncr
+	}
+	if failures == 1 {
+		return repo.redis.Expire(ctx, key, window).Err()
+	}
+	return nil
+}
+
+func mfaFailuresKey(userID uint) string {
+	return "mfa_failures:" + strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"auth-go/helper/timeout"
+	"auth-go/model"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidOAuthState is a callback with a state that no login started, expired or
+// already came back.
+var ErrInvalidOAuthState = errors.New("invalid oauth state")
+
+// SetOAuthState keeps what a login at a provider has to come back with under its state.
+func (repo *AuthRepository) SetOAuthState(state string, oauthState *model.OAuthState, stateDur time.Duration) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	dataByte, errJSON := json.Marshal(oauthState)
+	if errJSON != nil {
+		return errJSON
+	}
+	return repo.redis.Set(ctx, "oauth_state:"+state, dataByte, stateDur).Err()
+}
+
+// TakeOAuthState gets the login of the state and deletes it, a state can be used once.
+func (repo *AuthRepository) TakeOAuthState(state string) (*model.OAuthState, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	data, errGet := repo.redis.GetDel(ctx, "oauth_state:"+state).Bytes()
+	if errors.Is(errGet, redis.Nil) {
+		return nil, ErrInvalidOAuthState
+	}
+	if errGet != nil {
+		return nil, errGet
+	}
+
+	oauthState := new(model.OAuthState)
+	if errJSON := json.Unmarshal(data, oauthState); errJSON != nil {
+		return nil, errJSON
+	}
+	return oauthState, nil
+}
+
+// GetByIdentity is the user a provider account is linked to, sql.ErrNoRows when it isn't.
+func (repo *AuthRepository) GetByIdentity(provider, subject string) (*model.User, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	SELECT u.id, u.username, u.email, u.role, u.provider,
+	       u.full_name, u.age, u.image_url, u.created_at, u.updated_at,
+	       EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL)
+	FROM user_identities i
+	JOIN users u ON u.id = i.user_id
+	WHERE i.provider = $1 AND i.subject = $2
+	`
+	stmt, errStmt := repo.db.PrepareContext(ctx, sqlQuery)
+	if errStmt != nil {
+		return nil, errStmt
+	}
+	defer stmt.Close()
+
+	user := new(model.User)
+	scanErr := stmt.QueryRowContext(ctx, provider, subject).Scan(
+		&user.ID, &user.Username, &user.Email, &user.Role, &user.Provider,
+		&user.FullName, &user.Age, &user.ImageURL,
+		&user.CreatedAt, &user.UpdatedAt,
+		&user.MFAEnabled,
+	)
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return user, nil
+}
+
+// LinkIdentity links a provider account to the user, linking it again changes nothing.
+func (repo *AuthRepository) LinkIdentity(userID uint, identity *model.Identity) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	sqlQuery := `
+	INSERT INTO user_identities (provider, subject, user_id, email)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (provider, subject) DO NOTHING
+	`
+	_, errExec := repo.db.ExecContext(ctx, sqlQuery, identity.Provider, identity.Subject, userID, identity.Email)
+	return errExec
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"auth-go/helper/timeout"
+	"auth-go/model"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A login is a session in Redis, its refresh tokens form one family:
+//
+//	refresh_token:<token>          the live refresh token, a model.RefreshToken
+//	used_refresh_token:<token>     a rotated one, kept as long as it would have lived
+//	session:<id>                   hash of user_id, the live refresh_token and
+//	                               access:<jti> = expiry of every access token issued
+//	user_sessions:<user id>        set of the user's session ids
+//	access_token_denylist:<jti>    a revoked access token until it expires, the gateway
+//	                               turns it away
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token already used")
+)
+
+// rotateScript takes a refresh token out of use and tells whether it was live (1), already
+// used (2) or unknown (0), with its data. Doing both in one step keeps two refreshes racing
+// with the same token from both getting new tokens.
+var rotateScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if data then
+	local ttl = redis.call('PTTL', KEYS[1])
+	redis.call('DEL', KEYS[1])
+	if ttl > 0 then
+		redis.call('SET', KEYS[2], data, 'PX', ttl)
+	end
+	return {1, data}
+end
+data = redis.call('GET', KEYS[2])
+if data then
+	return {2, data}
+end
+return {0}
+`)
+
+// SetRefreshToken stores the live refresh token of its session.
+func (repo *AuthRepository) SetRefreshToken(token string, refreshToken *model.RefreshToken, refreshTokenDur time.Duration) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	dataByte, errJSON := json.Marshal(refreshToken)
+	if errJSON != nil {
+		return errJSON
+	}
+
+	userID := strconv.FormatUint(uint64(refreshToken.UserID), 10)
+	sessionKey := "session:" + refreshToken.SessionID
+	userSessionsKey := "user_sessions:" + userID
+
+	_, errExec := repo.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "refresh_token:"+token, dataByte, refreshTokenDur)
+		pipe.HSet(ctx, sessionKey, "user_id", userID, "refresh_token", token)
+		pipe.Expire(ctx, sessionKey, refreshTokenDur)
+		pipe.SAdd(ctx, userSessionsKey, refreshToken.SessionID)
+		pipe.Expire(ctx, userSessionsKey, refreshTokenDur)
+		return nil
+	})
+	return errExec
+}
+
+// AddAccessToken records an access token of the session, to denylist it when the session
+// is revoked.
+func (repo *AuthRepository) AddAccessToken(sessionID, tokenID string, expiresAt time.Time) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	return repo.redis.HSet(
+		ctx, "session:"+sessionID,
+		"access:"+tokenID, strconv.FormatInt(expiresAt.Unix(), 10),
+	).Err()
+}
+
+func (repo *AuthRepository) GetByRefreshToken(token string) (*model.RefreshToken, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	cachedData, errGetCache := repo.redis.Get(ctx, "refresh_token:"+token).Bytes()
+	if errors.Is(errGetCache, redis.Nil) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if errGetCache != nil {
+		return nil, errGetCache
+	}
+
+	refreshToken := new(model.RefreshToken)
+	errJSONUn := json.Unmarshal(cachedData, &refreshToken)
+	if errJSONUn != nil {
+		return nil, errJSONUn
+	}
+
+	return refreshToken, nil
+}
+
+// RotateRefreshToken uses up token and returns what it stood for. A token that was already
+// used returns ErrRefreshTokenReused along with its data, one of the two holders of it is
+// not its owner.
+func (repo *AuthRepository) RotateRefreshToken(token string) (*model.RefreshToken, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	res, errRun := rotateScript.Run(
+		ctx, repo.redis,
+		[]string{"refresh_token:" + token, "used_refresh_token:" + token},
+	).Slice()
+	if errRun != nil {
+		return nil, errRun
+	}
+
+	status, _ := res[0].(int64)
+	if status == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	data, _ := res[1].(string)
+	refreshToken := new(model.RefreshToken)
+	if errJSONUn := json.Unmarshal([]byte(data), &refreshToken); errJSONUn != nil {
+		return nil, errJSONUn
+	}
+
+	if status == 2 {
+		return refreshToken, ErrRefreshTokenReused
+	}
+
+	// the token outlived its session, which was revoked meanwhile
+	exists, errExists := repo.redis.Exists(ctx, "session:"+refreshToken.SessionID).Result()
+	if errExists != nil {
+		return nil, errExists
+	}
+	if exists == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return refreshToken, nil
+}
+
+// SessionExists tells whether the session is live, not logged out or revoked.
+func (repo *AuthRepository) SessionExists(sessionID string) (bool, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	exists, errExists := repo.redis.Exists(ctx, "session:"+sessionID).Result()
+	if errExists != nil {
+		return false, errExists
+	}
+	return exists == 1, nil
+}
+
+// RevokeSession ends a session, its refresh token stops working and its access tokens
+// are denylisted until they expire. A session that is already gone is not an error.
+func (repo *AuthRepository) RevokeSession(sessionID string) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	session, errGet := repo.redis.HGetAll(ctx, "session:"+sessionID).Result()
+	if errGet != nil {
+		return errGet
+	}
+	if len(session) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	_, errExec := repo.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for field, value := range session {
+			tokenID, isAccess := strings.CutPrefix(field, "access:")
+			if !isAccess {
+				continue
+			}
+			exp, errParse := strconv.ParseInt(value, 10, 64)
+			if errParse != nil {
+				continue
+			}
+			if ttl := time.Unix(exp, 0).Sub(now); ttl > 0 {
+				pipe.Set(ctx, "access_token_denylist:"+tokenID, sessionID, ttl)
+			}
+		}
+		pipe.Del(ctx, "refresh_token:"+session["refresh_token"], "session:"+sessionID)
+		pipe.SRem(ctx, "user_sessions:"+session["user_id"], sessionID)
+		return nil
+	})
+	return errExec
+}
+
+// RevokeUserSessions revokes every session of the user.
+func (repo *AuthRepository) RevokeUserSessions(userID uint) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	userSessionsKey := "user_sessions:" + strconv.FormatUint(uint64(userID), 10)
+	sessionIDs, errMembers := repo.redis.SMembers(ctx, userSessionsKey).Result()
+	if errMembers != nil {
+		return errMembers
+	}
+
+	for _, sessionID := range sessionIDs {
+		if errRevoke := repo.RevokeSession(sessionID); errRevoke != nil {
+			return errRevoke
+		}
+	}
+
+	return repo.redis.Del(ctx, userSessionsKey).Err()
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"auth-go/model"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRepository_RotateRefreshToken(t *testing.T) {
+	refreshToken := &model.RefreshToken{UserID: 7, UserRole: "user", SessionID: "session1"}
+	data, _ := json.Marshal(refreshToken)
+	keys := []string{"refresh_token:token1", "used_refresh_token:token1"}
+
+	tests := []struct {
+		name       string
+		beforeTest func(redismock.ClientMock)
+		want       *model.RefreshToken
+		wantErr    error
+	}{
+		{
+			name: "success",
+			beforeTest: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(rotateScript.Hash(), keys).SetVal([]interface{}{int64(1), string(data)})
+				m.ExpectExists("session:session1").SetVal(1)
+			},
+			want: refreshToken,
+		},
+		{
+			name: "failed - unknown token",
+			beforeTest: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(rotateScript.Hash(), keys).SetVal([]interface{}{int64(0)})
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "failed - session revoked",
+			beforeTest: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(rotateScript.Hash(), keys).SetVal([]interface{}{int64(1), string(data)})
+				m.ExpectExists("session:session1").SetVal(0)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "failed - reused",
+			beforeTest: func(m redismock.ClientMock) {
+				m.ExpectEvalSha(rotateScript.Hash(), keys).SetVal([]interface{}{int64(2), string(data)})
+			},
+			want:    refreshToken,
+			wantErr: ErrRefreshTokenReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisDB, mockRedis := redismock.NewClientMock()
+			repo := &AuthRepository{redis: redisDB}
+			tt.beforeTest(mockRedis)
+
+			got, err := repo.RotateRefreshToken("token1")
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mockRedis.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthRepository_RevokeSession(t *testing.T) {
+	redisDB, mockRedis := redismock.NewClientMock()
+	repo := &AuthRepository{redis: redisDB}
+
+	live := time.Now().Add(time.Hour).Unix()
+	mockRedis.ExpectHGetAll("session:session1").SetVal(map[string]string{
+		"user_id":       "7",
+		"refresh_token": "token2",
+		"access:jti1":   strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10),
+		"access:jti2":   strconv.FormatInt(live, 10),
+	})
+
+	// only the access token that hasn't expired is denylisted, for the time it has left
+	mockRedis.ExpectTxPipeline()
+	mockRedis.CustomMatch(func(expected, actual []interface{}) error {
+		if fmt.Sprint(expected[:3]) != fmt.Sprint(actual[:3]) {
+			return fmt.Errorf("got %v, want %v", actual, expected)
+		}
+		return nil
+	}).ExpectSet("access_token_denylist:jti2", "session1", time.Hour).SetVal("OK")
+	mockRedis.ExpectDel("refresh_token:token2", "session:session1").SetVal(2)
+	mockRedis.ExpectSRem("user_sessions:7", "session1").SetVal(1)
+	mockRedis.ExpectTxPipelineExec()
+
+	require.NoError(t, repo.RevokeSession("session1"))
+	require.NoError(t, mockRedis.ExpectationsWereMet())
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/package/mailer"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+)
+
+const (
+	verifyEmailDur   = 24 * time.Hour
+	resetPasswordDur = 30 * time.Minute
+	mailTimeout      = 10 * time.Second
+)
+
+// ErrAccountNotVerified is a login with the password of an email that isn't verified yet.
+var ErrAccountNotVerified = errors.New("verify your email before logging in, a new link was sent")
+
+// VerifyEmail verifies the email of the token, a token can be used once.
+func (svc *AuthService) VerifyEmail(token string) error {
+	claims, errUse := svc.useActionToken(authjwt.PurposeVerifyEmail, token)
+	if errUse != nil {
+		return errUse
+	}
+	return svc.repo.MarkEmailVerified(claims.Email, false)
+}
+
+// ForgotPassword emails a reset link when a user has the email. It returns at once whether
+// one has or not, so neither the response nor its timing tells who has an account.
+func (svc *AuthService) ForgotPassword(email string) {
+	go func() {
+		errSend := svc.sendPasswordReset(email)
+		if errSend != nil && !errors.Is(errSend, sql.ErrNoRows) {
+			log.Printf("password reset email: %v", errSend)
+		}
+	}()
+}
+
+// ResetPassword sets the password of the token's email and logs the user out everywhere,
+// whoever knew the old password is logged out too. A token is bound to the password it was
+// sent for, once the password changed no earlier link works.
+func (svc *AuthService) ResetPassword(token, password string) error {
+	claims, errParse := svc.actions.Parse(authjwt.PurposeResetPassword, token)
+	if errParse != nil {
+		return errParse
+	}
+
+	user, errUser := svc.repo.LoginByEmail(claims.Email)
+	if errUser != nil {
+		return errUser
+	}
+	if !svc.actions.MatchesState(claims, user.Password) {
+		return fmt.Errorf("%w: the password changed since the link was sent", authjwt.ErrInvalidActionToken)
+	}
+
+	if errUse := svc.repo.UseActionToken(claims.ID, claims.ExpiresAt.Time); errUse != nil {
+		return errUse
+	}
+
+	hashedPassword, errHash := authjwt.HashPassword(password)
+	if errHash != nil {
+		return errHash
+	}
+
+	// another link of the same password may be used at the same time, only one wins
+	userID, errUpdate := svc.repo.UpdatePassword(claims.Email, user.Password, hashedPassword)
+	if errUpdate != nil {
+		return errUpdate
+	}
+	return svc.repo.RevokeUserSessions(userID)
+}
+
+func (svc *AuthService) useActionToken(purpose, token string) (*authjwt.ActionClaims, error) {
+	claims, errParse := svc.actions.Parse(purpose, token)
+	if errParse != nil {
+		return nil, errParse
+	}
+	if errUse := svc.repo.UseActionToken(claims.ID, claims.ExpiresAt.Time); errUse != nil {
+		return nil, errUse
+	}
+	return claims, nil
+}
+
+// sendVerificationLater emails a verification link without holding up the request.
+func (svc *AuthService) sendVerificationLater(email string) {
+	go func() {
+		if errSend := svc.sendVerification(email); errSend != nil {
+			log.Printf("verification email: %v", errSend)
+		}
+	}()
+}
+
+func (svc *AuthService) sendVerification(email string) error {
+	token, errToken := svc.actions.Generate(authjwt.PurposeVerifyEmail, verifyEmailDur, email)
+	if errToken != nil {
+		return errToken
+	}
+
+	return svc.send(&mailer.Message{
+		To:      email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Welcome! Verify your email to start shopping:\n\n%s\n\nThe link works once, for 24 hours.",
+			svc.appURL+"/verify-email?token="+url.QueryEscape(token),
+		),
+	})
+}
+
+func (svc *AuthService) sendPasswordReset(email string) error {
+	user, errUser := svc.repo.LoginByEmail(email)
+	if errUser != nil {
+		return errUser
+	}
+
+	token, errToken := svc.actions.GenerateFor(authjwt.PurposeResetPassword, resetPasswordDur, email, user.Password)
+	if errToken != nil {
+		return errToken
+	}
+
+	return svc.send(&mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Set a new password here:\n\n%s\n\nThe link works once, for 30 minutes. "+
+				"If you didn't ask for it, ignore this email.",
+			svc.appURL+"/reset-password?token="+url.QueryEscape(token),
+		),
+	})
+}
+
+func (svc *AuthService) send(msg *mailer.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+	defer cancel()
+	return svc.mailer.Send(ctx, msg)
+}
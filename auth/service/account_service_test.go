@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/mocks"
+	"auth-go/model"
+	"auth-go/package/mailer"
+	"auth-go/package/oidcprovider"
+	"auth-go/repository"
+	"auth-go/service"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	actions := authjwt.NewActionSigner([]byte("0123456789abcdef0123456789abcdef"))
+	resetToken, err := actions.GenerateFor(authjwt.PurposeResetPassword, time.Hour, "budi@mail.com", "old-hash")
+	require.NoError(t, err)
+	user := &model.User{ID: 7, Email: "budi@mail.com", Password: "old-hash"}
+	verifyToken, err := actions.Generate(authjwt.PurposeVerifyEmail, time.Hour, "budi@mail.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		token      string
+		beforeTest func(*mocks.AuthRepositoryI)
+		wantErr    error
+	}{
+		{
+			name:  "success - every session revoked",
+			token: resetToken,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", "budi@mail.com").Return(user, nil)
+				repo.On("UseActionToken", mock.Anything, mock.Anything).Return(nil)
+				repo.On("UpdatePassword", "budi@mail.com", "old-hash", mock.Anything).Return(uint(7), nil)
+				repo.On("RevokeUserSessions", uint(7)).Return(nil)
+			},
+		},
+		{
+			name:  "failed - token used",
+			token: resetToken,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", "budi@mail.com").Return(user, nil)
+				repo.On("UseActionToken", mock.Anything, mock.Anything).Return(repository.ErrActionTokenUsed)
+			},
+			wantErr: repository.ErrActionTokenUsed,
+		},
+		{
+			name:  "failed - password changed since the link was sent",
+			token: resetToken,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", "budi@mail.com").Return(&model.User{ID: 7, Email: "budi@mail.com", Password: "new-hash"}, nil)
+			},
+			wantErr: authjwt.ErrInvalidActionToken,
+		},
+		{
+			name:  "failed - another link of the password used at the same time",
+			token: resetToken,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", "budi@mail.com").Return(user, nil)
+				repo.On("UseActionToken", mock.Anything, mock.Anything).Return(nil)
+				repo.On("UpdatePassword", "budi@mail.com", "old-hash", mock.Anything).Return(uint(0), sql.ErrNoRows)
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name:       "failed - token of email verification",
+			token:      verifyToken,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {},
+			wantErr:    authjwt.ErrInvalidActionToken,
+		},
+		{
+			name:       "failed - tampered token",
+			token:      resetToken + "x",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {},
+			wantErr:    authjwt.ErrInvalidActionToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAuthRepositoryI(t)
+			tt.beforeTest(repo)
+			svc := service.NewAuthService(repo, nil, oidcprovider.NewRegistry(), actions, nil, "", nil, nil)
+
+			err := svc.ResetPassword(tt.token, "new-password")
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthService_ForgotPassword(t *testing.T) {
+	actions := authjwt.NewActionSigner([]byte("0123456789abcdef0123456789abcdef"))
+	repo := mocks.NewAuthRepositoryI(t)
+	mail := mocks.NewMailer(t)
+	svc := service.NewAuthService(repo, nil, oidcprovider.NewRegistry(), actions, mail, "http://localhost:3000", nil, nil)
+
+	sent := make(chan *mailer.Message, 1)
+	repo.On("LoginByEmail", "budi@mail.com").Return(&model.User{ID: 7, Email: "budi@mail.com", Password: "old-hash"}, nil)
+	mail.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(*mailer.Message)
+	}).Return(nil)
+
+	svc.ForgotPassword("budi@mail.com")
+
+	var msg *mailer.Message
+	select {
+	case msg = <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reset email sent")
+	}
+	require.Equal(t, "budi@mail.com", msg.To)
+
+	// the link carries a reset token of the email
+	i := strings.Index(msg.Body, "http://localhost:3000/reset-password?")
+	require.GreaterOrEqual(t, i, 0)
+	link, err := url.Parse(strings.Fields(msg.Body[i:])[0])
+	require.NoError(t, err)
+	claims, err := actions.Parse(authjwt.PurposeResetPassword, link.Query().Get("token"))
+	require.NoError(t, err)
+	require.Equal(t, "budi@mail.com", claims.Email)
+	require.True(t, actions.MatchesState(claims, "old-hash"))
+	require.False(t, actions.MatchesState(claims, "new-hash"))
+}
@@ -2,14 +2,28 @@ package service
 
 import (
 	"auth-go/model"
-	"time"
+	"context"
 )
 
 type AuthServiceI interface {
 	Create(registerReq *model.RegisterReq) error
-	FirstOrCreate(provider string, userReq *model.UserReq) (*model.User, error)
 	LoginByEmail(loginReq *model.LoginReq) (*model.User, error)
 
-	SetRefreshToken(refreshToken string, dataByte []byte, refreshTokenDur time.Duration) error
-	GetByRefreshToken(token string) (*model.RefreshToken, error)
+	VerifyEmail(token string) error
+	ForgotPassword(email string)
+	ResetPassword(token, password string) error
+
+	OAuthLogin(providerName string) (authURL, state string, err error)
+	OAuthCallback(ctx context.Context, providerName, state, code string) (*model.User, error)
+	FirstOrCreate(identity *model.Identity) (*model.User, error)
+
+	Login(user *model.User) (*model.LoginResult, error)
+	VerifyMFA(mfaToken, code string) (*model.Tokens, error)
+	EnrollMFA(accessToken, mfaToken string) (*model.MFAEnrollment, error)
+	ConfirmMFA(accessToken, mfaToken, code string) (*model.MFAConfirmation, error)
+
+	NewSession(user *model.User) (*model.Tokens, error)
+	Refresh(refreshToken string) (*model.Tokens, error)
+	Logout(accessToken, refreshToken string) error
+	LogoutAll(accessToken string) error
 }
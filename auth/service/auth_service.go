@@ -2,19 +2,56 @@ package service
 
 import (
 	"auth-go/helper/authjwt"
+	"auth-go/helper/mfa"
 	"auth-go/model"
+	"auth-go/package/mailer"
+	"auth-go/package/oidcprovider"
 	"auth-go/repository"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 )
 
+const (
+	accessTokenDur  = 24 * time.Hour
+	refreshTokenDur = 7 * 24 * time.Hour
+)
+
+// ErrNoSession is a logout without a token of a live session.
+var ErrNoSession = errors.New("no session to log out of")
+
 type AuthService struct {
-	repo repository.AuthRepositoryI
+	repo      repository.AuthRepositoryI
+	keys      *authjwt.KeySet
+	providers *oidcprovider.Registry
+	actions   *authjwt.ActionSigner
+	mailer    mailer.Mailer
+	// appURL is the storefront the links of the emails open.
+	appURL string
+
+	// kek seals the TOTP secrets.
+	kek      []byte
+	recovery *mfa.RecoveryHasher
+	// mfaRoles are the roles that can't log in without MFA.
+	mfaRoles []string
 }
 
-func NewAuthService(repo repository.AuthRepositoryI) AuthServiceI {
+func NewAuthService(
+	repo repository.AuthRepositoryI, keys *authjwt.KeySet, providers *oidcprovider.Registry,
+	actions *authjwt.ActionSigner, mailer mailer.Mailer, appURL string,
+	kek []byte, mfaRoles []string,
+) AuthServiceI {
 	svc := new(AuthService)
 	svc.repo = repo
+	svc.keys = keys
+	svc.providers = providers
+	svc.actions = actions
+	svc.mailer = mailer
+	svc.appURL = appURL
+	svc.kek = kek
+	svc.recovery = mfa.NewRecoveryHasher(kek)
+	svc.mfaRoles = mfaRoles
 	return svc
 }
 
@@ -31,19 +68,13 @@ func (svc *AuthService) Create(registerReq *model.RegisterReq) error {
 		Role:     "user",
 		Provider: "email",
 	}
-	return svc.repo.Create(newUser)
-}
-
-func (svc *AuthService) FirstOrCreate(provider string, userReq *model.UserReq) (*model.User, error) {
-	newUser := &model.User{
-		Username: userReq.Username,
-		Email:    userReq.Email,
-		Role:     "user",
-		Provider: provider,
-		FullName: userReq.FullName,
-		ImageURL: userReq.ImageURL,
+	if errCreate := svc.repo.Create(newUser); errCreate != nil {
+		return errCreate
 	}
-	return svc.repo.FirstOrCreate(newUser)
+
+	// a link that doesn't go out is sent again on login
+	svc.sendVerificationLater(newUser.Email)
+	return nil
 }
 
 func (svc *AuthService) LoginByEmail(loginReq *model.LoginReq) (*model.User, error) {
@@ -56,15 +87,125 @@ func (svc *AuthService) LoginByEmail(loginReq *model.LoginReq) (*model.User, err
 		return nil, errors.New("incorrect password")
 	}
 
+	if user.EmailVerifiedAt == nil {
+		svc.sendVerificationLater(user.Email)
+		return nil, ErrAccountNotVerified
+	}
+
 	user.Password = ""
 
 	return user, nil
 }
 
-func (svc *AuthService) SetRefreshToken(refreshToken string, dataByte []byte, refreshTokenDur time.Duration) error {
-	return svc.repo.SetRefreshToken(refreshToken, dataByte, refreshTokenDur)
+// NewSession logs user in, a new session with its first tokens.
+func (svc *AuthService) NewSession(user *model.User) (*model.Tokens, error) {
+	sessionID, errID := authjwt.NewID()
+	if errID != nil {
+		return nil, errID
+	}
+	return svc.issueTokens(user.ID, user.Role, sessionID)
+}
+
+// Refresh trades a refresh token for new tokens of its session, the old one can't be used
+// again. Using it again revokes the session, whoever comes second is cut off and so is
+// the first, since the two can't be told apart. A session of a role that needs MFA, of a
+// user without one, is revoked too; it predates the requirement or lost its MFA since.
+func (svc *AuthService) Refresh(token string) (*model.Tokens, error) {
+	refreshToken, errRotate := svc.repo.RotateRefreshToken(token)
+	if errors.Is(errRotate, repository.ErrRefreshTokenReused) {
+		if errRevoke := svc.repo.RevokeSession(refreshToken.SessionID); errRevoke != nil {
+			return nil, errRevoke
+		}
+		return nil, errRotate
+	}
+	if errRotate != nil {
+		return nil, errRotate
+	}
+
+	if svc.requiresMFA(refreshToken.UserRole) {
+		enabled, errMFA := svc.mfaEnabled(refreshToken.UserID)
+		if errMFA != nil {
+			return nil, errMFA
+		}
+		if !enabled {
+			if errRevoke := svc.repo.RevokeSession(refreshToken.SessionID); errRevoke != nil {
+				return nil, errRevoke
+			}
+			return nil, ErrMFARequired
+		}
+	}
+
+	return svc.issueTokens(refreshToken.UserID, refreshToken.UserRole, refreshToken.SessionID)
 }
 
-func (svc *AuthService) GetByRefreshToken(token string) (*model.RefreshToken, error) {
-	return svc.repo.GetByRefreshToken(token)
+// Logout revokes the session of the access token, or of the refresh token when there is
+// no valid access token.
+func (svc *AuthService) Logout(accessToken, refreshToken string) error {
+	if accessToken != "" {
+		claims, errParse := authjwt.ParseToken(svc.keys, accessToken)
+		if errParse == nil && claims.SessionID != "" {
+			return svc.repo.RevokeSession(claims.SessionID)
+		}
+	}
+
+	if refreshToken != "" {
+		session, errGet := svc.repo.GetByRefreshToken(refreshToken)
+		if errGet == nil {
+			return svc.repo.RevokeSession(session.SessionID)
+		}
+		if !errors.Is(errGet, repository.ErrInvalidRefreshToken) {
+			return errGet
+		}
+	}
+
+	return ErrNoSession
+}
+
+// LogoutAll revokes every session of the user of the access token.
+func (svc *AuthService) LogoutAll(accessToken string) error {
+	claims, errParse := authjwt.ParseToken(svc.keys, accessToken)
+	if errParse != nil {
+		return fmt.Errorf("%w: %s", ErrNoSession, errParse)
+	}
+
+	userID, errID := strconv.ParseUint(claims.UserID, 10, 64)
+	if errID != nil {
+		return fmt.Errorf("%w: %s", ErrNoSession, errID)
+	}
+
+	return svc.repo.RevokeUserSessions(uint(userID))
+}
+
+func (svc *AuthService) issueTokens(userID uint, userRole, sessionID string) (*model.Tokens, error) {
+	accessToken, accessClaims, errAccess := authjwt.GenerateToken(
+		svc.keys, accessTokenDur,
+		userID, userRole, sessionID,
+	)
+	if errAccess != nil {
+		return nil, errAccess
+	}
+
+	refreshToken, errRefresh := authjwt.NewRefreshToken()
+	if errRefresh != nil {
+		return nil, errRefresh
+	}
+
+	errSet := svc.repo.SetRefreshToken(refreshToken, &model.RefreshToken{
+		UserID:    userID,
+		UserRole:  userRole,
+		SessionID: sessionID,
+	}, refreshTokenDur)
+	if errSet != nil {
+		return nil, errSet
+	}
+
+	errAdd := svc.repo.AddAccessToken(sessionID, accessClaims.ID, accessClaims.ExpiresAt.Time)
+	if errAdd != nil {
+		return nil, errAdd
+	}
+
+	return &model.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
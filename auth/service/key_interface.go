@@ -0,0 +1,12 @@
+package service
+
+import (
+	"auth-go/helper/authjwt"
+	"context"
+)
+
+type KeyServiceI interface {
+	Rotate() error
+	Run(ctx context.Context)
+	JWKS() *authjwt.JWKS
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/model"
+	"auth-go/repository"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// keyRefresh is how often the keys are reloaded and rotated when due.
+	keyRefresh = time.Minute
+	// KeyPublishDelay is how long a new key is in the JWKS before it signs, it has to be
+	// longer than the JWKS_CACHE_TTL of the gateway.
+	KeyPublishDelay = 10 * time.Minute
+)
+
+type KeyService struct {
+	repo        repository.KeyRepositoryI
+	keys        *authjwt.KeySet
+	kek         []byte
+	rotateEvery time.Duration
+}
+
+func NewKeyService(repo repository.KeyRepositoryI, keys *authjwt.KeySet, kek []byte, rotateEvery time.Duration) KeyServiceI {
+	svc := new(KeyService)
+	svc.repo = repo
+	svc.keys = keys
+	svc.kek = kek
+	svc.rotateEvery = rotateEvery
+	return svc
+}
+
+// Rotate adds a key when the newest is older than rotateEvery, deletes the keys no valid
+// token is signed with anymore and loads the keys there are into the key set.
+func (svc *KeyService) Rotate() error {
+	newest := svc.keys.Newest()
+	if newest == nil || time.Since(newest.CreatedAt) >= svc.rotateEvery {
+		key, errKey := authjwt.NewSigningKey()
+		if errKey != nil {
+			return errKey
+		}
+		sealed, errSeal := authjwt.SealKey(svc.kek, key)
+		if errSeal != nil {
+			return errSeal
+		}
+		if _, errCreate := svc.repo.CreateIfDue(&model.SigningKey{ID: key.ID, PrivateKey: sealed}, svc.rotateEvery); errCreate != nil {
+			return errCreate
+		}
+	}
+
+	// the successor of a key signs from KeyPublishDelay after it's created, the other
+	// instances of the service see it up to keyRefresh later
+	retiredBefore := time.Now().Add(-accessTokenDur - KeyPublishDelay - keyRefresh)
+	if _, errDelete := svc.repo.DeleteRetired(retiredBefore); errDelete != nil {
+		return errDelete
+	}
+
+	rows, errList := svc.repo.List()
+	if errList != nil {
+		return errList
+	}
+	keys := make([]*authjwt.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		key, errOpen := authjwt.OpenKey(svc.kek, row.ID, row.CreatedAt, row.PrivateKey)
+		if errOpen != nil {
+			return errOpen
+		}
+		keys = append(keys, key)
+	}
+	svc.keys.Set(keys)
+	return nil
+}
+
+// Run rotates every keyRefresh until ctx is done, a failed rotation keeps the keys loaded.
+func (svc *KeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if errRotate := svc.Rotate(); errRotate != nil {
+			log.Printf("signing key rotation: %v", errRotate)
+		}
+	}
+}
+
+func (svc *KeyService) JWKS() *authjwt.JWKS {
+	return svc.keys.JWKS()
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/helper/mfa"
+	"auth-go/model"
+	"auth-go/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// mfaChallengeDur is how long a login can wait for its TOTP code.
+	mfaChallengeDur = 5 * time.Minute
+	// a user gets maxMFAFailures wrong codes per mfaFailureWindow, a 6 digit code can't be
+	// guessed by trying a few logins more.
+	maxMFAFailures   = 5
+	mfaFailureWindow = 15 * time.Minute
+
+	mfaIssuer         = "ecommerce"
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrInvalidMFACode = errors.New("invalid mfa code")
+	// ErrTooManyMFAFailures is a code given after too many wrong ones, it isn't checked.
+	ErrTooManyMFAFailures = errors.New("too many wrong mfa codes, try again later")
+	// ErrMFANotEnrolled is a code for a user without an MFA, or without a pending one to
+	// confirm.
+	ErrMFANotEnrolled = errors.New("mfa not enrolled")
+	// ErrMFARequired is a refresh of a session of a user whose role needs MFA but has none,
+	// the session is revoked and the next login enrolls it.
+	ErrMFARequired = errors.New("mfa required, log in again")
+)
+
+// Login logs in a user that gave its password or came back from a provider. A user with
+// MFA, or whose role needs it, gets an MFA challenge instead of tokens.
+func (svc *AuthService) Login(user *model.User) (*model.LoginResult, error) {
+	if !user.MFAEnabled && !svc.requiresMFA(user.Role) {
+		tokens, errSession := svc.NewSession(user)
+		if errSession != nil {
+			return nil, errSession
+		}
+		return &model.LoginResult{Tokens: tokens}, nil
+	}
+
+	mfaToken, errToken := svc.actions.Generate(authjwt.PurposeMFAChallenge, mfaChallengeDur, user.Email)
+	if errToken != nil {
+		return nil, errToken
+	}
+	return &model.LoginResult{
+		MFAToken:           mfaToken,
+		EnrollmentRequired: !user.MFAEnabled,
+	}, nil
+}
+
+// VerifyMFA finishes the login of the MFA token with a TOTP code or a recovery code, a
+// challenge logs in once.
+func (svc *AuthService) VerifyMFA(mfaToken, code string) (*model.Tokens, error) {
+	claims, errParse := svc.actions.Parse(authjwt.PurposeMFAChallenge, mfaToken)
+	if errParse != nil {
+		return nil, errParse
+	}
+	user, errUser := svc.repo.LoginByEmail(claims.Email)
+	if errUser != nil {
+		return nil, errUser
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnrolled
+	}
+
+	secret, errSecret := svc.mfaSecret(user.ID)
+	if errSecret != nil {
+		return nil, errSecret
+	}
+	if errCode := svc.checkCode(user.ID, secret, code); errCode != nil {
+		return nil, errCode
+	}
+
+	if errUse := svc.repo.UseActionToken(claims.ID, claims.ExpiresAt.Time); errUse != nil {
+		return nil, errUse
+	}
+	return svc.NewSession(user)
+}
+
+// EnrollMFA starts an enrollment of the user of the MFA token, or of the access token, with
+// a new secret. Enrolling again before confirming starts over.
+func (svc *AuthService) EnrollMFA(accessToken, mfaToken string) (*model.MFAEnrollment, error) {
+	user, errUser := svc.mfaUser(accessToken, mfaToken)
+	if errUser != nil {
+		return nil, errUser
+	}
+	if user.MFAEnabled {
+		return nil, repository.ErrMFAEnabled
+	}
+
+	secret, errSecret := mfa.GenerateSecret()
+	if errSecret != nil {
+		return nil, errSecret
+	}
+	sealed, errSeal := authjwt.Seal(svc.kek, mfaAAD(user.ID), secret)
+	if errSeal != nil {
+		return nil, errSeal
+	}
+	if errSet := svc.repo.SetPendingMFA(user.ID, sealed); errSet != nil {
+		return nil, errSet
+	}
+
+	return &model.MFAEnrollment{
+		Secret:          mfa.EncodeSecret(secret),
+		ProvisioningURI: mfa.ProvisioningURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables the pending MFA of the user with a code of its secret and returns the
+// recovery codes. Confirmed with an MFA token, the login of the token goes on too.
+func (svc *AuthService) ConfirmMFA(accessToken, mfaToken, code string) (*model.MFAConfirmation, error) {
+	user, errUser := svc.mfaUser(accessToken, mfaToken)
+	if errUser != nil {
+		return nil, errUser
+	}
+	if errFailures := svc.checkFailures(user.ID); errFailures != nil {
+		return nil, errFailures
+	}
+
+	userMFA, errMFA := svc.repo.GetMFA(user.ID)
+	if errors.Is(errMFA, sql.ErrNoRows) {
+		return nil, ErrMFANotEnrolled
+	}
+	if errMFA != nil {
+		return nil, errMFA
+	}
+	if userMFA.EnabledAt != nil {
+		return nil, repository.ErrMFAEnabled
+	}
+
+	secret, errOpen := authjwt.Open(svc.kek, mfaAAD(user.ID), userMFA.Secret)
+	if errOpen != nil {
+		return nil, errOpen
+	}
+	step, valid := mfa.Validate(secret, code, time.Now())
+	if !valid {
+		return nil, svc.addFailure(user.ID)
+	}
+
+	recoveryCodes, errCodes := mfa.NewRecoveryCodes(recoveryCodeCount)
+	if errCodes != nil {
+		return nil, errCodes
+	}
+	codeHashes := make([]string, len(recoveryCodes))
+	for i, recoveryCode := range recoveryCodes {
+		codeHashes[i] = svc.recovery.Hash(recoveryCode)
+	}
+	if errEnable := svc.repo.EnableMFA(user.ID, step, codeHashes); errEnable != nil {
+		return nil, errEnable
+	}
+
+	confirmation := &model.MFAConfirmation{RecoveryCodes: recoveryCodes}
+	if mfaToken != "" {
+		claims, errParse := svc.actions.Parse(authjwt.PurposeMFAChallenge, mfaToken)
+		if errParse != nil {
+			return nil, errParse
+		}
+		if errUse := svc.repo.UseActionToken(claims.ID, claims.ExpiresAt.Time); errUse != nil {
+			return nil, errUse
+		}
+		tokens, errSession := svc.NewSession(user)
+		if errSession != nil {
+			return nil, errSession
+		}
+		confirmation.Tokens = tokens
+	}
+	return confirmation, nil
+}
+
+func (svc *AuthService) requiresMFA(role string) bool {
+	for _, mfaRole := range svc.mfaRoles {
+		if mfaRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaEnabled tells whether the user confirmed an MFA.
+func (svc *AuthService) mfaEnabled(userID uint) (bool, error) {
+	userMFA, errMFA := svc.repo.GetMFA(userID)
+	if errors.Is(errMFA, sql.ErrNoRows) {
+		return false, nil
+	}
+	if errMFA != nil {
+		return false, errMFA
+	}
+	return userMFA.EnabledAt != nil, nil
+}
+
+// mfaUser is the user of the MFA token of a login, or else of the access token of a live
+// session.
+func (svc *AuthService) mfaUser(accessToken, mfaToken string) (*model.User, error) {
+	if mfaToken != "" {
+		claims, errParse := svc.actions.Parse(authjwt.PurposeMFAChallenge, mfaToken)
+		if errParse != nil {
+			return nil, errParse
+		}
+		return svc.repo.LoginByEmail(claims.Email)
+	}
+
+	claims, errParse := authjwt.ParseToken(svc.keys, accessToken)
+	if errParse != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSession, errParse)
+	}
+	live, errExists := svc.repo.SessionExists(claims.SessionID)
+	if errExists != nil {
+		return nil, errExists
+	}
+	if !live {
+		return nil, ErrNoSession
+	}
+
+	userID, errID := strconv.ParseUint(claims.UserID, 10, 64)
+	if errID != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSession, errID)
+	}
+	return svc.repo.GetByID(uint(userID))
+}
+
+// mfaSecret is the opened TOTP secret of the user's MFA.
+func (svc *AuthService) mfaSecret(userID uint) ([]byte, error) {
+	userMFA, errMFA := svc.repo.GetMFA(userID)
+	if errors.Is(errMFA, sql.ErrNoRows) {
+		return nil, ErrMFANotEnrolled
+	}
+	if errMFA != nil {
+		return nil, errMFA
+	}
+
+	return authjwt.Open(svc.kek, mfaAAD(userID), userMFA.Secret)
+}
+
+// checkCode takes a TOTP code of the secret, or a recovery code when it isn't one. A TOTP
+// code of a step taken already and a recovery code used already are wrong codes too.
+func (svc *AuthService) checkCode(userID uint, secret []byte, code string) error {
+	if errFailures := svc.checkFailures(userID); errFailures != nil {
+		return errFailures
+	}
+
+	var errUse error
+	if step, valid := mfa.Validate(secret, code, time.Now()); valid {
+		errUse = svc.repo.UseTOTPStep(userID, step)
+	} else {
+		errUse = svc.repo.UseRecoveryCode(userID, svc.recovery.Hash(code))
+	}
+	if errors.Is(errUse, repository.ErrMFACodeUsed) {
+		return svc.addFailure(userID)
+	}
+	return errUse
+}
+
+func (svc *AuthService) checkFailures(userID uint) error {
+	failures, errFailures := svc.repo.MFAFailures(userID)
+	if errFailures != nil {
+		return errFailures
+	}
+	if failures >= maxMFAFailures {
+		return ErrTooManyMFAFailures
+	}
+	return nil
+}
+
+// addFailure counts a wrong code and returns ErrInvalidMFACode.
+func (svc *AuthService) addFailure(userID uint) error {
+	if errAdd := svc.repo.AddMFAFailure(userID, mfaFailureWindow); errAdd != nil {
+		return errAdd
+	}
+	return ErrInvalidMFACode
+}
+
+// mfaAAD binds a sealed TOTP secret to its user, it can't be copied to another one.
+func mfaAAD(userID uint) []byte {
+	return []byte("user_mfa:" + strconv.FormatUint(uint64(userID), 10))
+}
@@ -0,0 +1,331 @@
+package service_test
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/helper/mfa"
+	"auth-go/mocks"
+	"auth-go/model"
+	"auth-go/package/oidcprovider"
+	"auth-go/repository"
+	"auth-go/service"
+	"database/sql"
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var mfaKEK = []byte("0123456789abcdef0123456789abcdef")
+
+func newMFAService(t *testing.T, repo *mocks.AuthRepositoryI) service.AuthServiceI {
+	key, err := authjwt.NewSigningKey()
+	require.NoError(t, err)
+	keys := authjwt.NewKeySet(0)
+	keys.Set([]*authjwt.SigningKey{key})
+
+	return service.NewAuthService(
+		repo, keys, oidcprovider.NewRegistry(),
+		authjwt.NewActionSigner(mfaKEK), nil, "",
+		mfaKEK, []string{"admin", "seller"},
+	)
+}
+
+// enrollMFA enrolls the user of the MFA token and returns the secret as sealed for the
+// database and as the app takes it.
+func enrollMFA(t *testing.T, user *model.User, mfaToken string) (sealed, secret []byte) {
+	repo := mocks.NewAuthRepositoryI(t)
+	repo.On("LoginByEmail", user.Email).Return(user, nil)
+	repo.On("SetPendingMFA", user.ID, mock.Anything).Run(func(args mock.Arguments) {
+		sealed = args.Get(1).([]byte)
+	}).Return(nil)
+
+	enrollment, err := newMFAService(t, repo).EnrollMFA("", mfaToken)
+	require.NoError(t, err)
+	secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+	return sealed, secret
+}
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name               string
+		user               *model.User
+		beforeTest         func(*mocks.AuthRepositoryI)
+		wantMFA            bool
+		wantEnrollRequired bool
+	}{
+		{
+			name: "user without mfa - tokens",
+			user: &model.User{ID: 7, Email: "budi@mail.com", Role: "user"},
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("SetRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repo.On("AddAccessToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:       "user with mfa - challenge",
+			user:       &model.User{ID: 7, Email: "budi@mail.com", Role: "user", MFAEnabled: true},
+			beforeTest: func(repo *mocks.AuthRepositoryI) {},
+			wantMFA:    true,
+		},
+		{
+			name:               "seller without mfa - enrollment",
+			user:               &model.User{ID: 7, Email: "budi@mail.com", Role: "seller"},
+			beforeTest:         func(repo *mocks.AuthRepositoryI) {},
+			wantMFA:            true,
+			wantEnrollRequired: true,
+		},
+		{
+			name:       "admin with mfa - challenge",
+			user:       &model.User{ID: 7, Email: "budi@mail.com", Role: "admin", MFAEnabled: true},
+			beforeTest: func(repo *mocks.AuthRepositoryI) {},
+			wantMFA:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAuthRepositoryI(t)
+			tt.beforeTest(repo)
+
+			result, err := newMFAService(t, repo).Login(tt.user)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEnrollRequired, result.EnrollmentRequired)
+			if !tt.wantMFA {
+				require.NotNil(t, result.Tokens)
+				require.Empty(t, result.MFAToken)
+				return
+			}
+
+			require.Nil(t, result.Tokens)
+			claims, err := authjwt.NewActionSigner(mfaKEK).Parse(authjwt.PurposeMFAChallenge, result.MFAToken)
+			require.NoError(t, err)
+			require.Equal(t, tt.user.Email, claims.Email)
+		})
+	}
+}
+
+func TestAuthService_VerifyMFA(t *testing.T) {
+	user := &model.User{ID: 7, Email: "budi@mail.com", Role: "seller", MFAEnabled: true}
+	actions := authjwt.NewActionSigner(mfaKEK)
+	mfaToken, err := actions.Generate(authjwt.PurposeMFAChallenge, time.Minute, user.Email)
+	require.NoError(t, err)
+	resetToken, err := actions.Generate(authjwt.PurposeResetPassword, time.Minute, user.Email)
+	require.NoError(t, err)
+
+	sealed, secret := enrollMFA(t, &model.User{ID: 7, Email: "budi@mail.com", Role: "seller"}, mfaToken)
+	userMFA := &model.MFA{UserID: 7, Secret: sealed, EnabledAt: &time.Time{}}
+	code := mfa.Code(secret, time.Now().Unix()/30)
+	recoveryCode := "abcde-fghjk"
+
+	loggedIn := func(repo *mocks.AuthRepositoryI) {
+		repo.On("UseActionToken", mock.Anything, mock.Anything).Return(nil)
+		repo.On("SetRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		repo.On("AddAccessToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		code       string
+		beforeTest func(*mocks.AuthRepositoryI)
+		wantErr    error
+	}{
+		{
+			name:  "success - totp code",
+			token: mfaToken,
+			code:  code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+				repo.On("UseTOTPStep", uint(7), mock.Anything).Return(nil)
+				loggedIn(repo)
+			},
+		},
+		{
+			name:  "success - recovery code",
+			token: mfaToken,
+			code:  "ABCDE FGHJK",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+				repo.On("UseRecoveryCode", uint(7), mfa.NewRecoveryHasher(mfaKEK).Hash(recoveryCode)).Return(nil)
+				loggedIn(repo)
+			},
+		},
+		{
+			name:  "failed - totp code used",
+			token: mfaToken,
+			code:  code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+				repo.On("UseTOTPStep", uint(7), mock.Anything).Return(repository.ErrMFACodeUsed)
+				repo.On("AddMFAFailure", uint(7), mock.Anything).Return(nil)
+			},
+			wantErr: service.ErrInvalidMFACode,
+		},
+		{
+			name:  "failed - wrong code",
+			token: mfaToken,
+			code:  "000000",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+				repo.On("UseRecoveryCode", uint(7), mock.Anything).Return(repository.ErrMFACodeUsed)
+				repo.On("AddMFAFailure", uint(7), mock.Anything).Return(nil)
+			},
+			wantErr: service.ErrInvalidMFACode,
+		},
+		{
+			name:  "failed - too many wrong codes",
+			token: mfaToken,
+			code:  code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(5), nil)
+			},
+			wantErr: service.ErrTooManyMFAFailures,
+		},
+		{
+			name:  "failed - challenge used",
+			token: mfaToken,
+			code:  code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(user, nil)
+				repo.On("GetMFA", uint(7)).Return(userMFA, nil)
+				repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+				repo.On("UseTOTPStep", uint(7), mock.Anything).Return(nil)
+				repo.On("UseActionToken", mock.Anything, mock.Anything).Return(repository.ErrActionTokenUsed)
+			},
+			wantErr: repository.ErrActionTokenUsed,
+		},
+		{
+			name:  "failed - not enrolled",
+			token: mfaToken,
+			code:  code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("LoginByEmail", user.Email).Return(&model.User{ID: 7, Email: user.Email, Role: "seller"}, nil)
+			},
+			wantErr: service.ErrMFANotEnrolled,
+		},
+		{
+			name:       "failed - token of a password reset",
+			token:      resetToken,
+			code:       code,
+			beforeTest: func(repo *mocks.AuthRepositoryI) {},
+			wantErr:    authjwt.ErrInvalidActionToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAuthRepositoryI(t)
+			tt.beforeTest(repo)
+
+			tokens, err := newMFAService(t, repo).VerifyMFA(tt.token, tt.code)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.NotEmpty(t, tokens.AccessToken)
+			}
+		})
+	}
+}
+
+func TestAuthService_ConfirmMFA(t *testing.T) {
+	user := &model.User{ID: 7, Email: "budi@mail.com", Role: "seller"}
+	mfaToken, err := authjwt.NewActionSigner(mfaKEK).Generate(authjwt.PurposeMFAChallenge, time.Minute, user.Email)
+	require.NoError(t, err)
+	sealed, secret := enrollMFA(t, user, mfaToken)
+	step := time.Now().Unix() / 30
+
+	repo := mocks.NewAuthRepositoryI(t)
+	repo.On("LoginByEmail", user.Email).Return(user, nil)
+	repo.On("MFAFailures", uint(7)).Return(int64(0), nil)
+	repo.On("GetMFA", uint(7)).Return(&model.MFA{UserID: 7, Secret: sealed}, nil)
+	var codeHashes []string
+	repo.On("EnableMFA", uint(7), step, mock.Anything).Run(func(args mock.Arguments) {
+		codeHashes = args.Get(2).([]string)
+	}).Return(nil)
+	repo.On("UseActionToken", mock.Anything, mock.Anything).Return(nil)
+	repo.On("SetRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repo.On("AddAccessToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	confirmation, err := newMFAService(t, repo).ConfirmMFA("", mfaToken, mfa.Code(secret, step))
+	require.NoError(t, err)
+	require.NotNil(t, confirmation.Tokens)
+
+	// only the hashes of the recovery codes are stored
+	require.Len(t, confirmation.RecoveryCodes, 10)
+	hasher := mfa.NewRecoveryHasher(mfaKEK)
+	for i, code := range confirmation.RecoveryCodes {
+		require.Equal(t, hasher.Hash(code), codeHashes[i])
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	enabledAt := time.Now()
+
+	tests := []struct {
+		name       string
+		role       string
+		beforeTest func(*mocks.AuthRepositoryI)
+		wantErr    error
+	}{
+		{
+			name: "success - role without mfa",
+			role: "user",
+		},
+		{
+			name: "success - seller with mfa",
+			role: "seller",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetMFA", uint(7)).Return(&model.MFA{UserID: 7, EnabledAt: &enabledAt}, nil)
+			},
+		},
+		{
+			name: "failed - seller without mfa, session revoked",
+			role: "seller",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetMFA", uint(7)).Return(nil, sql.ErrNoRows)
+				repo.On("RevokeSession", "session1").Return(nil)
+			},
+			wantErr: service.ErrMFARequired,
+		},
+		{
+			name: "failed - admin with an unconfirmed mfa, session revoked",
+			role: "admin",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetMFA", uint(7)).Return(&model.MFA{UserID: 7}, nil)
+				repo.On("RevokeSession", "session1").Return(nil)
+			},
+			wantErr: service.ErrMFARequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAuthRepositoryI(t)
+			repo.On("RotateRefreshToken", "refresh1").
+				Return(&model.RefreshToken{UserID: 7, UserRole: tt.role, SessionID: "session1"}, nil)
+			if tt.beforeTest != nil {
+				tt.beforeTest(repo)
+			}
+			if tt.wantErr == nil {
+				repo.On("SetRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repo.On("AddAccessToken", "session1", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			tokens, err := newMFAService(t, repo).Refresh("refresh1")
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.NotEmpty(t, tokens.AccessToken)
+				require.NotEmpty(t, tokens.RefreshToken)
+			}
+		})
+	}
+}
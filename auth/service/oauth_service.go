@@ -0,0 +1,125 @@
+package service
+
+import (
+	"auth-go/helper/authjwt"
+	"auth-go/model"
+	"auth-go/package/oidcprovider"
+	"auth-go/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// oauthStateDur is how long a login at a provider can take.
+const oauthStateDur = 10 * time.Minute
+
+var (
+	ErrNoEmail = errors.New("the provider didn't share an email")
+	// ErrEmailNotVerified is a provider account whose email the provider hasn't verified,
+	// it can't sign in since whoever holds it could claim the account of the email.
+	ErrEmailNotVerified = errors.New("the provider hasn't verified the email")
+)
+
+// OAuthLogin starts a login at the provider, a random state, nonce and PKCE verifier are
+// kept until its callback. The state has to come back from the same browser.
+func (svc *AuthService) OAuthLogin(providerName string) (authURL, state string, err error) {
+	provider, errGet := svc.providers.Get(providerName)
+	if errGet != nil {
+		return "", "", errGet
+	}
+
+	state, errState := authjwt.NewID()
+	if errState != nil {
+		return "", "", errState
+	}
+	nonce, errNonce := authjwt.NewID()
+	if errNonce != nil {
+		return "", "", errNonce
+	}
+	codeVerifier, errVerifier := oidcprovider.NewCodeVerifier()
+	if errVerifier != nil {
+		return "", "", errVerifier
+	}
+
+	errSet := svc.repo.SetOAuthState(state, &model.OAuthState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, oauthStateDur)
+	if errSet != nil {
+		return "", "", errSet
+	}
+
+	return provider.AuthCodeURL(state, nonce, codeVerifier), state, nil
+}
+
+// OAuthCallback finishes the login of the state, the code is exchanged for an ID token of
+// the provider that has to carry the nonce of the login.
+func (svc *AuthService) OAuthCallback(ctx context.Context, providerName, state, code string) (*model.User, error) {
+	provider, errGet := svc.providers.Get(providerName)
+	if errGet != nil {
+		return nil, errGet
+	}
+
+	oauthState, errState := svc.repo.TakeOAuthState(state)
+	if errState != nil {
+		return nil, errState
+	}
+	if oauthState.Provider != providerName {
+		return nil, repository.ErrInvalidOAuthState
+	}
+
+	identity, errExchange := provider.Exchange(ctx, code, oauthState.CodeVerifier, oauthState.Nonce)
+	if errExchange != nil {
+		return nil, errExchange
+	}
+
+	return svc.FirstOrCreate(identity)
+}
+
+// FirstOrCreate is the user of a provider account. An account seen the first time is
+// linked to the user of its email, an email/password user included, or to a new user.
+func (svc *AuthService) FirstOrCreate(identity *model.Identity) (*model.User, error) {
+	user, errGet := svc.repo.GetByIdentity(identity.Provider, identity.Subject)
+	if errGet == nil {
+		return user, nil
+	}
+	if !errors.Is(errGet, sql.ErrNoRows) {
+		return nil, errGet
+	}
+
+	if identity.Email == "" {
+		return nil, ErrNoEmail
+	}
+	if !identity.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	username := identity.Username
+	if username == "" {
+		username = identity.Name
+	}
+	user, errCreate := svc.repo.FirstOrCreate(&model.User{
+		Username: username,
+		Email:    identity.Email,
+		Role:     "user",
+		Provider: identity.Provider,
+		FullName: identity.Name,
+		ImageURL: identity.Picture,
+	})
+	if errCreate != nil {
+		return nil, errCreate
+	}
+
+	if errLink := svc.repo.LinkIdentity(user.ID, identity); errLink != nil {
+		return nil, errLink
+	}
+	// the provider vouches for the email, a password set before it was verified goes
+	if errVerify := svc.repo.MarkEmailVerified(identity.Email, true); errVerify != nil {
+		return nil, errVerify
+	}
+
+	user.Password = ""
+	return user, nil
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"auth-go/mocks"
+	"auth-go/model"
+	"auth-go/package/oidcprovider"
+	"auth-go/service"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_FirstOrCreate(t *testing.T) {
+	identity := model.Identity{
+		Provider: "google", Subject: "subject1",
+		Email: "budi@mail.com", EmailVerified: true, Name: "Budi",
+	}
+	passwordUser := &model.User{ID: 7, Email: "budi@mail.com", Password: "hashed", Provider: "email"}
+
+	tests := []struct {
+		name       string
+		identity   func(*model.Identity)
+		beforeTest func(*mocks.AuthRepositoryI)
+		want       *model.User
+		wantErr    error
+	}{
+		{
+			name: "linked account",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetByIdentity", "google", "subject1").Return(&model.User{ID: 7}, nil)
+			},
+			want: &model.User{ID: 7},
+		},
+		{
+			name: "links the email/password user of the email",
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetByIdentity", "google", "subject1").Return(nil, sql.ErrNoRows)
+				repo.On("FirstOrCreate", mock.Anything).Return(passwordUser, nil)
+				repo.On("LinkIdentity", uint(7), mock.Anything).Return(nil)
+				repo.On("MarkEmailVerified", "budi@mail.com", true).Return(nil)
+			},
+			want: &model.User{ID: 7, Email: "budi@mail.com", Provider: "email"},
+		},
+		{
+			name:     "failed - email not verified",
+			identity: func(i *model.Identity) { i.EmailVerified = false },
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetByIdentity", "google", "subject1").Return(nil, sql.ErrNoRows)
+			},
+			wantErr: service.ErrEmailNotVerified,
+		},
+		{
+			name:     "failed - no email",
+			identity: func(i *model.Identity) { i.Email = "" },
+			beforeTest: func(repo *mocks.AuthRepositoryI) {
+				repo.On("GetByIdentity", "google", "subject1").Return(nil, sql.ErrNoRows)
+			},
+			wantErr: service.ErrNoEmail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewAuthRepositoryI(t)
+			tt.beforeTest(repo)
+			svc := service.NewAuthService(repo, nil, oidcprovider.NewRegistry(), nil, nil, "", nil, nil)
+
+			identity := identity
+			if tt.identity != nil {
+				tt.identity(&identity)
+			}
+
+			got, err := svc.FirstOrCreate(&identity)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
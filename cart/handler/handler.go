@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"fmt"
-	"net/http"
-	"strconv"
 	"cart-go/helper/response"
 	"cart-go/model"
 	"cart-go/service"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
 )
 
 type handler struct {
@@ -56,7 +56,7 @@ func (h *handler) Create(ctx *gin.Context) {
 			response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("userID must be positive number"))
 			return
 		}
-	
+
 		if v.ProductID <= 0 {
 			response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("cartID must be positive number"))
 			return
@@ -68,16 +68,17 @@ func (h *handler) Create(ctx *gin.Context) {
 		}
 	}
 
-	res, err := h.svc.Create(req)
+	err = h.svc.Create(ctx.Request.Context(), req)
 	if err != nil {
 		response.ResponseError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	response.ResponseSuccess(ctx, http.StatusOK, res)
+	// consumer-worker writes it shortly, it shows up in the list once it has
+	response.ResponseSuccess(ctx, http.StatusAccepted, req)
 }
 
 func (h *handler) Delete(ctx *gin.Context) {
-	cartIDString, ok := ctx.GetQuery("cart_id")	
+	cartIDString, ok := ctx.GetQuery("cart_id")
 	if !ok {
 		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("query param cart_id should not be empty"))
 		return
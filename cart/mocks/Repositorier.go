@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	model "cart-go/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,30 +15,18 @@ type Repositorier struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: req
-func (_m *Repositorier) Create(req []model.CartRequest) ([]model.Cart, error) {
-	ret := _m.Called(req)
+// Create provides a mock function with given fields: ctx, req
+func (_m *Repositorier) Create(ctx context.Context, req []model.CartRequest) error {
+	ret := _m.Called(ctx, req)
 
-	var r0 []model.Cart
-	var r1 error
-	if rf, ok := ret.Get(0).(func([]model.CartRequest) ([]model.Cart, error)); ok {
-		return rf(req)
-	}
-	if rf, ok := ret.Get(0).(func([]model.CartRequest) []model.Cart); ok {
-		r0 = rf(req)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Cart)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func([]model.CartRequest) error); ok {
-		r1 = rf(req)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.CartRequest) error); ok {
+		r0 = rf(ctx, req)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
 // Delete provides a mock function with given fields: cartID
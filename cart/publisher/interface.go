@@ -1,6 +1,8 @@
 //go:generate mockery --output=../mocks --name Repositorier
 package publisher
 
+import "context"
+
 type PublisherInterface interface {
-	Publish(body interface{}, queueName string) (err error)
-}
\ No newline at end of file
+	Publish(ctx context.Context, body interface{}, queueName string) (err error)
+}
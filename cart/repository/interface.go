@@ -3,12 +3,13 @@ package repository
 
 import (
 	"cart-go/model"
+	"context"
 )
 
 type Repositorier interface {
 	Get(userID int) (res []model.Cart, err error)
 	GetByID(cartID int) (res model.Cart, err error)
 	GetDetail(userID, productID int) (res model.Cart, err error)
-	Create(req []model.CartRequest) (res []model.Cart, err error)
+	Create(ctx context.Context, req []model.CartRequest) (err error)
 	Delete(cartID int) (err error)
-}
\ No newline at end of file
+}
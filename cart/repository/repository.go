@@ -5,7 +5,6 @@ import (
 	"cart-go/publisher"
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 )
@@ -83,22 +82,12 @@ func (repo *repository) GetDetail(userID, productID int) (res model.Cart, err er
 	return
 }
 
-func (repo *repository) Create(req []model.CartRequest) (res []model.Cart, err error) {
-	// publish data to RabbitMQ
-	err = repo.publisher.Publish(req, "create_carts")
+// Create publishes the create_carts event, consumer-worker writes it. It returns once the event is
+// stored in the outbox, before consumer-worker has written it.
+func (repo *repository) Create(ctx context.Context, req []model.CartRequest) (err error) {
+	err = repo.publisher.Publish(ctx, req, "create_carts")
 	if err != nil {
-		err = fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
-		return
-	}
-
-	time.Sleep(3*time.Second)
-
-	for _, v := range req {
-		result, err := repo.GetDetail(v.UserID, v.ProductID)
-		if err != nil {
-			return []model.Cart{}, errors.New("error get by user id after create")
-		}
-		res = append(res, result)
+		return fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
 	}
 	return
 }
@@ -114,4 +103,4 @@ func (repo *repository) Delete(cartID int) (err error) {
 	}
 
 	return
-}
\ No newline at end of file
+}
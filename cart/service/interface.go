@@ -3,11 +3,12 @@ package service
 
 import (
 	"cart-go/model"
+	"context"
 )
 
 type Servicer interface {
 	Get(userID int) (res []model.Cart, err error)
 	GetDetail(userID, productID int) (res model.Cart, err error)
-	Create(req []model.CartRequest) (res []model.Cart, err error)
+	Create(ctx context.Context, req []model.CartRequest) (err error)
 	Delete(cartID int) (err error)
-}
\ No newline at end of file
+}
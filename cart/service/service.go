@@ -3,6 +3,7 @@ package service
 import (
 	"cart-go/model"
 	"cart-go/repository"
+	"context"
 	"errors"
 	"fmt"
 )
@@ -35,7 +36,7 @@ func (svc *service) GetDetail(userID, productID int) (res model.Cart, err error)
 	return
 }
 
-func (svc *service) Create(req []model.CartRequest) (res []model.Cart, err error) {
+func (svc *service) Create(ctx context.Context, req []model.CartRequest) (err error) {
 	// check in each userID, ProductID already exist in cart or not
 	for _, v := range req {
 		_, err := svc.GetDetail(v.UserID, v.ProductID)
@@ -43,11 +44,11 @@ func (svc *service) Create(req []model.CartRequest) (res []model.Cart, err error
 			continue
 		} else {
 			err = fmt.Errorf("cart with product_id %d in user_id %d already exist", v.ProductID, v.UserID)
-			return []model.Cart{}, err
+			return err
 		}
 	}
 
-	return svc.repo.Create(req)
+	return svc.repo.Create(ctx, req)
 }
 
 func (svc *service) Delete(cartID int) (err error) {
@@ -58,4 +59,4 @@ func (svc *service) Delete(cartID int) (err error) {
 		return fmt.Errorf("item with id %d not found", cartID)
 	}
 	return svc.repo.Delete(cartID)
-}
\ No newline at end of file
+}
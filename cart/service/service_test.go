@@ -3,6 +3,7 @@ package service
 import (
 	"cart-go/mocks"
 	"cart-go/model"
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -17,7 +18,7 @@ func Test_service_Get(t *testing.T) {
 		svc     *service
 		args    args
 		wantRes []model.Cart
-		err		error
+		err     error
 		wantErr bool
 	}{
 		{
@@ -29,7 +30,7 @@ func Test_service_Get(t *testing.T) {
 				{Id: 1, UserID: 1, ProductID: 1, Quantity: 5},
 				{Id: 1, UserID: 1, ProductID: 2, Quantity: 5},
 			},
-			err: nil,
+			err:     nil,
 			wantErr: false,
 		},
 		{
@@ -38,7 +39,7 @@ func Test_service_Get(t *testing.T) {
 				userID: 2,
 			},
 			wantRes: []model.Cart{},
-			err: fmt.Errorf("some error"),
+			err:     fmt.Errorf("some error"),
 			wantErr: true,
 		},
 	}
@@ -68,8 +69,7 @@ func Test_service_Create(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		wantRes []model.Cart
-		err		error
+		err     error
 		wantErr bool
 	}{
 		{
@@ -80,11 +80,7 @@ func Test_service_Create(t *testing.T) {
 					{UserID: 1, ProductID: 2, Quantity: 5},
 				},
 			},
-			wantRes: []model.Cart{
-				{Id: 1, UserID: 1, ProductID: 1, Quantity: 5},
-				{Id: 2, UserID: 1, ProductID: 2, Quantity: 5},
-			},
-			err: nil,
+			err:     nil,
 			wantErr: false,
 		},
 		{
@@ -95,8 +91,7 @@ func Test_service_Create(t *testing.T) {
 					{UserID: 1, ProductID: 2, Quantity: 5},
 				},
 			},
-			wantRes: []model.Cart{},
-			err: fmt.Errorf("some error"),
+			err:     fmt.Errorf("some error"),
 			wantErr: true,
 		},
 	}
@@ -105,16 +100,15 @@ func Test_service_Create(t *testing.T) {
 			repoMock := mocks.NewRepositorier(t)
 			service := NewService(repoMock)
 
-			repoMock.On("Create", tt.args.req).Return(tt.wantRes, tt.err)
-			repoMock.On("GetDetail", tt.args.req[1].UserID, tt.args.req[1].ProductID).Return(tt.wantRes, tt.err)
+			// none of the products is in the cart yet
+			for _, v := range tt.args.req {
+				repoMock.On("GetDetail", v.UserID, v.ProductID).Return(model.Cart{}, nil)
+			}
+			repoMock.On("Create", context.Background(), tt.args.req).Return(tt.err)
 
-			gotRes, err := service.Create(tt.args.req)
+			err := service.Create(context.Background(), tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("service.Create() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(gotRes, tt.wantRes) {
-				t.Errorf("service.Create() = %v, want %v", gotRes, tt.wantRes)
 			}
 		})
 	}
@@ -127,26 +121,26 @@ func Test_service_Delete(t *testing.T) {
 	// for mock response
 	type mockGetById struct {
 		res model.Cart
-		err	error
+		err error
 	}
 
 	tests := []struct {
-		name    	string
-		args    	args
-		err			error
-		wantErr 	bool
-		getByID 	mockGetById
+		name    string
+		args    args
+		err     error
+		wantErr bool
+		getByID mockGetById
 	}{
 		{
 			name: "success delete cart",
 			args: args{
 				cartID: 1,
 			},
-			err: nil,
+			err:     nil,
 			wantErr: false,
 			// used for repo mock response
 			getByID: mockGetById{
-				model.Cart{Id: 1, ProductID: 1, UserID: 1, Quantity: 5}, 
+				model.Cart{Id: 1, ProductID: 1, UserID: 1, Quantity: 5},
 				nil,
 			},
 		},
@@ -155,11 +149,11 @@ func Test_service_Delete(t *testing.T) {
 			args: args{
 				cartID: 2,
 			},
-			err: fmt.Errorf("some error"),
+			err:     fmt.Errorf("some error"),
 			wantErr: true,
 			// used for repo mock response
 			getByID: mockGetById{
-				model.Cart{}, 
+				model.Cart{},
 				fmt.Errorf("cart not found"),
 			},
 		},
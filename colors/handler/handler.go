@@ -36,7 +36,7 @@ func (h *handler) CreateColors(ctx *gin.Context) {
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.CreateColors(data)
+	res, err := h.svc.CreateColors(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
@@ -1,9 +1,12 @@
 package repository
 
-import "product-colors-go/model"
+import (
+	"context"
+	"product-colors-go/model"
+)
 
 type Repositorier interface {
 	GetColors() ([]model.Colors, error)
-	CreateColors(req []model.ColorsReq) ([]model.Colors, error)
+	CreateColors(ctx context.Context, req []model.ColorsReq) error
 	DeleteColors(id int) (int, error)
 }
@@ -1,13 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"product-colors-go/helper/failerror"
 	"product-colors-go/helper/timeout"
 	"product-colors-go/model"
 	"product-colors-go/publisher"
-	"time"
 )
 
 type repository struct {
@@ -41,54 +41,29 @@ func (repo *repository) GetColors() ([]model.Colors, error) {
 	return data, nil
 }
 
-func (repo *repository) CreateColors(sent []model.ColorsReq) ([]model.Colors, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
+// CreateColors publishes the create_colors event, consumer-worker writes the colors. It returns once
+// the event is stored in the outbox, the colors show up by their name once it has.
+func (repo *repository) CreateColors(ctx context.Context, sent []model.ColorsReq) error {
+	queryCtx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
 	for _, v := range sent {
 		var idCheck int
 		queryCheck := `select id from product_colors where name = $1`
-		err := repo.db.QueryRowContext(ctx, queryCheck, v.Name).Scan(&idCheck)
+		err := repo.db.QueryRowContext(queryCtx, queryCheck, v.Name).Scan(&idCheck)
 		failerror.FailError(err, "error exec")
 
 		if idCheck != 0 {
-			return nil, errors.New("name " + v.Name + " already exist")
+			return errors.New("name " + v.Name + " already exist")
 		}
 	}
 
-	err := repo.sent.Public(sent, "create_colors")
+	err := repo.sent.Publish(ctx, sent, "create_colors")
 	if err != nil {
-		return nil, errors.New("failed publisher")
-	}
-
-	time.Sleep(1 * time.Second)
-
-	var resultss []model.Colors
-	query := `select * from product_colors where name = $1`
-
-	stmt, err := repo.db.PrepareContext(ctx, query)
-	failerror.FailError(err, "error prepare")
-
-	for _, v := range sent {
-
-		result, err := stmt.QueryContext(ctx, v.Name)
-		failerror.FailError(err, "error prepare")
-
-		var temp model.Colors
-		for result.Next() {
-			result.Scan(&temp.Id, &temp.Name, &temp.Created_at, &temp.Update_at)
-		}
-		if temp.Id == 0 {
-			continue
-		}
-		resultss = append(resultss, temp)
-	}
-
-	if resultss == nil {
-		return nil, errors.New("error create product")
+		return errors.New("failed publisher")
 	}
 
-	return resultss, nil
+	return nil
 }
 
 func (repo *repository) DeleteColors(id int) (int, error) {
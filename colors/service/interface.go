@@ -1,9 +1,12 @@
 package service
 
-import "product-colors-go/model"
+import (
+	"context"
+	"product-colors-go/model"
+)
 
 type Servicer interface {
 	GetColors() (model.Respon, error)
-	CreateColors(req []model.ColorsReq) (model.Respon, error)
+	CreateColors(ctx context.Context, req []model.ColorsReq) (model.Respon, error)
 	DeleteColors(idColors int) (model.Respon, error)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"product-colors-go/model"
@@ -32,7 +33,7 @@ func (svc *service) GetColors() (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateColors(req []model.ColorsReq) (model.Respon, error) {
+func (svc *service) CreateColors(ctx context.Context, req []model.ColorsReq) (model.Respon, error) {
 
 	var check []model.ColorsReq
 
@@ -54,16 +55,17 @@ func (svc *service) CreateColors(req []model.ColorsReq) (model.Respon, error) {
 	}
 
 	// start
-	res, err := svc.repo.CreateColors(check)
+	err := svc.repo.CreateColors(ctx, check)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
 			Data:   nil,
 		}, err
 	}
+	// consumer-worker writes them shortly, the name finds each one
 	return model.Respon{
-		Status: http.StatusOK,
-		Data:   res,
+		Status: http.StatusAccepted,
+		Data:   check,
 	}, nil
 }
 
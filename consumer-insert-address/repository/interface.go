@@ -1,9 +0,0 @@
-package repository
-
-import (
-	"consumer-address-go/model"
-)
-
-type Repositorier interface {
-	Create(req model.AddressRequest) (err error)
-}
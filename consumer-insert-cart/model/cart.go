@@ -1,14 +0,0 @@
-package model
-
-type Cart struct {
-	Id        int `json:"id"`
-	UserID    int `json:"user_id"`
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
-}
-
-type CartRequest struct {
-	UserID    int `json:"user_id"`
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
-}
\ No newline at end of file
@@ -1,9 +0,0 @@
-package repository
-
-import (
-	"consumer-cart-go/model"
-)
-
-type Repositorier interface {
-	Create(req []model.CartRequest) (err error)
-}
\ No newline at end of file
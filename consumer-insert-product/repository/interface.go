@@ -1,7 +0,0 @@
-package repository
-
-import "consumer-product-go/model"
-
-type Product interface {
-	CreateProduct(req []model.Product) error
-}
@@ -1,9 +0,0 @@
-package repository
-
-import (
-	"consumer-review-go/model"
-)
-
-type Repositorier interface {
-	Create(req []model.ReviewRequest) (err error)
-}
\ No newline at end of file
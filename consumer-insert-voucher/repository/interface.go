@@ -1,7 +0,0 @@
-package repository
-
-import "consumer-insert-voucher-go/model"
-
-type Product interface {
-	CreateProduct(req []model.Voucher) error
-}
@@ -1,10 +0,0 @@
-package timeout
-
-import (
-	"context"
-	"time"
-)
-
-func NewCtxTimeout() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), 10*time.Second)
-}
\ No newline at end of file
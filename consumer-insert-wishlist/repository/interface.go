@@ -1,9 +0,0 @@
-package repository
-
-import (
-	"consumer-wishlist-go/model"
-)
-
-type Repositorier interface {
-	Create(req []model.WishlistRequest) (err error)
-}
\ No newline at end of file
@@ -1,9 +0,0 @@
-package helpers
-
-import "log"
-
-func FailOnError(err error, msg string) {
-	if err != nil {
-		log.Panicf("%s: %s", msg, err)
-	}
-}
@@ -1,11 +0,0 @@
-package helpers
-
-import (
-	"context"
-	"time"
-)
-
-func NewCtxTimeout() (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	return ctx, cancel
-}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	Debug bool `mapstructure:"DEBUG"`
+
+	// MetricsAddr is where /metrics is served, the worker has no other http server
+	MetricsAddr  string `mapstructure:"METRICS_ADDR"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	Database `mapstructure:",squash"`
+	RabbitMQ `mapstructure:",squash"`
+	Worker   `mapstructure:",squash"`
+}
+
+type Database struct {
+	Driver string `mapstructure:"DATABASE_DRIVER"`
+	URL    string `mapstructure:"DATABASE_URL"`
+	DBName string `mapstructure:"PGDATABASE"`
+}
+
+type RabbitMQ struct {
+	URL string `mapstructure:"RABBITMQ_URL"`
+}
+
+// Worker picks the queues this worker consumes. Queues is a comma separated list of
+// name[:concurrency[:prefetch]], a queue without its own numbers uses Concurrency and Prefetch.
+// A prefetch of 0 keeps the client default of 10.
+type Worker struct {
+	Queues          string        `mapstructure:"WORKER_QUEUES"`
+	Concurrency     int           `mapstructure:"WORKER_CONCURRENCY"`
+	Prefetch        int           `mapstructure:"WORKER_PREFETCH"`
+	ShutdownTimeout time.Duration `mapstructure:"WORKER_SHUTDOWN_TIMEOUT"`
+}
+
+// Queue is one queue the worker consumes.
+type Queue struct {
+	Name        string
+	Concurrency int
+	Prefetch    int
+}
+
+func LoadConfig() (*Config, error) {
+	viper.SetConfigFile(".env")
+
+	errRead := viper.ReadInConfig()
+	if errRead != nil {
+		return nil, errRead
+	}
+
+	config := new(Config)
+	errUn := viper.Unmarshal(&config)
+	if errUn != nil {
+		return nil, errUn
+	}
+	return config, nil
+}
+
+// ParseQueues reads Queues, an empty list means every queue in all.
+func (w Worker) ParseQueues(all []string) ([]Queue, error) {
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	entries := strings.Split(w.Queues, ",")
+	if strings.TrimSpace(w.Queues) == "" {
+		entries = all
+	}
+
+	queues := []Queue{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if parts[0] == "" || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid queue %q, want name[:concurrency[:prefetch]]", entry)
+		}
+		if seen[parts[0]] {
+			return nil, fmt.Errorf("queue %s is listed twice", parts[0])
+		}
+		seen[parts[0]] = true
+
+		q := Queue{Name: parts[0], Concurrency: concurrency, Prefetch: w.Prefetch}
+		if len(parts) > 1 {
+			n, err := strconv.Atoi(parts[1])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid concurrency for queue %s: %q", q.Name, parts[1])
+			}
+			q.Concurrency = n
+		}
+		if len(parts) > 2 {
+			n, err := strconv.Atoi(parts[2])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid prefetch for queue %s: %q", q.Name, parts[2])
+			}
+			q.Prefetch = n
+		}
+		queues = append(queues, q)
+	}
+
+	return queues, nil
+}
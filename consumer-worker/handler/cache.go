@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+	"encoding/json"
+	"events-go"
+	"telemetry-go"
+
+	"github.com/wagslane/go-rabbitmq"
+)
+
+// productService is the service_name of the product routes in the gateway's api_managements.
+const productService = "product"
+
+// invalidateCache asks the gateway to drop its cached responses of serviceName after a
+// write changed them. A lost message only leaves them stale until their TTL, the write
+// itself already succeeded, so a failure is only logged.
+func (h *Handler) invalidateCache(serviceName string) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	env, err := events.New("invalidate_cache", "consumer-worker", model.CacheInvalidation{ServiceName: serviceName})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error create invalidate_cache event")
+		return
+	}
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error marshal invalidate_cache event")
+		return
+	}
+
+	err = h.publisher.PublishWithContext(ctx,
+		body,
+		[]string{env.Type},
+		rabbitmq.WithPublishOptionsContentType(events.ContentType),
+		rabbitmq.WithPublishOptionsType(env.Type),
+		rabbitmq.WithPublishOptionsMessageID(env.ID),
+	)
+	if err != nil {
+		telemetry.PublishFailed(env.Type)
+		h.logger.Error().Err(err).Msgf("error publish invalidate_cache of %s", serviceName)
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"consumer-worker-go/model"
+	"consumer-worker-go/repository"
+	"consumer-worker-go/retry"
+	"encoding/json"
+	"errors"
+	"events-go"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/wagslane/go-rabbitmq"
+)
+
+// Func handles one delivery. A nil error acks it, any other error sends it through the
+// retry queues, and an error wrapped with retry.Permanent straight to the DLQ.
+type Func func(d rabbitmq.Delivery) error
+
+type Handler struct {
+	repo      repository.Repositorier
+	publisher *rabbitmq.Publisher
+	logger    *zerolog.Logger
+}
+
+// NewHandler returns the handlers of every queue, publisher is used to reply to the order
+// service when it waits for the outcome of an order message.
+func NewHandler(repo repository.Repositorier, publisher *rabbitmq.Publisher, logger *zerolog.Logger) *Handler {
+	h := new(Handler)
+	h.repo = repo
+	h.publisher = publisher
+	h.logger = logger
+	return h
+}
+
+// Queues maps every queue the worker can consume to its handler.
+func (h *Handler) Queues() map[string]Func {
+	return map[string]Func{
+		"create_address":   h.CreateAddress,
+		"create_carts":     h.CreateCarts,
+		"create_colors":    h.CreateColors,
+		"create_order":     h.CreateOrder,
+		"create_product":   h.CreateProducts,
+		"create_reviews":   h.CreateReviews,
+		"create_shipping":  h.CreateShippings,
+		"create_size":      h.CreateSizes,
+		"create_stores":    h.CreateStores,
+		"create_voucher":   h.CreateVouchers,
+		"create_wishlists": h.CreateWishlists,
+		"update_order":     h.UpdateOrder,
+		"update_product":   h.UpdateProduct,
+	}
+}
+
+// decode reads the event of typ from d. The message id consumers deduplicate on is the
+// envelope's id, or the AMQP message id for messages published before the envelope.
+// A message that doesn't follow its event's schema can never be handled.
+func decode(d rabbitmq.Delivery, typ string) (events.Envelope, error) {
+	env, err := events.Decode(d.Body, typ)
+	if err != nil {
+		return events.Envelope{}, retry.Permanent(err)
+	}
+	if env.ID == "" {
+		env.ID = d.MessageId
+	}
+	return env, nil
+}
+
+// decodeInto decodes events with a single version into v.
+func decodeInto(d rabbitmq.Delivery, typ string, v any) (events.Envelope, error) {
+	env, err := decode(d, typ)
+	if err != nil {
+		return events.Envelope{}, err
+	}
+	if env.Version != 1 {
+		return events.Envelope{}, retry.Permanent(fmt.Errorf("unsupported %s version %d", typ, env.Version))
+	}
+	if err := json.Unmarshal(env.Payload, v); err != nil {
+		return events.Envelope{}, retry.Permanent(err)
+	}
+	return env, nil
+}
+
+// skipDuplicate treats a message that was processed before as handled.
+func (h *Handler) skipDuplicate(d rabbitmq.Delivery, err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		h.logger.Info().Msgf("message %s already processed", d.MessageId)
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) CreateAddress(d rabbitmq.Delivery) error {
+	var data model.AddressRequest
+	env, err := decodeInto(d, "create_address", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateAddress(env.ID, data))
+}
+
+func (h *Handler) CreateCarts(d rabbitmq.Delivery) error {
+	var data []model.CartRequest
+	env, err := decodeInto(d, "create_carts", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateCarts(env.ID, data))
+}
+
+func (h *Handler) CreateColors(d rabbitmq.Delivery) error {
+	var data []model.ColorsReq
+	env, err := decodeInto(d, "create_colors", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateColors(env.ID, data))
+}
+
+func (h *Handler) CreateProducts(d rabbitmq.Delivery) error {
+	var data []model.Product
+	env, err := decodeInto(d, "create_product", &data)
+	if err != nil {
+		return err
+	}
+	if err := h.repo.CreateProducts(env.ID, data); err != nil {
+		return h.skipDuplicate(d, err)
+	}
+	h.invalidateCache(productService)
+	return nil
+}
+
+func (h *Handler) CreateReviews(d rabbitmq.Delivery) error {
+	env, err := decode(d, "create_reviews")
+	if err != nil {
+		return err
+	}
+
+	var data []model.ReviewRequest
+	switch env.Version {
+	case 1:
+		var v1 []model.ReviewRequestV1
+		if err := json.Unmarshal(env.Payload, &v1); err != nil {
+			return retry.Permanent(err)
+		}
+		for _, v := range v1 {
+			data = append(data, model.ReviewRequest{UserID: v.UserID, ProductID: v.ProductID, Rating: v.Rating, ReviewText: v.ReviewText})
+		}
+	case 2:
+		if err := json.Unmarshal(env.Payload, &data); err != nil {
+			return retry.Permanent(err)
+		}
+	default:
+		return retry.Permanent(fmt.Errorf("unsupported create_reviews version %d", env.Version))
+	}
+
+	return h.skipDuplicate(d, h.repo.CreateReviews(env.ID, data))
+}
+
+func (h *Handler) CreateShippings(d rabbitmq.Delivery) error {
+	var data []model.Shipping
+	env, err := decodeInto(d, "create_shipping", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateShippings(env.ID, data))
+}
+
+func (h *Handler) CreateSizes(d rabbitmq.Delivery) error {
+	var data []model.SizeReq
+	env, err := decodeInto(d, "create_size", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateSizes(env.ID, data))
+}
+
+func (h *Handler) CreateStores(d rabbitmq.Delivery) error {
+	var data []model.StoreRequest
+	env, err := decodeInto(d, "create_stores", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateStores(env.ID, data))
+}
+
+func (h *Handler) CreateVouchers(d rabbitmq.Delivery) error {
+	var data []model.Voucher
+	env, err := decodeInto(d, "create_voucher", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateVouchers(env.ID, data))
+}
+
+func (h *Handler) CreateWishlists(d rabbitmq.Delivery) error {
+	var data []model.WishlistRequest
+	env, err := decodeInto(d, "create_wishlists", &data)
+	if err != nil {
+		return err
+	}
+	return h.skipDuplicate(d, h.repo.CreateWishlists(env.ID, data))
+}
+
+func (h *Handler) UpdateProduct(d rabbitmq.Delivery) error {
+	var data model.ProductUpd
+	env, err := decodeInto(d, "update_product", &data)
+	if err != nil {
+		return err
+	}
+	if err := h.repo.UpdateProduct(env.ID, data); err != nil {
+		return h.skipDuplicate(d, err)
+	}
+	h.invalidateCache(productService)
+	return nil
+}
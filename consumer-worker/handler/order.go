@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+	"consumer-worker-go/repository"
+	"encoding/json"
+	"errors"
+	"telemetry-go"
+
+	"github.com/wagslane/go-rabbitmq"
+)
+
+func (h *Handler) CreateOrder(d rabbitmq.Delivery) error {
+	var data model.GetOrdersSent
+	env, err := decodeInto(d, "create_order", &data)
+	if err != nil {
+		h.reply(d, model.Orders{}, err)
+		return err
+	}
+
+	order, err := h.repo.CreateOrder(env.ID, data)
+	err = h.skipDuplicate(d, err)
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("error create order %s", data.OrderNumber)
+	} else {
+		// the order took its products' stock
+		h.invalidateCache(productService)
+	}
+
+	h.reply(d, order, err)
+	if d.ReplyTo != "" {
+		// the order service got the error in the reply, it won't wait for a retry
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) UpdateOrder(d rabbitmq.Delivery) error {
+	var data model.OrderUpd
+	env, err := decodeInto(d, "update_order", &data)
+	if err != nil {
+		h.reply(d, model.Orders{}, err)
+		return err
+	}
+
+	order, err := h.repo.UpdateOrder(env.ID, data)
+	err = h.skipDuplicate(d, err)
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("error update order %s", data.OrderNumber)
+	} else {
+		// a cancelled order gave its products' stock back
+		h.invalidateCache(productService)
+	}
+
+	h.reply(d, order, err)
+	if d.ReplyTo != "" {
+		// the order service got the error in the reply, it won't wait for a retry
+		return nil
+	}
+	if errors.Is(err, repository.ErrStatusChanged) {
+		// another update got there first, this one is stale rather than failed
+		return nil
+	}
+	return err
+}
+
+// reply answers the order service when it is waiting on d.ReplyTo for the outcome of the write.
+func (h *Handler) reply(d rabbitmq.Delivery, order model.Orders, errWrite error) {
+	if d.ReplyTo == "" {
+		return
+	}
+
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	res := model.OrderReply{Success: errWrite == nil, Order: order}
+	if errWrite != nil {
+		res.Error = errWrite.Error()
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("error marshal reply")
+		return
+	}
+
+	err = h.publisher.PublishWithContext(ctx,
+		body,
+		[]string{d.ReplyTo},
+		rabbitmq.WithPublishOptionsContentType("application/json"),
+		rabbitmq.WithPublishOptionsCorrelationID(d.CorrelationId),
+	)
+	if err != nil {
+		// every request has its own reply queue, they share one label
+		telemetry.PublishFailed("amq.rabbitmq.reply-to")
+		h.logger.Error().Err(err).Msg("error publish reply")
+	}
+}
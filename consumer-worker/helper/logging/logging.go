@@ -2,7 +2,6 @@ package logging
 
 import (
 	"os"
-	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -14,11 +13,11 @@ func New(isDebug bool) *zerolog.Logger {
 	}
 
 	zerolog.SetGlobalLevel(logLvl)
-	output := zerolog.ConsoleWriter{
-		Out:        os.Stderr,
-		TimeFormat: time.RFC3339,
-		NoColor:    false,
-	}
-	logger := zerolog.New(output).With().Timestamp().Logger()
+	// output := zerolog.ConsoleWriter{
+	// 	// Out:        ,
+	// 	TimeFormat: time.RFC3339,
+	// 	NoColor:    true,
+	// }
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
 	return &logger
-}
\ No newline at end of file
+}
@@ -7,4 +7,4 @@ import (
 
 func NewCtxTimeout() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), 10*time.Second)
-}
\ No newline at end of file
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"consumer-worker-go/config"
+	"consumer-worker-go/handler"
+	"consumer-worker-go/helper/logging"
+	"consumer-worker-go/package/db"
+	"consumer-worker-go/package/rmq"
+	"consumer-worker-go/repository"
+	"consumer-worker-go/retry"
+	"consumer-worker-go/worker"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"telemetry-go"
+	"time"
+)
+
+func main() {
+	config, errConf := config.LoadConfig()
+	if errConf != nil {
+		log.Fatalf("load config err:%s", errConf)
+	}
+
+	logger := logging.New(config.Debug)
+
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "consumer-worker", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
+	metricsAddr := config.MetricsAddr
+	if metricsAddr == "" {
+		metricsAddr = ":9100"
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Handler())
+	metricsSrv := &http.Server{Addr: metricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if errSrv := metricsSrv.ListenAndServe(); errSrv != nil && !errors.Is(errSrv, http.ErrServerClosed) {
+			logger.Error().Err(errSrv).Msg("metrics server failed")
+		}
+	}()
+
+	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
+	if errDB != nil {
+		logger.Fatal().Err(errDB).Msg("db failed to connect")
+	}
+	logger.Debug().Msg("db connected")
+
+	rmqConn, errRmq := rmq.NewConn(config.RabbitMQ.URL)
+	if errRmq != nil {
+		logger.Fatal().Err(errRmq).Msg("rabbitmq failed to connect")
+	}
+	logger.Debug().Msg("rabbitmq connected")
+
+	// publishes retries and replies to the order service
+	publisher, errPub := rmq.NewPublisher(rmqConn, "", "", false)
+	if errPub != nil {
+		logger.Fatal().Err(errPub).Msg("rabbitmq publisher failed")
+	}
+
+	defer func() {
+		publisher.Close()
+
+		if errRmqC := rmqConn.Close(); errRmqC != nil {
+			logger.Error().Err(errRmqC).Msg("rabbitmq failed to closed")
+		}
+		logger.Debug().Msg("rabbitmq closed")
+
+		if errDBC := sqlDB.Close(); errDBC != nil {
+			logger.Error().Err(errDBC).Msg("db failed to closed")
+		}
+		logger.Debug().Msg("db closed")
+	}()
+
+	repo := repository.NewRepository(sqlDB.SQLDB)
+	handlers := handler.NewHandler(repo, publisher, logger).Queues()
+
+	all := make([]string, 0, len(handlers))
+	for name := range handlers {
+		all = append(all, name)
+	}
+	sort.Strings(all)
+
+	queues, errQ := config.Worker.ParseQueues(all)
+	if errQ != nil {
+		logger.Fatal().Err(errQ).Msg("invalid WORKER_QUEUES")
+	}
+
+	names := make([]string, 0, len(queues))
+	for _, q := range queues {
+		if handlers[q.Name] == nil {
+			logger.Fatal().Msgf("no handler for queue %s", q.Name)
+		}
+		names = append(names, q.Name)
+	}
+
+	if errDecl := retry.Declare(config.RabbitMQ.URL, names); errDecl != nil {
+		logger.Fatal().Err(errDecl).Msg("failed to declare retry queues")
+	}
+
+	w := worker.New(rmqConn, publisher, logger)
+	for _, q := range queues {
+		if errReg := w.Register(q, handlers[q.Name]); errReg != nil {
+			logger.Fatal().Err(errReg).Msgf("failed to consume %s", q.Name)
+		}
+	}
+
+	// block main thread - wait for shutdown signal
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	logger.Debug().Msg("awaiting signal...")
+	sig := <-sigs
+	logger.Debug().Msgf("sig: %v, draining in-flight messages", sig)
+
+	shutdownTimeout := config.Worker.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if errShut := w.Shutdown(ctx); errShut != nil {
+		logger.Error().Err(errShut).Msg("shut down before every message was settled")
+	}
+	_ = metricsSrv.Shutdown(ctx)
+	logger.Debug().Msg("consumer-worker shutting down...")
+}
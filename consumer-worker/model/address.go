@@ -0,0 +1,11 @@
+package model
+
+type AddressRequest struct {
+	UserID      int    `json:"user_id"`
+	Street      string `json:"street"`
+	City        string `json:"city"`
+	Country     string `json:"country"`
+	State       string `json:"state"`
+	Zipcode     string `json:"zipcode"`
+	PhoneNumber string `json:"phone_number"`
+}
@@ -0,0 +1,6 @@
+package model
+
+// CacheInvalidation tells the api-gateway to drop the responses it cached for a service.
+type CacheInvalidation struct {
+	ServiceName string `json:"service_name"`
+}
@@ -0,0 +1,7 @@
+package model
+
+type CartRequest struct {
+	UserID    int `json:"user_id"`
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const StatusCancelled = "cancelled"
+
+type Orders struct {
+	Id            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	ShippingID    int       `json:"shipping_id"`
+	Subtotal      float64   `json:"subtotal"`
+	Discount      float64   `json:"discount"`
+	ShippingFee   float64   `json:"shipping_fee"`
+	VoucherCode   string    `json:"voucher_code"`
+	TotalPrice    float64   `json:"total_price"`
+	Status        string    `json:"status"`
+	OrderNumber   string    `json:"order_number"`
+	ReceiptNumber string    `json:"receipt_number"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type OrderItemReq struct {
+	ProductId  int     `json:"product_id"`
+	Quantity   int     `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+	TotalPrice float64 `json:"total_price"`
+}
+
+type GetOrdersSent struct {
+	UserID       int            `json:"user_id"`
+	ShippingID   int            `json:"shipping_id"`
+	Subtotal     float64        `json:"subtotal"`
+	Discount     float64        `json:"discount"`
+	ShippingFee  float64        `json:"shipping_fee"`
+	VoucherCode  string         `json:"voucher_code"`
+	TotalPrice   float64        `json:"total_price"`
+	Status       string         `json:"status"`
+	OrderNumber  string         `json:"order_number"`
+	OrderItemReq []OrderItemReq `json:"order_items"`
+}
+
+type OrderUpd struct {
+	OrderNumber   string `json:"order_number"`
+	Status        string `json:"status"`
+	PrevStatus    string `json:"prev_status"`
+	ReceiptNumber string `json:"receipt_number"`
+	Note          string `json:"note"`
+}
+
+type OrderReply struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Order   Orders `json:"order"`
+}
@@ -0,0 +1,16 @@
+package model
+
+type ReviewRequest struct {
+	UserID     int    `json:"user_id"`
+	ProductID  int    `json:"product_id"`
+	Rating     int    `json:"rating"`
+	ReviewText string `json:"review_text"`
+}
+
+// ReviewRequestV1 is version 1 of the create_reviews event, which named the rating rating_id.
+type ReviewRequestV1 struct {
+	UserID     int    `json:"user_id"`
+	ProductID  int    `json:"product_id"`
+	Rating     int    `json:"rating_id"`
+	ReviewText string `json:"review_text"`
+}
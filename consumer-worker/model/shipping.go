@@ -7,10 +7,7 @@ import (
 type Shipping struct {
 	Id         int       `json:"id"`
 	Name       string    `json:"name"`
+	Fee        float64   `json:"fee"`
 	Created_at time.Time `json:"created_at"`
 	Update_at  time.Time `json:"updated_at"`
 }
-
-type ShippingReq struct {
-	Name string `json:"name"`
-}
@@ -0,0 +1,8 @@
+package model
+
+type StoreRequest struct {
+	AddressID   int    `json:"address_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+}
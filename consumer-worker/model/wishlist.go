@@ -0,0 +1,6 @@
+package model
+
+type WishlistRequest struct {
+	UserID    int `json:"user_id"`
+	ProductID int `json:"product_id"`
+}
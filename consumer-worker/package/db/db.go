@@ -1,7 +1,7 @@
 package db
 
 import (
-	"consumer-store-go/helper/timeout"
+	"consumer-worker-go/helper/timeout"
 	"database/sql"
 	"errors"
 	"time"
@@ -54,7 +54,11 @@ func (g *GormDB) init(debug bool, driver, url string) error {
 		return err
 	}
 
-	// _ = db.AutoMigrate(new(model.Store))
+	// _ = db.AutoMigrate(
+	// 	new(model.User),
+	// 	new(model.UserSetting),
+	// 	new(model.Language),
+	// )
 
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -1,23 +1,12 @@
 package repository
 
 import (
-	"consumer-address-go/model"
+	"consumer-worker-go/model"
 	"context"
-	"database/sql"
 	"time"
 )
 
-type repository struct {
-	db *sql.DB
-}
-
-func NewRepository(db *sql.DB) Repositorier {
-	return &repository{
-		db: db,
-	}
-}
-
-func (repo *repository) Create(req model.AddressRequest) (err error) {
+func (repo *repository) CreateAddress(messageID string, req model.AddressRequest) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
@@ -26,6 +15,12 @@ func (repo *repository) Create(req model.AddressRequest) (err error) {
 	if err != nil {
 		return
 	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerAddress, messageID)
+	if err != nil {
+		return err
+	}
 
 	stmt, err := trx.PrepareContext(ctx, query)
 	if err != nil {
@@ -34,10 +29,8 @@ func (repo *repository) Create(req model.AddressRequest) (err error) {
 
 	_, err = stmt.ExecContext(ctx, req.UserID, req.Street, req.City, req.State, req.Country, req.Zipcode, req.PhoneNumber)
 	if err != nil {
-		trx.Rollback()
 		return err
 	}
 
-	trx.Commit()
-	return
+	return trx.Commit()
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"consumer-worker-go/model"
+)
+
+type Repositorier interface {
+	CreateAddress(messageID string, req model.AddressRequest) (err error)
+	CreateCarts(messageID string, req []model.CartRequest) (err error)
+	CreateColors(messageID string, req []model.ColorsReq) error
+	CreateOrder(messageID string, req model.GetOrdersSent) (model.Orders, error)
+	CreateProducts(messageID string, req []model.Product) error
+	CreateReviews(messageID string, req []model.ReviewRequest) (err error)
+	CreateShippings(messageID string, req []model.Shipping) error
+	CreateSizes(messageID string, req []model.SizeReq) error
+	CreateStores(messageID string, req []model.StoreRequest) (err error)
+	CreateVouchers(messageID string, req []model.Voucher) error
+	CreateWishlists(messageID string, req []model.WishlistRequest) (err error)
+	UpdateOrder(messageID string, req model.OrderUpd) (model.Orders, error)
+	UpdateProduct(messageID string, req model.ProductUpd) error
+}
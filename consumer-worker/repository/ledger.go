@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Keys of each handler's rows in the processed_messages ledger. They keep the names of the
+// consumers the handlers used to run in, so messages processed before the merge still count.
+const (
+	ledgerAddress       = "consumer-insert-address"
+	ledgerCart          = "consumer-insert-cart"
+	ledgerColors        = "consumer-insert-colors"
+	ledgerOrder         = "consumer-insert-order"
+	ledgerProduct       = "consumer-insert-product"
+	ledgerReview        = "consumer-insert-review"
+	ledgerShipping      = "consumer-insert-shipping"
+	ledgerSize          = "consumer-insert-size"
+	ledgerStore         = "consumer-insert-store"
+	ledgerVoucher       = "consumer-insert-voucher"
+	ledgerWishlist      = "consumer-insert-wishlist"
+	ledgerOrderUpdate   = "consumer-update-order"
+	ledgerProductUpdate = "consumer-update-product"
+)
+
+// ErrDuplicate means the message was processed before, its write is skipped.
+var ErrDuplicate = errors.New("message already processed")
+
+// markProcessed records messageID for consumer in the processed_messages ledger with trx, so
+// the ledger row commits or rolls back together with the message's write. A message seen
+// before returns ErrDuplicate. Messages published without an id can't be deduplicated.
+func markProcessed(ctx context.Context, trx *sql.Tx, consumer, messageID string) error {
+	if messageID == "" {
+		return nil
+	}
+
+	query := `insert into processed_messages (consumer, message_id) values ($1, $2) on conflict do nothing`
+
+	res, err := trx.ExecContext(ctx, query, consumer, messageID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
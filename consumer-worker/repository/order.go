@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func (repo *repository) CreateOrder(messageID string, req model.GetOrdersSent) (model.Orders, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Orders{}, err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerOrder, messageID)
+	if errors.Is(err, ErrDuplicate) {
+		// the order was created by the first delivery, reply with it again
+		order, errGet := getOrder(ctx, trx, req.OrderNumber)
+		if errGet != nil {
+			return model.Orders{}, errGet
+		}
+		return order, err
+	}
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	queryOrder := `insert into orders (user_id, shipping_id, subtotal, discount, shipping_fee, voucher_code, total_price, status, order_number) values ($1,$2,$3,$4,$5,nullif($6, ''),$7,$8,$9) returning id, created_at, updated_at`
+
+	order := model.Orders{
+		UserID:      req.UserID,
+		ShippingID:  req.ShippingID,
+		Subtotal:    req.Subtotal,
+		Discount:    req.Discount,
+		ShippingFee: req.ShippingFee,
+		VoucherCode: req.VoucherCode,
+		TotalPrice:  req.TotalPrice,
+		Status:      req.Status,
+		OrderNumber: req.OrderNumber,
+	}
+	err = trx.QueryRowContext(ctx, queryOrder, req.UserID, req.ShippingID, req.Subtotal, req.Discount, req.ShippingFee, req.VoucherCode, req.TotalPrice, req.Status, req.OrderNumber).Scan(&order.Id, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	err = reserveStock(ctx, trx, req.OrderItemReq)
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	queryOrderItem := `insert into order_items (order_id,product_id,quantity,unit_price,total_price) values ($1,$2,$3,$4,$5)`
+
+	stmt, err := trx.PrepareContext(ctx, queryOrderItem)
+	if err != nil {
+		return model.Orders{}, err
+	}
+	defer stmt.Close()
+
+	for _, v := range req.OrderItemReq {
+		_, err := stmt.ExecContext(ctx, order.Id, v.ProductId, v.Quantity, v.UnitPrice, v.TotalPrice)
+		if err != nil {
+			return model.Orders{}, err
+		}
+	}
+
+	queryHistory := `insert into order_status_histories (order_id, to_status, note) values ($1,$2,$3)`
+
+	_, err = trx.ExecContext(ctx, queryHistory, order.Id, req.Status, "order created")
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	return order, nil
+}
+
+func getOrder(ctx context.Context, trx *sql.Tx, orderNumber string) (model.Orders, error) {
+	query := `select id, user_id, shipping_id, subtotal, discount, shipping_fee, coalesce(voucher_code, ''), total_price, status, order_number, coalesce(receipt_number, ''), created_at, updated_at from orders where order_number = $1`
+
+	var order model.Orders
+	err := trx.QueryRowContext(ctx, query, orderNumber).Scan(&order.Id, &order.UserID, &order.ShippingID, &order.Subtotal, &order.Discount, &order.ShippingFee, &order.VoucherCode, &order.TotalPrice, &order.Status, &order.OrderNumber, &order.ReceiptNumber, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	return order, nil
+}
+
+// reserveStock locks every ordered product and takes the quantities out of its stock.
+// Products are locked in id order so two orders sharing products cannot deadlock.
+// Nothing is decremented unless every item can be served.
+func reserveStock(ctx context.Context, trx *sql.Tx, items []model.OrderItemReq) error {
+	quantities := map[int]int{}
+	productIDs := []int{}
+	for _, v := range items {
+		if _, ok := quantities[v.ProductId]; !ok {
+			productIDs = append(productIDs, v.ProductId)
+		}
+		quantities[v.ProductId] += v.Quantity
+	}
+	sort.Ints(productIDs)
+
+	querySelect := `select coalesce(stock, 0) from products where id = $1 for update`
+
+	var shortages []string
+	for _, id := range productIDs {
+		var stock int
+		err := trx.QueryRowContext(ctx, querySelect, id).Scan(&stock)
+		if err == sql.ErrNoRows {
+			shortages = append(shortages, fmt.Sprintf("product %d not found", id))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if stock < quantities[id] {
+			shortages = append(shortages, fmt.Sprintf("product %d requested %d, available %d", id, quantities[id], stock))
+		}
+	}
+
+	if len(shortages) != 0 {
+		return errors.New("insufficient stock: " + strings.Join(shortages, "; "))
+	}
+
+	queryUpdate := `update products set stock = stock - $1, updated_at = now() where id = $2`
+
+	for _, id := range productIDs {
+		_, err := trx.ExecContext(ctx, queryUpdate, quantities[id], id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
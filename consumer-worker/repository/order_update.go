@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrStatusChanged means the order left the status the update expected before it was applied.
+var ErrStatusChanged = errors.New("order status changed")
+
+func (repo *repository) UpdateOrder(messageID string, req model.OrderUpd) (model.Orders, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Orders{}, err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerOrderUpdate, messageID)
+	if errors.Is(err, ErrDuplicate) {
+		// the update was applied by the first delivery, reply with the order as it is now
+		order, errGet := getOrder(ctx, trx, req.OrderNumber)
+		if errGet != nil {
+			return model.Orders{}, errGet
+		}
+		return order, err
+	}
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	// only move the order when it is still in the status the order or payment service validated
+	// against, so two concurrent updates cannot both apply. A partially_refunded order may be
+	// partially refunded again, prev and new status are then the same and only the history grows
+	querys := `update orders set status = $1, receipt_number = coalesce(nullif($2, ''), receipt_number), updated_at = now() where order_number = $3 and status = $4 returning id, user_id, shipping_id, total_price, status, order_number, coalesce(receipt_number, ''), created_at, updated_at`
+
+	var order model.Orders
+	err = trx.QueryRowContext(ctx, querys, req.Status, req.ReceiptNumber, req.OrderNumber, req.PrevStatus).Scan(&order.Id, &order.UserID, &order.ShippingID, &order.TotalPrice, &order.Status, &order.OrderNumber, &order.ReceiptNumber, &order.CreatedAt, &order.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return model.Orders{}, fmt.Errorf("%w: order %s is no longer %s", ErrStatusChanged, req.OrderNumber, req.PrevStatus)
+	}
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	queryHistory := `insert into order_status_histories (order_id, from_status, to_status, note) values ($1,$2,$3,$4)`
+
+	_, err = trx.ExecContext(ctx, queryHistory, order.Id, req.PrevStatus, req.Status, req.Note)
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	// a cancelled order, whether by the customer or an expired payment, gives its reserved stock back
+	if req.Status == model.StatusCancelled {
+		err = releaseStock(ctx, trx, order.Id)
+		if err != nil {
+			return model.Orders{}, err
+		}
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return model.Orders{}, err
+	}
+
+	return order, nil
+}
+
+func releaseStock(ctx context.Context, trx *sql.Tx, orderID int) error {
+	queryRelease := `update products p set stock = p.stock + oi.quantity, updated_at = now() from (select product_id, sum(quantity) as quantity from order_items where order_id = $1 group by product_id) oi where p.id = oi.product_id`
+
+	_, err := trx.ExecContext(ctx, queryRelease, orderID)
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+)
+
+func (repo *repository) UpdateProduct(messageID string, req model.ProductUpd) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerProductUpdate, messageID)
+	if err != nil {
+		return err
+	}
+
+	querys := `update products set store_id = $1 ,category_id = $2, size_id = $3, color_id = $4, name = $5, subtitle = $6,description = $7, unit_price = $8, status = $9, stock = $10, weight = $11, brand = $12 where id = $13`
+
+	_, err = trx.ExecContext(ctx, querys, req.StoreID, req.CategoryID, req.SizeID, req.ColorID, req.Name, req.Subtitle, req.Description, req.UnitPrice, req.Status, req.Stock, req.Weight, req.Brand, req.Id)
+	if err != nil {
+		return err
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
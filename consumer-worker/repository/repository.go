@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repositorier {
+	return &repository{
+		db: db,
+	}
+}
@@ -1,23 +1,12 @@
 package repository
 
 import (
-	"consumer-review-go/model"
+	"consumer-worker-go/model"
 	"context"
-	"database/sql"
 	"time"
 )
 
-type repository struct {
-	db 		  *sql.DB
-}
-
-func NewRepository(db *sql.DB) Repositorier {
-	return &repository{
-		db: db,
-	}
-}
-
-func (repo *repository) Create(req []model.ReviewRequest) (err error) {
+func (repo *repository) CreateReviews(messageID string, req []model.ReviewRequest) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
@@ -26,6 +15,12 @@ func (repo *repository) Create(req []model.ReviewRequest) (err error) {
 	if err != nil {
 		return
 	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerReview, messageID)
+	if err != nil {
+		return err
+	}
 
 	stmt, err := trx.PrepareContext(ctx, query)
 	if err != nil {
@@ -35,11 +30,9 @@ func (repo *repository) Create(req []model.ReviewRequest) (err error) {
 	for _, v := range req {
 		_, err := stmt.ExecContext(ctx, v.UserID, v.ProductID, v.Rating, v.ReviewText)
 		if err != nil {
-			trx.Rollback()
 			return err
 		}
 	}
-	
-	trx.Commit()
-	return
-}
\ No newline at end of file
+
+	return trx.Commit()
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+)
+
+func (repo *repository) CreateShippings(messageID string, req []model.Shipping) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerShipping, messageID)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into Shippings (name, fee) values ($1, $2)`
+
+	stmt, err := trx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range req {
+		_, err = stmt.ExecContext(ctx, v.Name, v.Fee)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+)
+
+func (repo *repository) CreateSizes(messageID string, req []model.SizeReq) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerSize, messageID)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into product_sizes (name) values ($1)`
+
+	stmt, err := trx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range req {
+		_, err = stmt.ExecContext(ctx, v.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,23 +1,12 @@
 package repository
 
 import (
-	"consumer-store-go/model"
+	"consumer-worker-go/model"
 	"context"
-	"database/sql"
 	"time"
 )
 
-type repository struct {
-	db *sql.DB
-}
-
-func NewRepository(db *sql.DB) Repositorier {
-	return &repository{
-		db: db,
-	}
-}
-
-func (repo *repository) Create(req []model.StoreRequest) (err error) {
+func (repo *repository) CreateStores(messageID string, req []model.StoreRequest) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
@@ -26,6 +15,12 @@ func (repo *repository) Create(req []model.StoreRequest) (err error) {
 	if err != nil {
 		return
 	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerStore, messageID)
+	if err != nil {
+		return err
+	}
 
 	stmt, err := trx.PrepareContext(ctx, query)
 	if err != nil {
@@ -35,11 +30,9 @@ func (repo *repository) Create(req []model.StoreRequest) (err error) {
 	for _, v := range req {
 		_, err := stmt.ExecContext(ctx, v.AddressID, v.Description, v.ImageURL, v.Name)
 		if err != nil {
-			trx.Rollback()
 			return err
 		}
 	}
 
-	trx.Commit()
-	return
+	return trx.Commit()
 }
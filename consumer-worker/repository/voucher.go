@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"consumer-worker-go/helper/timeout"
+	"consumer-worker-go/model"
+)
+
+func (repo *repository) CreateVouchers(messageID string, req []model.Voucher) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerVoucher, messageID)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into voucher (store_id,product_id,category_id,discount_value,name,code,start_date,end_date) 
+	values ($1,	$2,$3,$4,$5,$6,$7,$8)`
+
+	stmt, err := trx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range req {
+		_, err = stmt.ExecContext(ctx, v.StoreID, v.ProductID, v.CategoryID, v.Discount, v.Name, v.Code, v.StartDate, v.EndDate)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = trx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,23 +1,12 @@
 package repository
 
 import (
-	"consumer-wishlist-go/model"
+	"consumer-worker-go/model"
 	"context"
-	"database/sql"
 	"time"
 )
 
-type repository struct {
-	db 		  *sql.DB
-}
-
-func NewRepository(db *sql.DB) Repositorier {
-	return &repository{
-		db: db,
-	}
-}
-
-func (repo *repository) Create(req []model.WishlistRequest) (err error) {
+func (repo *repository) CreateWishlists(messageID string, req []model.WishlistRequest) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
@@ -26,6 +15,12 @@ func (repo *repository) Create(req []model.WishlistRequest) (err error) {
 	if err != nil {
 		return
 	}
+	defer trx.Rollback()
+
+	err = markProcessed(ctx, trx, ledgerWishlist, messageID)
+	if err != nil {
+		return err
+	}
 
 	stmt, err := trx.PrepareContext(ctx, query)
 	if err != nil {
@@ -35,11 +30,9 @@ func (repo *repository) Create(req []model.WishlistRequest) (err error) {
 	for _, v := range req {
 		_, err := stmt.ExecContext(ctx, v.UserID, v.ProductID)
 		if err != nil {
-			trx.Rollback()
 			return err
 		}
 	}
-	
-	trx.Commit()
-	return
-}
\ No newline at end of file
+
+	return trx.Commit()
+}
@@ -0,0 +1,174 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"telemetry-go"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/wagslane/go-rabbitmq"
+)
+
+const (
+	// MaxAttempts is how many times a message is handled before it is parked in the DLQ.
+	MaxAttempts = 5
+	// BaseDelay is the wait before the first retry, it doubles for every retry after that.
+	BaseDelay = 2 * time.Second
+
+	HeaderAttempts = "x-retry-attempts"
+	HeaderError    = "x-last-error"
+	HeaderQueue    = "x-original-queue"
+)
+
+// RetryQueue is where a message waits before its nth retry, the queue's TTL dead-letters
+// it back onto queue when the delay is up.
+func RetryQueue(queue string, n int) string {
+	return fmt.Sprintf("%s.retry.%d", queue, n)
+}
+
+// DLQ is where messages of queue end up once they can't be handled.
+func DLQ(queue string) string {
+	return queue + ".dlq"
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error retrying won't fix, such as a body that doesn't unmarshal.
+// The message goes straight to the DLQ.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Declare declares the retry queues and the DLQ of every queue. The queues are durable, so
+// declaring them once at startup outlives any reconnect of the consumers.
+func Declare(url string, queues []string) error {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, queue := range queues {
+		for n := 1; n < MaxAttempts; n++ {
+			delay := BaseDelay << (n - 1)
+			_, err := ch.QueueDeclare(
+				RetryQueue(queue, n), // queue name
+				true,                 // durable
+				false,                // auto delete queue when unused
+				false,                // exclusive
+				false,                // no-wait
+				amqp.Table{
+					"x-message-ttl":             delay.Milliseconds(),
+					"x-dead-letter-exchange":    "",
+					"x-dead-letter-routing-key": queue,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := ch.QueueDeclare(
+			DLQ(queue), // queue name
+			true,       // durable
+			false,      // auto delete queue when unused
+			false,      // exclusive
+			false,      // no-wait
+			nil,        // arguments
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retrier settles the deliveries of one queue.
+type Retrier struct {
+	publisher *rabbitmq.Publisher
+	queue     string
+}
+
+// New returns a Retrier that moves failed messages of queue with publisher. The queues
+// must have been declared with Declare.
+func New(publisher *rabbitmq.Publisher, queue string) *Retrier {
+	return &Retrier{publisher: publisher, queue: queue}
+}
+
+// Done tells how to settle d once it was handled with err. Handled messages are acked,
+// failed ones are moved to the next retry queue, or to the DLQ once they are out of attempts.
+func (r *Retrier) Done(d rabbitmq.Delivery, err error) rabbitmq.Action {
+	if err == nil {
+		return rabbitmq.Ack
+	}
+
+	attempts := Attempts(d) + 1
+
+	target := RetryQueue(r.queue, attempts)
+	var permanent permanentError
+	if errors.As(err, &permanent) || attempts >= MaxAttempts {
+		target = DLQ(r.queue)
+	}
+
+	log.Printf("message on %s failed attempt %d: %s, moving to %s", r.queue, attempts, err, target)
+
+	headers := rabbitmq.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderAttempts] = int32(attempts)
+	headers[HeaderError] = err.Error()
+	headers[HeaderQueue] = r.queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errPub := r.publisher.PublishWithContext(ctx,
+		d.Body,
+		[]string{target},
+		rabbitmq.WithPublishOptionsHeaders(headers),
+		rabbitmq.WithPublishOptionsPersistentDelivery,
+		rabbitmq.WithPublishOptionsContentType(d.ContentType),
+		rabbitmq.WithPublishOptionsMessageID(d.MessageId),
+		rabbitmq.WithPublishOptionsCorrelationID(d.CorrelationId),
+	)
+	if errPub != nil {
+		telemetry.PublishFailed(target)
+		// leave it on the queue rather than lose it
+		log.Printf("error move message to %s: %s", target, errPub)
+		return rabbitmq.NackRequeue
+	}
+
+	return rabbitmq.Ack
+}
+
+// Attempts is how many times d failed before this delivery.
+func Attempts(d rabbitmq.Delivery) int {
+	switch v := d.Headers[HeaderAttempts].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"consumer-worker-go/config"
+	"consumer-worker-go/handler"
+	"consumer-worker-go/package/rmq"
+	"consumer-worker-go/retry"
+	"context"
+	"sync"
+	"telemetry-go"
+
+	"github.com/rs/zerolog"
+	"github.com/wagslane/go-rabbitmq"
+)
+
+// Worker runs the handlers of the configured queues on one connection. The connection and
+// the consumers reconnect by themselves when the broker goes away.
+type Worker struct {
+	conn      *rabbitmq.Conn
+	publisher *rabbitmq.Publisher
+	logger    *zerolog.Logger
+	consumers []*rabbitmq.Consumer
+
+	// every delivery in flight holds a read lock, Shutdown takes the write lock to wait for them
+	mu       sync.RWMutex
+	stopping bool
+}
+
+func New(conn *rabbitmq.Conn, publisher *rabbitmq.Publisher, logger *zerolog.Logger) *Worker {
+	w := new(Worker)
+	w.conn = conn
+	w.publisher = publisher
+	w.logger = logger
+	return w
+}
+
+// Register starts consuming q with fn, on q.Concurrency goroutines and with q.Prefetch
+// unacked messages at most.
+func (w *Worker) Register(q config.Queue, fn handler.Func) error {
+	retrier := retry.New(w.publisher, q.Name)
+
+	consumer, err := rmq.NewConsumer(
+		w.conn,
+		func(d rabbitmq.Delivery) rabbitmq.Action {
+			w.mu.RLock()
+			defer w.mu.RUnlock()
+
+			if w.stopping {
+				// held unacked until Shutdown closes the consumer, the broker then gives it to
+				// another worker. A requeue would hand it straight back to this consumer, and with
+				// prefetch deliveries held the broker sends no more.
+				return rabbitmq.Manual
+			}
+
+			w.logger.Debug().Msgf("consumed from %s: %s", q.Name, d.Body)
+
+			// a child of the publisher's span when the message carries one
+			_, span := telemetry.StartConsume(d.Headers, q.Name)
+			err := fn(d)
+			telemetry.EndConsume(span, err)
+
+			return retrier.Done(d, err)
+		},
+		q.Name, "",
+		true, false,
+		q.Concurrency, q.Prefetch,
+		"", "", false,
+	)
+	if err != nil {
+		return err
+	}
+
+	w.consumers = append(w.consumers, consumer)
+	w.logger.Debug().Msgf("consuming %s on %d goroutines, prefetch %d", q.Name, q.Concurrency, q.Prefetch)
+	return nil
+}
+
+// Shutdown stops taking new deliveries, waits for the ones in flight to be settled or for
+// ctx to be done, then closes the consumers. The deliveries held meanwhile, and any left
+// unsettled, go back to their queue when the consumers' channels close.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		w.mu.Lock()
+		w.stopping = true
+		w.mu.Unlock()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	for _, consumer := range w.consumers {
+		consumer.Close()
+	}
+
+	return err
+}
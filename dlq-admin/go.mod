@@ -0,0 +1,5 @@
+module dlq-admin-go
+
+go 1.19
+
+require github.com/rabbitmq/amqp091-go v1.8.1
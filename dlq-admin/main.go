@@ -0,0 +1,181 @@
+// dlq-admin inspects, replays or purges the dead-letter queues the consumers park failed
+// messages in. Every consumer queue has one named <queue>.dlq.
+//
+//	dlq-admin inspect -queue create_carts -n 10
+//	dlq-admin replay -queue create_carts
+//	dlq-admin purge -queue create_carts
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// headers set by the consumers' retry package
+const (
+	headerAttempts = "x-retry-attempts"
+	headerError    = "x-last-error"
+	headerQueue    = "x-original-queue"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	url := cmd.String("url", os.Getenv("RABBITMQ_URL"), "rabbitmq url, defaults to $RABBITMQ_URL")
+	queue := cmd.String("queue", "", "consumer queue whose dlq to work on, e.g. create_carts")
+	n := cmd.Int("n", 0, "number of messages, 0 for all of them")
+	cmd.Parse(os.Args[2:])
+
+	if *url == "" || *queue == "" {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := amqp.Dial(*url)
+	if err != nil {
+		log.Fatalf("failed to connect to RabbitMQ: %s", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		log.Fatalf("failed to open a channel: %s", err)
+	}
+	defer ch.Close()
+
+	dlq := *queue + ".dlq"
+
+	switch os.Args[1] {
+	case "inspect":
+		err = inspect(ch, dlq, *n)
+	case "replay":
+		err = replay(ch, dlq, *queue, *n)
+	case "purge":
+		var purged int
+		purged, err = ch.QueuePurge(dlq, false)
+		if err == nil {
+			fmt.Printf("purged %d messages from %s\n", purged, dlq)
+		}
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatalf("%s %s: %s", os.Args[1], dlq, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq-admin inspect|replay|purge -queue <queue> [-n <count>] [-url <rabbitmq url>]")
+	os.Exit(2)
+}
+
+// inspect prints messages without taking them off the dlq, they are requeued when the
+// channel closes since none of them is acked.
+func inspect(ch *amqp.Channel, dlq string, n int) error {
+	q, err := ch.QueueDeclarePassive(dlq, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s holds %d messages\n", dlq, q.Messages)
+
+	for i := 0; n == 0 || i < n; i++ {
+		d, ok, err := ch.Get(dlq, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		fmt.Printf("\n#%d message id %q, %v attempts\n", i+1, d.MessageId, d.Headers[headerAttempts])
+		fmt.Printf("   error: %v\n", d.Headers[headerError])
+		fmt.Printf("   body:  %s\n", d.Body)
+	}
+
+	return nil
+}
+
+// replay moves messages back to the queue they failed on with a fresh set of attempts.
+// A message is only acked off the dlq once the broker confirmed the republish.
+func replay(ch *amqp.Channel, dlq, queue string, n int) error {
+	err := ch.Confirm(false)
+	if err != nil {
+		return err
+	}
+
+	replayed := 0
+	for n == 0 || replayed < n {
+		d, ok, err := ch.Get(dlq, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		target := queue
+		if original, ok := d.Headers[headerQueue].(string); ok && original != "" {
+			target = original
+		}
+
+		headers := amqp.Table{}
+		for k, v := range d.Headers {
+			headers[k] = v
+		}
+		delete(headers, headerAttempts)
+		delete(headers, headerError)
+
+		err = publish(ch, target, amqp.Publishing{
+			Headers:       headers,
+			DeliveryMode:  amqp.Persistent,
+			ContentType:   d.ContentType,
+			MessageId:     d.MessageId,
+			CorrelationId: d.CorrelationId,
+			Body:          d.Body,
+		})
+		if err != nil {
+			d.Nack(false, true)
+			return err
+		}
+
+		d.Ack(false)
+		replayed++
+	}
+
+	fmt.Printf("replayed %d messages from %s\n", replayed, dlq)
+	return nil
+}
+
+func publish(ch *amqp.Channel, queue string, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		"",    // exchange
+		queue, // routing key
+		false, // mandatory
+		false, // immediate
+		msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("message nacked by broker")
+	}
+
+	return nil
+}
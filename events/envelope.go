@@ -0,0 +1,120 @@
+// Package events is the contract of the messages the services exchange over RabbitMQ. Every
+// message is an Envelope around a payload, the payload's shape is fixed by a versioned JSON
+// Schema that is checked when the event is published and again when it is consumed.
+package events
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ContentType is set on every published envelope.
+const ContentType = "application/json"
+
+// Envelope wraps every event. Type is the queue the event is published to, Version the
+// schema its Payload follows and ID the message id consumers deduplicate on.
+type Envelope struct {
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	ID            string          `json:"id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Producer      string          `json:"producer"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// New wraps payload in an envelope of the current version of typ, it fails when payload
+// doesn't follow that version's schema so a changed struct can't reach the consumer.
+func New(typ, producer string, payload any) (Envelope, error) {
+	version := Current(typ)
+	if version == 0 {
+		return Envelope{}, fmt.Errorf("%w: %s", ErrUnknownEvent, typ)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	err = Validate(typ, version, body)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		Type:       typ,
+		Version:    version,
+		ID:         id,
+		OccurredAt: time.Now().UTC(),
+		Producer:   producer,
+		Payload:    body,
+	}, nil
+}
+
+// Decode reads an event of typ from body and validates it. A body that isn't an envelope
+// was published before envelopes existed, it is read as version 1 of typ without an id.
+func Decode(body []byte, typ string) (Envelope, error) {
+	if !isEnvelope(body) {
+		err := Validate(typ, 1, body)
+		if err != nil {
+			return Envelope{}, err
+		}
+		return Envelope{Type: typ, Version: 1, Payload: body}, nil
+	}
+
+	err := validate(reg.envelope, body, "envelope")
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	var env Envelope
+	err = json.Unmarshal(body, &env)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: %s", ErrInvalidEvent, err)
+	}
+
+	if env.Type != typ {
+		return Envelope{}, fmt.Errorf("%w: got %s, want %s", ErrInvalidEvent, env.Type, typ)
+	}
+
+	err = Validate(env.Type, env.Version, env.Payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return env, nil
+}
+
+// isEnvelope tells an envelope from the bare payloads sent before it, which never have
+// both a type and a payload field.
+func isEnvelope(body []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+
+	_, hasType := fields["type"]
+	_, hasPayload := fields["payload"]
+	return hasType && hasPayload
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
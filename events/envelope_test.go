@@ -0,0 +1,135 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type orderUpd struct {
+	OrderNumber   string `json:"order_number"`
+	Status        string `json:"status"`
+	PrevStatus    string `json:"prev_status"`
+	ReceiptNumber string `json:"receipt_number"`
+	Note          string `json:"note"`
+}
+
+func TestNew(t *testing.T) {
+	test_new := []struct {
+		name    string
+		typ     string
+		payload any
+		wantErr error
+	}{
+		{
+			name:    "valid payload",
+			typ:     "update_order",
+			payload: orderUpd{OrderNumber: "ABCDE12345", Status: "paid", PrevStatus: "pending_payment"},
+		},
+		{
+			name: "renamed field",
+			typ:  "update_order",
+			payload: struct {
+				OrderNumber string `json:"order_no"`
+				Status      string `json:"status"`
+			}{OrderNumber: "ABCDE12345", Status: "paid"},
+			wantErr: ErrInvalidEvent,
+		},
+		{
+			name:    "wrong type",
+			typ:     "create_carts",
+			payload: []map[string]any{{"user_id": "1", "product_id": 2, "quantity": 1}},
+			wantErr: ErrInvalidEvent,
+		},
+		{
+			name:    "unknown event",
+			typ:     "delete_everything",
+			payload: map[string]any{},
+			wantErr: ErrUnknownEvent,
+		},
+	}
+	for _, tt := range test_new {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := New(tt.typ, "order", tt.payload)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.typ, env.Type)
+			require.Equal(t, Current(tt.typ), env.Version)
+			require.NotEmpty(t, env.ID)
+			require.Equal(t, "order", env.Producer)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	env, err := New("update_order", "payment", orderUpd{OrderNumber: "ABCDE12345", Status: "paid", PrevStatus: "pending_payment"})
+	require.NoError(t, err)
+	body, err := json.Marshal(env)
+	require.NoError(t, err)
+
+	got, err := Decode(body, "update_order")
+	require.NoError(t, err)
+	require.Equal(t, env.ID, got.ID)
+	require.Equal(t, env.Version, got.Version)
+	require.JSONEq(t, string(env.Payload), string(got.Payload))
+
+	_, err = Decode(body, "create_order")
+	require.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+func TestDecodeVersions(t *testing.T) {
+	require.Equal(t, []int{1, 2}, Versions("create_reviews"))
+
+	test_decode := []struct {
+		name        string
+		body        string
+		wantVersion int
+		wantErr     error
+	}{
+		{
+			name:        "bare payload from before envelopes",
+			body:        `[{"user_id":1,"product_id":2,"rating_id":5,"review_text":"good"}]`,
+			wantVersion: 1,
+		},
+		{
+			name:        "version 1",
+			body:        `{"type":"create_reviews","version":1,"id":"a1","occurred_at":"2023-06-01T10:00:00Z","producer":"review","payload":[{"user_id":1,"product_id":2,"rating_id":5,"review_text":"good"}]}`,
+			wantVersion: 1,
+		},
+		{
+			name:        "version 2",
+			body:        `{"type":"create_reviews","version":2,"id":"a2","occurred_at":"2023-06-01T10:00:00Z","producer":"review","payload":[{"user_id":1,"product_id":2,"rating":5,"review_text":"good"}]}`,
+			wantVersion: 2,
+		},
+		{
+			name:    "version 2 payload labelled version 1",
+			body:    `{"type":"create_reviews","version":1,"id":"a3","occurred_at":"2023-06-01T10:00:00Z","producer":"review","payload":[{"user_id":1,"product_id":2,"rating":5,"review_text":"good"}]}`,
+			wantErr: ErrInvalidEvent,
+		},
+		{
+			name:    "unknown version",
+			body:    `{"type":"create_reviews","version":9,"id":"a4","occurred_at":"2023-06-01T10:00:00Z","producer":"review","payload":[]}`,
+			wantErr: ErrUnknownEvent,
+		},
+		{
+			name:    "envelope without id",
+			body:    `{"type":"create_reviews","version":2,"occurred_at":"2023-06-01T10:00:00Z","producer":"review","payload":[]}`,
+			wantErr: ErrInvalidEvent,
+		},
+	}
+	for _, tt := range test_decode {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := Decode([]byte(tt.body), "create_reviews")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVersion, env.Version)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemas holds envelope.json and a schemas/<type>/v<version>.json for every event version.
+// Adding a version is adding its file, producers move to it as soon as it is the latest.
+//
+//go:embed schemas
+var schemas embed.FS
+
+var (
+	ErrUnknownEvent = errors.New("unknown event")
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+type registry struct {
+	envelope *jsonschema.Schema
+	// payload schemas by type then version
+	payloads map[string]map[int]*jsonschema.Schema
+}
+
+// the schemas are embedded, failing to compile one is a bug and must stop every service at startup
+var reg = mustLoad()
+
+func mustLoad() *registry {
+	r, err := load(schemas)
+	if err != nil {
+		panic(fmt.Sprintf("events: %s", err))
+	}
+	return r
+}
+
+func load(fsys fs.FS) (*registry, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+
+	var files []string
+	err := fs.WalkDir(fsys, "schemas", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		if err := compiler.AddResource(schemaURL(p), bytes.NewReader(b)); err != nil {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &registry{payloads: map[string]map[int]*jsonschema.Schema{}}
+	for _, p := range files {
+		schema, err := compiler.Compile(schemaURL(p))
+		if err != nil {
+			return nil, err
+		}
+
+		if p == "schemas/envelope.json" {
+			r.envelope = schema
+			continue
+		}
+
+		typ, version, err := parseSchemaPath(p)
+		if err != nil {
+			return nil, err
+		}
+		if r.payloads[typ] == nil {
+			r.payloads[typ] = map[int]*jsonschema.Schema{}
+		}
+		r.payloads[typ][version] = schema
+	}
+
+	if r.envelope == nil {
+		return nil, errors.New("schemas/envelope.json is missing")
+	}
+	return r, nil
+}
+
+func schemaURL(p string) string {
+	return "file:///" + p
+}
+
+// parseSchemaPath reads the type and version out of schemas/<type>/v<version>.json.
+func parseSchemaPath(p string) (string, int, error) {
+	dir, file := path.Split(p)
+	typ := path.Base(dir)
+
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json"))
+	if err != nil || version < 1 || dir != "schemas/"+typ+"/" {
+		return "", 0, fmt.Errorf("%s is not schemas/<type>/v<version>.json", p)
+	}
+	return typ, version, nil
+}
+
+// Versions lists the versions of typ the registry knows, oldest first.
+func Versions(typ string) []int {
+	versions := []int{}
+	for v := range reg.payloads[typ] {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Current is the version of typ producers publish, the latest one. It is 0 for an unknown type.
+func Current(typ string) int {
+	versions := Versions(typ)
+	if len(versions) == 0 {
+		return 0
+	}
+	return versions[len(versions)-1]
+}
+
+// Validate checks payload against the schema of version of typ.
+func Validate(typ string, version int, payload []byte) error {
+	schema := reg.payloads[typ][version]
+	if schema == nil {
+		return fmt.Errorf("%w: %s v%d", ErrUnknownEvent, typ, version)
+	}
+	return validate(schema, payload, fmt.Sprintf("%s v%d", typ, version))
+}
+
+func validate(schema *jsonschema.Schema, doc []byte, name string) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %s is not json: %s", ErrInvalidEvent, name, err)
+	}
+
+	if err := schema.Validate(v); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidEvent, name, err)
+	}
+	return nil
+}
@@ -14,8 +14,10 @@ type Config struct {
 	PgPort         string `mapstructure:"PGPORT"`
 	PgUser         string `mapstructure:"PGUSER"`
 	Port           string `mapstructure:"PORT"`
+	OTLPEndpoint   string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	Debug          bool   `mapstructure:"DEBUG"`
 	RabbitMQ       string `mapstructure:"RABBITMQ"`
+	VoucherURL     string `mapstructure:"VOUCHER_URL"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -72,7 +72,7 @@ func (h *handler) ShowOrders(ctx *gin.Context) {
 	userIDString := ctx.GetHeader("user-id")
 	idUser := ctx.Query("user_id")
 	var numi int
-	
+
 	if idUser != "" {
 		if userIDString != "" {
 			num, err := strconv.Atoi(userIDString)
@@ -98,13 +98,27 @@ func (h *handler) ShowOrders(ctx *gin.Context) {
 	}
 }
 
+func (h *handler) QuoteOrders(ctx *gin.Context) {
+	var data model.GetOrders
+
+	err := ctx.ShouldBindJSON(&data)
+	failerror.FailError(err, "error bind json")
+
+	res, err := h.svc.QuoteOrders(data)
+	if err != nil {
+		response.ResponseError(ctx, res.Status, err)
+	} else {
+		response.ResponseSuccess(ctx, res.Status, res.Data)
+	}
+}
+
 func (h *handler) CreateOrders(ctx *gin.Context) {
 	var data model.GetOrders
 
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.CreateOrders(data)
+	res, err := h.svc.CreateOrders(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
@@ -119,10 +133,60 @@ func (h *handler) UpdateOrders(ctx *gin.Context) {
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.UpdateOrders(data)
+	res, err := h.svc.UpdateOrders(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
 		response.ResponseSuccess(ctx, res.Status, res.Data)
 	}
 }
+
+// UpdateOwnOrders lets a customer move one of their own orders, the user is the one the
+// gateway authenticated.
+func (h *handler) UpdateOwnOrders(ctx *gin.Context) {
+	var data model.OrderUpd
+
+	err := ctx.ShouldBindJSON(&data)
+	failerror.FailError(err, "error bind json")
+
+	res, err := h.svc.UpdateOwnOrders(ctx.Request.Context(), gatewayUserID(ctx), data)
+	if err != nil {
+		response.ResponseError(ctx, res.Status, err)
+	} else {
+		response.ResponseSuccess(ctx, res.Status, res.Data)
+	}
+}
+
+// GetOrderHistory is the history of one of the customer's own orders.
+func (h *handler) GetOrderHistory(ctx *gin.Context) {
+	orderNumber := ctx.Query("order_number")
+
+	res, err := h.svc.GetOrderHistory(gatewayUserID(ctx), orderNumber)
+	if err != nil {
+		response.ResponseError(ctx, res.Status, err)
+	} else {
+		response.ResponseSuccess(ctx, res.Status, res.Data)
+	}
+}
+
+// GetAnyOrderHistory is the history of any order, for admins.
+func (h *handler) GetAnyOrderHistory(ctx *gin.Context) {
+	orderNumber := ctx.Query("order_number")
+
+	res, err := h.svc.GetAnyOrderHistory(orderNumber)
+	if err != nil {
+		response.ResponseError(ctx, res.Status, err)
+	} else {
+		response.ResponseSuccess(ctx, res.Status, res.Data)
+	}
+}
+
+// gatewayUserID is the user the gateway authenticated the request for, 0 when there is
+// none.
+func gatewayUserID(ctx *gin.Context) int {
+	userID, err := strconv.Atoi(ctx.GetHeader("user-id"))
+	if err != nil {
+		return 0
+	}
+	return userID
+}
@@ -5,7 +5,11 @@ import "github.com/gin-gonic/gin"
 type Handlerer interface {
 	GetOrders(c *gin.Context)
 	GetOrdersByStoreID(ctx *gin.Context)
+	QuoteOrders(c *gin.Context)
 	CreateOrders(c *gin.Context)
 	ShowOrders(c *gin.Context)
 	UpdateOrders(c *gin.Context)
+	UpdateOwnOrders(c *gin.Context)
+	GetOrderHistory(c *gin.Context)
+	GetAnyOrderHistory(c *gin.Context)
 }
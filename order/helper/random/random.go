@@ -1,8 +1,8 @@
 package random
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"math/big"
 )
 
 type random struct {
@@ -16,13 +16,18 @@ type Random interface {
 	RandomString() (int, error)
 }
 
+// RandomString draws from crypto/rand so concurrent orders never share an order number.
 func (r *random) RandomString() string {
-	randomizer := rand.New(rand.NewSource(time.Now().Unix()))
 	letters := []rune("qwertyuioplkjhgfdsazxcvbnmQWERTYUIOPLKJHGFDSAZXCVBNM1234567890")
+	max := big.NewInt(int64(len(letters)))
 
 	b := make([]rune, 10)
 	for i := range b {
-		b[i] = letters[randomizer.Intn(len(letters))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = letters[n.Int64()]
 	}
 
 	return string(b)
@@ -2,6 +2,7 @@ package timeout
 
 import (
 	"context"
+	"telemetry-go"
 	"time"
 )
 
@@ -9,3 +10,10 @@ func NewCtxTimeout() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	return ctx, cancel
 }
+
+// NewCtxTimeoutFrom is NewCtxTimeout carrying on the trace of parent, the client going
+// away does not cancel it.
+func NewCtxTimeoutFrom(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(telemetry.Detach(parent), 10*time.Second)
+	return ctx, cancel
+}
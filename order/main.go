@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"order-go/config"
 	"order-go/handler"
 	"order-go/helper/failerror"
@@ -10,6 +11,8 @@ import (
 	"order-go/publisher"
 	"order-go/repository"
 	"order-go/service"
+	"order-go/voucher"
+	"telemetry-go"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -22,14 +25,24 @@ func main() {
 
 	logger := logging.New(conf.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "order", conf.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	db, err := db.NewGormDB(conf.Debug, conf.DatabaseDriver, conf.DatabaseURL)
 	failerror.FailError(err, "error new gorm")
 	logger.Debug().Msg("DB Connected")
 
-	pub := publisher.NewPublisher()
-
-	repoProduct := repository.NewRepository(db.SQLDB, pub)
-	product := service.NewService(repoProduct)
+	// orders are written by consumer-worker, every request waits for its reply
+	repoProduct := repository.NewRepository(db.SQLDB, publisher.NewPublisher())
+	voucherClient := voucher.NewVoucher(conf.VoucherURL)
+	product := service.NewService(repoProduct, voucherClient)
 	Handler := handler.NewHandler(product)
 
 	NewServer(Handler, logger)
@@ -41,6 +54,8 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 
 	// server
 	r := gin.New()
+	r.Use(telemetry.Middleware("order"))
+	r.GET("/metrics", gin.WrapH(telemetry.Handler()))
 
 	// middleware
 	r.Use(middleware.Logger(logger))
@@ -48,11 +63,15 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 	r.GET("/orders", hand.GetOrders)
 	r.GET("/orders/stores", hand.GetOrdersByStoreID)
 	r.GET("/orders/details", hand.ShowOrders)
+	r.GET("/orders/history", hand.GetOrderHistory)
 	r.POST("/orders", hand.CreateOrders)
+	r.POST("/orders/quote", hand.QuoteOrders)
 	r.POST("/orders/item", hand.CreateOrders)
+	r.PATCH("/orders", hand.UpdateOwnOrders)
 
 	admin := r.Group("/admin")
 	admin.PATCH("/orders", hand.UpdateOrders)
+	admin.GET("/orders/history", hand.GetAnyOrderHistory)
 
 	r.Run(":" + conf.Port)
 }
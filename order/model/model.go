@@ -2,12 +2,29 @@ package model
 
 import "time"
 
+const (
+	StatusPendingPayment = "pending_payment"
+	StatusPaid           = "paid"
+	StatusPacked         = "packed"
+	StatusShipped        = "shipped"
+	StatusDelivered      = "delivered"
+	StatusCompleted      = "completed"
+	StatusCancelled      = "cancelled"
+	StatusRefunded       = "refunded"
+
+	StatusPartiallyRefunded = "partially_refunded"
+)
+
 type Orders struct {
 	Id            int       `json:"id"`
 	UserID        int       `json:"user_id"`
 	ShippingID    int       `json:"shipping_id"`
+	Subtotal      float64   `json:"subtotal"`
+	Discount      float64   `json:"discount"`
+	ShippingFee   float64   `json:"shipping_fee"`
+	VoucherCode   string    `json:"voucher_code"`
 	TotalPrice    float64   `json:"total_price"`
-	Status        bool      `json:"status"`
+	Status        string    `json:"status"`
 	OrderNumber   string    `json:"order_number"`
 	ReceiptNumber string    `json:"receipt_number"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -23,24 +40,28 @@ type OrderItem struct {
 }
 
 type OrdersByStore struct {
-	Id            int       `json:"id"`
-	UserID        int       `json:"user_id"`
-	ShippingID    int       `json:"shipping_id"`
-	Status        bool      `json:"status"`
-	OrderNumber   string    `json:"order_number"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	StoreID		  int		`json:"store_id"`
-	Quantity      int       `json:"quantity"`
-	TotalPrice    int		`json:"total_price"`
+	Id          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	ShippingID  int       `json:"shipping_id"`
+	Status      string    `json:"status"`
+	OrderNumber string    `json:"order_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	StoreID     int       `json:"store_id"`
+	Quantity    int       `json:"quantity"`
+	TotalPrice  int       `json:"total_price"`
 }
 
 type ResultOrders struct {
 	Id            int            `json:"id"`
 	UserID        int            `json:"user_id"`
 	ShippingID    int            `json:"shipping_id"`
+	Subtotal      float64        `json:"subtotal"`
+	Discount      float64        `json:"discount"`
+	ShippingFee   float64        `json:"shipping_fee"`
+	VoucherCode   string         `json:"voucher_code"`
 	TotalPrice    float64        `json:"total_price"`
-	Status        bool           `json:"status"`
+	Status        string         `json:"status"`
 	OrderNumber   string         `json:"order_number"`
 	ReceiptNumber string         `json:"receipt_number"`
 	OrderItemReq  []OrderItemReq `json:"order_items"`
@@ -48,32 +69,91 @@ type ResultOrders struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+// GetOrders is what the client sends, prices are always computed by the order service.
 type GetOrders struct {
 	UserID       int            `json:"user_id"`
 	ShippingID   int            `json:"shipping_id"`
-	TotalPrice   float64        `json:"total_price"`
+	VoucherCode  string         `json:"voucher_code"`
 	OrderItemReq []OrderItemReq `json:"order_items"`
 }
 
 type OrderItemReq struct {
 	ProductId  int     `json:"product_id"`
 	Quantity   int     `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
 	TotalPrice float64 `json:"total_price"`
 }
 
+// Quote is a priced order, the same numbers are stored on the order as a snapshot.
+type Quote struct {
+	UserID       int            `json:"user_id"`
+	ShippingID   int            `json:"shipping_id"`
+	OrderItemReq []OrderItemReq `json:"order_items"`
+	Subtotal     float64        `json:"subtotal"`
+	Discount     float64        `json:"discount"`
+	ShippingFee  float64        `json:"shipping_fee"`
+	VoucherCode  string         `json:"voucher_code"`
+	TotalPrice   float64        `json:"total_price"`
+}
+
+type ProductPrice struct {
+	Id         int
+	StoreID    int
+	CategoryID int
+	UnitPrice  float64
+}
+
+type Voucher struct {
+	Id         int       `json:"id"`
+	StoreID    int       `json:"store_id"`
+	ProductID  int       `json:"product_id"`
+	CategoryID int       `json:"category_id"`
+	Discount   float64   `json:"discount_value"`
+	Name       string    `json:"name"`
+	Code       string    `json:"code"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+}
+
 type GetOrdersSent struct {
 	UserID       int            `json:"user_id"`
 	ShippingID   int            `json:"shipping_id"`
+	Subtotal     float64        `json:"subtotal"`
+	Discount     float64        `json:"discount"`
+	ShippingFee  float64        `json:"shipping_fee"`
+	VoucherCode  string         `json:"voucher_code"`
 	TotalPrice   float64        `json:"total_price"`
-	Status       bool           `json:"status"`
+	Status       string         `json:"status"`
 	OrderNumber  string         `json:"order_number"`
 	OrderItemReq []OrderItemReq `json:"order_items"`
 }
 
+// MessageID identifies the create_order message, an order number is only ever placed once.
+func (o GetOrdersSent) MessageID() string {
+	return o.OrderNumber
+}
+
 type OrderUpd struct {
 	OrderNumber   string `json:"order_number"`
-	Status        bool   `json:"status"`
+	Status        string `json:"status"`
+	PrevStatus    string `json:"prev_status"`
 	ReceiptNumber string `json:"receipt_number"`
+	Note          string `json:"note"`
+}
+
+type OrderStatusHistory struct {
+	Id         int       `json:"id"`
+	OrderID    int       `json:"order_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrderReply struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Order   Orders `json:"order"`
 }
 
 type OrderItems struct {
@@ -1,17 +1,35 @@
 package publisher
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"events-go"
 	"log"
 	"order-go/config"
-	"order-go/helper/failerror"
-	"order-go/helper/timeout"
+	"telemetry-go"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// producer names this service in the envelope of its events.
+const producer = "order"
+
+// directReplyTo is the RabbitMQ pseudo queue used for request/reply without declaring a reply queue.
+const directReplyTo = "amq.rabbitmq.reply-to"
+
+var ErrReplyTimeout = errors.New("timed out waiting for consumer reply")
+
+// messageIDer is implemented by requests that carry their own event id, like
+// model.GetOrdersSent, so consumers can recognise a message that is delivered twice.
+type messageIDer interface {
+	MessageID() string
+}
+
 type Publisher interface {
-	Public(req any, queueName string) error
+	Request(ctx context.Context, req any, queueName string) ([]byte, error)
 }
 
 type publisher struct{}
@@ -20,19 +38,25 @@ func NewPublisher() Publisher {
 	return &publisher{}
 }
 
-func (p publisher) Public(req any, queueName string) error {
-	ctx, cancel := timeout.NewCtxTimeout()
-	defer cancel()
-
+// Request publishes req to queueName and blocks until the consumer replies with the same
+// correlation id, or returns ErrReplyTimeout once ctx is done.
+func (p publisher) Request(ctx context.Context, req any, queueName string) ([]byte, error) {
 	config, err := config.LoadConfig()
-	failerror.FailError(err, "error config")
+	if err != nil {
+		return nil, err
+	}
 
 	conn, err := amqp.Dial(config.RabbitMQ)
-	failerror.FailError(err, "error connect to rabbitmq")
+	if err != nil {
+		return nil, err
+	}
 	defer conn.Close()
 
 	ch, err := conn.Channel()
-	failerror.FailError(err, "failed to open a channel")
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
 
 	q, err := ch.QueueDeclare(
 		queueName, // queue name
@@ -42,25 +66,86 @@ func (p publisher) Public(req any, queueName string) error {
 		false,     // no-wait
 		nil,       // arguments
 	)
-	failerror.FailError(err, "failed to declare queue")
+	if err != nil {
+		return nil, err
+	}
 
-	// marshal data to jsonByte
-	jsonByte, err := json.Marshal(req)
-	failerror.FailError(err, "failed to marshaling")
+	// must consume from the reply pseudo queue before publishing on the same channel
+	replies, err := ch.Consume(
+		directReplyTo, // queue
+		"",            // consumer
+		true,          // auto-ack
+		false,         // exclusive
+		false,         // no-local
+		false,         // no-wait
+		nil,           // args
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	correlationID, err := newCorrelationID()
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := events.New(queueName, producer, req)
+	if err != nil {
+		return nil, err
+	}
+	env.CorrelationID = correlationID
+	if m, ok := req.(messageIDer); ok && m.MessageID() != "" {
+		env.ID = m.MessageID()
+	}
 
-	err = ch.PublishWithContext(ctx,
+	jsonByte, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// the consumer continues the trace of the request from the message headers
+	pubCtx, span := telemetry.StartPublish(ctx, q.Name)
+	err = ch.PublishWithContext(pubCtx,
 		"",     // exchange
 		q.Name, // routing key
 		false,  // mandatory
 		false,  // immediate
 		amqp.Publishing{
-			DeliveryMode: amqp.Persistent,
-			ContentType:  "text/plain",
-			Body:         jsonByte,
+			Headers:       telemetry.Inject(pubCtx, nil),
+			DeliveryMode:  amqp.Persistent,
+			ContentType:   events.ContentType,
+			Type:          env.Type,
+			CorrelationId: correlationID,
+			MessageId:     env.ID,
+			ReplyTo:       directReplyTo,
+			Body:          jsonByte,
 		})
-	failerror.FailError(err, "failed to publish message")
+	telemetry.EndPublish(span, q.Name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf(" [x] Sent %s with correlation id %s", req, correlationID)
 
-	log.Printf(" [x] Sent %s", req)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ErrReplyTimeout
+		case d, ok := <-replies:
+			if !ok {
+				return nil, errors.New("reply channel closed")
+			}
+			if d.CorrelationId == correlationID {
+				return d.Body, nil
+			}
+		}
+	}
+}
 
-	return nil
+func newCorrelationID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
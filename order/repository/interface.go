@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"order-go/model"
 )
 
@@ -8,6 +9,10 @@ type Repositorier interface {
 	GetOrders(userID int) ([]model.Orders, error)
 	GetOrdersByStoreID(storeID int) ([]model.OrdersByStore, error)
 	ShowOrders(req model.OrderItems) (model.ResultOrders, error)
-	CreateOrders(req model.GetOrders) (model.Orders, error)
-	UpdateOrders(req model.OrderUpd) (model.Orders, error)
+	CreateOrders(ctx context.Context, req model.GetOrdersSent) (model.Orders, error)
+	UpdateOrders(ctx context.Context, req model.OrderUpd) (model.Orders, error)
+	GetOrderByNumber(orderNumber string) (model.Orders, error)
+	GetOrderHistory(orderNumber string) ([]model.OrderStatusHistory, error)
+	GetProductPrices(productIDs []int) (map[int]model.ProductPrice, error)
+	GetShippingFee(shippingID int) (float64, error)
 }
@@ -1,16 +1,20 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"order-go/helper/failerror"
 	"order-go/helper/random"
 	"order-go/helper/timeout"
 	"order-go/model"
 	"order-go/publisher"
-	"time"
 )
 
+var ErrOrderRejected = errors.New("order rejected")
+
 type repository struct {
 	db   *sql.DB
 	sent publisher.Publisher
@@ -27,7 +31,7 @@ func (repo *repository) GetOrders(userID int) ([]model.Orders, error) {
 	ctx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
-	querySelect := `select * from orders where user_id = $1`
+	querySelect := `select id, user_id, shipping_id, coalesce(subtotal, 0), coalesce(discount, 0), coalesce(shipping_fee, 0), coalesce(voucher_code, ''), total_price, status, order_number, coalesce(receipt_number, ''), created_at, updated_at from orders where user_id = $1`
 
 	result, err := repo.db.QueryContext(ctx, querySelect, userID)
 	if err != nil {
@@ -38,7 +42,7 @@ func (repo *repository) GetOrders(userID int) ([]model.Orders, error) {
 
 	for result.Next() {
 		var temp model.Orders
-		result.Scan(&temp.Id, &temp.UserID, &temp.ShippingID, &temp.TotalPrice, &temp.Status, &temp.CreatedAt, &temp.UpdatedAt, &temp.OrderNumber, &temp.ReceiptNumber)
+		result.Scan(&temp.Id, &temp.UserID, &temp.ShippingID, &temp.Subtotal, &temp.Discount, &temp.ShippingFee, &temp.VoucherCode, &temp.TotalPrice, &temp.Status, &temp.OrderNumber, &temp.ReceiptNumber, &temp.CreatedAt, &temp.UpdatedAt)
 		data = append(data, temp)
 	}
 
@@ -73,7 +77,7 @@ func (repo *repository) ShowOrders(req model.OrderItems) (model.ResultOrders, er
 
 	var result model.ResultOrders
 
-	queryProduct := `select oi.product_id, oi.quantity, oi.total_price from order_items oi join orders o on o.id = oi.order_id where o.order_number = $1 and o.user_id = $2`
+	queryProduct := `select oi.product_id, oi.quantity, coalesce(oi.unit_price, 0), oi.total_price from order_items oi join orders o on o.id = oi.order_id where o.order_number = $1 and o.user_id = $2`
 
 	rows, err := repo.db.QueryContext(ctx, queryProduct, req.OrderNumber, req.UserId)
 	failerror.FailError(err, "error query")
@@ -81,21 +85,25 @@ func (repo *repository) ShowOrders(req model.OrderItems) (model.ResultOrders, er
 	var dataProduct = []model.OrderItemReq{}
 	for rows.Next() {
 		var temp model.OrderItemReq
-		err := rows.Scan(&temp.ProductId, &temp.Quantity, &temp.TotalPrice)
+		err := rows.Scan(&temp.ProductId, &temp.Quantity, &temp.UnitPrice, &temp.TotalPrice)
 		failerror.FailError(err, "error scan")
 
 		dataProduct = append(dataProduct, temp)
 	}
 
-	queryOrder := `select id, user_id, shipping_id , total_price ,status , order_number,created_at ,updated_at, receipt_number from orders o where o.order_number = $1 and o.user_id = $2`
+	queryOrder := `select id, user_id, shipping_id, coalesce(subtotal, 0), coalesce(discount, 0), coalesce(shipping_fee, 0), coalesce(voucher_code, ''), total_price ,status , order_number,created_at ,updated_at, coalesce(receipt_number, '') from orders o where o.order_number = $1 and o.user_id = $2`
 
 	var dataOrder model.Orders
-	repo.db.QueryRowContext(ctx, queryOrder, req.OrderNumber, req.UserId).Scan(&dataOrder.Id, &dataOrder.UserID, &dataOrder.ShippingID, &dataOrder.TotalPrice, &dataOrder.Status, &dataOrder.OrderNumber, &dataOrder.CreatedAt, &dataOrder.UpdatedAt, &dataOrder.ReceiptNumber)
+	repo.db.QueryRowContext(ctx, queryOrder, req.OrderNumber, req.UserId).Scan(&dataOrder.Id, &dataOrder.UserID, &dataOrder.ShippingID, &dataOrder.Subtotal, &dataOrder.Discount, &dataOrder.ShippingFee, &dataOrder.VoucherCode, &dataOrder.TotalPrice, &dataOrder.Status, &dataOrder.OrderNumber, &dataOrder.CreatedAt, &dataOrder.UpdatedAt, &dataOrder.ReceiptNumber)
 
 	result = model.ResultOrders{
 		Id:            dataOrder.Id,
 		UserID:        dataOrder.UserID,
 		ShippingID:    dataOrder.ShippingID,
+		Subtotal:      dataOrder.Subtotal,
+		Discount:      dataOrder.Discount,
+		ShippingFee:   dataOrder.ShippingFee,
+		VoucherCode:   dataOrder.VoucherCode,
 		TotalPrice:    dataOrder.TotalPrice,
 		Status:        dataOrder.Status,
 		OrderNumber:   dataOrder.OrderNumber,
@@ -112,57 +120,133 @@ func (repo *repository) ShowOrders(req model.OrderItems) (model.ResultOrders, er
 	return result, nil
 }
 
-func (repo *repository) CreateOrders(req model.GetOrders) (model.Orders, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
+func (repo *repository) CreateOrders(ctx context.Context, req model.GetOrdersSent) (model.Orders, error) {
+	ctx, cancel := timeout.NewCtxTimeoutFrom(ctx)
 	defer cancel()
 
-	inRandom := model.GetOrdersSent{
-		UserID:       req.UserID,
-		ShippingID:   req.ShippingID,
-		TotalPrice:   req.TotalPrice,
-		Status:       false,
-		OrderNumber:  random.NewRandom().RandomString(),
-		OrderItemReq: req.OrderItemReq,
-	}
+	req.Status = model.StatusPendingPayment
+	req.OrderNumber = random.NewRandom().RandomString()
+
+	return repo.request(ctx, req, "create_order")
+}
+
+func (repo *repository) UpdateOrders(ctx context.Context, req model.OrderUpd) (model.Orders, error) {
+	ctx, cancel := timeout.NewCtxTimeoutFrom(ctx)
+	defer cancel()
+
+	return repo.request(ctx, req, "update_order")
+}
 
-	err := repo.sent.Public(inRandom, "create_order")
+// request publishes req and waits for the consumer to confirm the write.
+func (repo *repository) request(ctx context.Context, req any, queueName string) (model.Orders, error) {
+	body, err := repo.sent.Request(ctx, req, queueName)
 	if err != nil {
+		if errors.Is(err, publisher.ErrReplyTimeout) {
+			return model.Orders{}, err
+		}
 		return model.Orders{}, errors.New("failed publisher")
 	}
 
-	time.Sleep(3 * time.Second)
+	var reply model.OrderReply
+	err = json.Unmarshal(body, &reply)
+	if err != nil {
+		return model.Orders{}, errors.New("invalid consumer reply")
+	}
+
+	if !reply.Success {
+		return model.Orders{}, fmt.Errorf("%w: %s", ErrOrderRejected, reply.Error)
+	}
 
-	query := `select id,user_id ,shipping_id ,total_price ,status ,order_number ,created_at ,updated_at  from orders where order_number = $1`
+	return reply.Order, nil
+}
 
-	var temp model.Orders
-	repo.db.QueryRowContext(ctx, query, inRandom.OrderNumber).Scan(&temp.Id, &temp.UserID, &temp.ShippingID, &temp.TotalPrice, &temp.Status, &temp.OrderNumber, &temp.CreatedAt, &temp.UpdatedAt)
+func (repo *repository) GetOrderByNumber(orderNumber string) (model.Orders, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	queryOrder := `select id, user_id, shipping_id, coalesce(subtotal, 0), coalesce(discount, 0), coalesce(shipping_fee, 0), coalesce(voucher_code, ''), total_price, status, order_number, created_at, updated_at, coalesce(receipt_number, '') from orders where order_number = $1`
 
-	if temp.Id == 0 {
-		return temp, errors.New("error create order")
+	var temp model.Orders
+	err := repo.db.QueryRowContext(ctx, queryOrder, orderNumber).Scan(&temp.Id, &temp.UserID, &temp.ShippingID, &temp.Subtotal, &temp.Discount, &temp.ShippingFee, &temp.VoucherCode, &temp.TotalPrice, &temp.Status, &temp.OrderNumber, &temp.CreatedAt, &temp.UpdatedAt, &temp.ReceiptNumber)
+	if err == sql.ErrNoRows {
+		return model.Orders{}, errors.New("order not found")
+	}
+	if err != nil {
+		return model.Orders{}, errors.New("error get data")
 	}
 
 	return temp, nil
 }
 
-func (repo *repository) UpdateOrders(req model.OrderUpd) (model.Orders, error) {
+func (repo *repository) GetOrderHistory(orderNumber string) ([]model.OrderStatusHistory, error) {
 	ctx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
-	err := repo.sent.Public(req, "update_order")
+	querySelect := `select h.id, h.order_id, coalesce(h.from_status, ''), h.to_status, coalesce(h.note, ''), h.created_at from order_status_histories h join orders o on o.id = h.order_id where o.order_number = $1 order by h.created_at, h.id`
+
+	result, err := repo.db.QueryContext(ctx, querySelect, orderNumber)
 	if err != nil {
-		return model.Orders{}, errors.New("failed publisher")
+		return []model.OrderStatusHistory{}, errors.New("error get data")
 	}
+	defer result.Close()
 
-	time.Sleep(3 * time.Second)
+	var data = []model.OrderStatusHistory{}
 
-	queryOrder := `select id, user_id, shipping_id , total_price ,status , order_number,created_at ,updated_at,receipt_number from orders where order_number = $1`
+	for result.Next() {
+		var temp model.OrderStatusHistory
+		err := result.Scan(&temp.Id, &temp.OrderID, &temp.FromStatus, &temp.ToStatus, &temp.Note, &temp.CreatedAt)
+		if err != nil {
+			return []model.OrderStatusHistory{}, errors.New("error scan data")
+		}
+		data = append(data, temp)
+	}
 
-	var temp model.Orders
-	repo.db.QueryRowContext(ctx, queryOrder, req.OrderNumber).Scan(&temp.Id, &temp.UserID, &temp.ShippingID, &temp.TotalPrice, &temp.Status, &temp.OrderNumber, &temp.CreatedAt, &temp.UpdatedAt, &temp.ReceiptNumber)
+	return data, nil
+}
+
+func (repo *repository) GetProductPrices(productIDs []int) (map[int]model.ProductPrice, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	querySelect := `select id, coalesce(store_id, 0), coalesce(category_id, 0), coalesce(unit_price, 0) from products where id = $1`
 
-	if temp.Id == 0 {
-		return model.Orders{}, errors.New("error update order")
+	stmt, err := repo.db.PrepareContext(ctx, querySelect)
+	if err != nil {
+		return nil, errors.New("error get data")
+	}
+	defer stmt.Close()
+
+	var data = map[int]model.ProductPrice{}
+
+	for _, id := range productIDs {
+		var temp model.ProductPrice
+		err := stmt.QueryRowContext(ctx, id).Scan(&temp.Id, &temp.StoreID, &temp.CategoryID, &temp.UnitPrice)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, errors.New("error get data")
+		}
+		data[temp.Id] = temp
 	}
 
-	return temp, nil
+	return data, nil
+}
+
+func (repo *repository) GetShippingFee(shippingID int) (float64, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	querySelect := `select coalesce(fee, 0) from shippings where id = $1`
+
+	var fee float64
+	err := repo.db.QueryRowContext(ctx, querySelect, shippingID).Scan(&fee)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("shipping not found")
+	}
+	if err != nil {
+		return 0, errors.New("error get data")
+	}
+
+	return fee, nil
 }
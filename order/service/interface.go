@@ -1,13 +1,18 @@
 package service
 
 import (
+	"context"
 	"order-go/model"
 )
 
 type Servicer interface {
 	GetOrders(idUser int) (model.Respon, error)
 	GetOrdersByStoreID(storeID int) (model.Respon, error)
-	CreateOrders(req model.GetOrders) (model.Respon, error)
+	QuoteOrders(req model.GetOrders) (model.Respon, error)
+	CreateOrders(ctx context.Context, req model.GetOrders) (model.Respon, error)
 	ShowOrders(req model.OrderItems) (model.Respon, error)
-	UpdateOrders(req model.OrderUpd) (model.Respon, error)
+	UpdateOrders(ctx context.Context, req model.OrderUpd) (model.Respon, error)
+	UpdateOwnOrders(ctx context.Context, userID int, req model.OrderUpd) (model.Respon, error)
+	GetOrderHistory(userID int, orderNumber string) (model.Respon, error)
+	GetAnyOrderHistory(orderNumber string) (model.Respon, error)
 }
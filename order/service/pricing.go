@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"order-go/model"
+	"time"
+)
+
+// priceOrder computes the order from catalogue prices, ignoring anything the client claims.
+// The voucher discount_value is a percentage applied to the lines it is scoped to: a single
+// product, every product of a store or of a category, or the whole order when unscoped.
+func priceOrder(req model.GetOrders, products map[int]model.ProductPrice, voucher *model.Voucher, shippingFee float64, now time.Time) (model.Quote, error) {
+	quote := model.Quote{
+		UserID:      req.UserID,
+		ShippingID:  req.ShippingID,
+		ShippingFee: roundPrice(shippingFee),
+	}
+
+	var eligible float64
+	for _, v := range req.OrderItemReq {
+		product, ok := products[v.ProductId]
+		if !ok {
+			return model.Quote{}, fmt.Errorf("product %d not found", v.ProductId)
+		}
+		if product.UnitPrice <= 0 {
+			return model.Quote{}, fmt.Errorf("product %d has no price", v.ProductId)
+		}
+
+		line := model.OrderItemReq{
+			ProductId:  v.ProductId,
+			Quantity:   v.Quantity,
+			UnitPrice:  product.UnitPrice,
+			TotalPrice: roundPrice(product.UnitPrice * float64(v.Quantity)),
+		}
+		quote.OrderItemReq = append(quote.OrderItemReq, line)
+		quote.Subtotal += line.TotalPrice
+
+		if voucher != nil && voucherApplies(*voucher, product) {
+			eligible += line.TotalPrice
+		}
+	}
+
+	if voucher != nil {
+		if now.Before(voucher.StartDate) || now.After(voucher.EndDate) {
+			return model.Quote{}, fmt.Errorf("voucher %s is not active", voucher.Code)
+		}
+		if eligible == 0 {
+			return model.Quote{}, fmt.Errorf("voucher %s does not apply to this order", voucher.Code)
+		}
+
+		percent := math.Min(math.Max(voucher.Discount, 0), 100)
+		quote.VoucherCode = voucher.Code
+		quote.Discount = roundPrice(eligible * percent / 100)
+	}
+
+	quote.Subtotal = roundPrice(quote.Subtotal)
+	quote.TotalPrice = roundPrice(quote.Subtotal - quote.Discount + quote.ShippingFee)
+
+	return quote, nil
+}
+
+func voucherApplies(voucher model.Voucher, product model.ProductPrice) bool {
+	switch {
+	case voucher.ProductID != 0:
+		return voucher.ProductID == product.Id
+	case voucher.StoreID != 0:
+		return voucher.StoreID == product.StoreID
+	case voucher.CategoryID != 0:
+		return voucher.CategoryID == product.CategoryID
+	default:
+		return true
+	}
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"order-go/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceOrder(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	products := map[int]model.ProductPrice{
+		1: {Id: 1, StoreID: 10, CategoryID: 100, UnitPrice: 50000},
+		2: {Id: 2, StoreID: 20, CategoryID: 200, UnitPrice: 12500},
+		3: {Id: 3, StoreID: 20, CategoryID: 200},
+	}
+	req := model.GetOrders{
+		UserID:     1,
+		ShippingID: 1,
+		OrderItemReq: []model.OrderItemReq{
+			{ProductId: 1, Quantity: 2, TotalPrice: 1},
+			{ProductId: 2, Quantity: 4, UnitPrice: 1},
+		},
+	}
+	activeVoucher := func(v model.Voucher) *model.Voucher {
+		v.Code = "PROMO"
+		v.StartDate = now.Add(-time.Hour)
+		v.EndDate = now.Add(time.Hour)
+		return &v
+	}
+
+	test_price := []struct {
+		name         string
+		req          model.GetOrders
+		voucher      *model.Voucher
+		wantSubtotal float64
+		wantDiscount float64
+		wantTotal    float64
+		wantErr      bool
+	}{
+		{
+			name:         "client prices are ignored",
+			req:          req,
+			wantSubtotal: 150000,
+			wantTotal:    159000,
+		}, {
+			name:         "voucher scoped to a store",
+			req:          req,
+			voucher:      activeVoucher(model.Voucher{StoreID: 20, Discount: 10}),
+			wantSubtotal: 150000,
+			wantDiscount: 5000,
+			wantTotal:    154000,
+		}, {
+			name:         "voucher scoped to a product",
+			req:          req,
+			voucher:      activeVoucher(model.Voucher{StoreID: 10, ProductID: 1, Discount: 50}),
+			wantSubtotal: 150000,
+			wantDiscount: 50000,
+			wantTotal:    109000,
+		}, {
+			name:    "voucher for another store",
+			req:     req,
+			voucher: activeVoucher(model.Voucher{StoreID: 30, Discount: 10}),
+			wantErr: true,
+		}, {
+			name:    "expired voucher",
+			req:     req,
+			voucher: &model.Voucher{Code: "OLD", Discount: 10, StartDate: now.Add(-48 * time.Hour), EndDate: now.Add(-24 * time.Hour)},
+			wantErr: true,
+		}, {
+			name:    "unknown product",
+			req:     model.GetOrders{OrderItemReq: []model.OrderItemReq{{ProductId: 9, Quantity: 1}}},
+			wantErr: true,
+		}, {
+			name:    "product without price",
+			req:     model.GetOrders{OrderItemReq: []model.OrderItemReq{{ProductId: 3, Quantity: 1}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range test_price {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := priceOrder(tt.req, products, tt.voucher, 9000, now)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSubtotal, quote.Subtotal)
+			require.Equal(t, tt.wantDiscount, quote.Discount)
+			require.Equal(t, tt.wantTotal, quote.TotalPrice)
+			require.Equal(t, 100000.0, quote.OrderItemReq[0].TotalPrice)
+			require.Equal(t, 12500.0, quote.OrderItemReq[1].UnitPrice)
+		})
+	}
+}
@@ -1,19 +1,26 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"order-go/model"
+	"order-go/publisher"
 	"order-go/repository"
+	"order-go/voucher"
+	"time"
 )
 
 type service struct {
-	repo repository.Repositorier
+	repo    repository.Repositorier
+	voucher voucher.Voucher
 }
 
-func NewService(repo repository.Repositorier) Servicer {
+func NewService(repo repository.Repositorier, voucher voucher.Voucher) Servicer {
 	return &service{
-		repo: repo,
+		repo:    repo,
+		voucher: voucher,
 	}
 }
 
@@ -61,28 +68,114 @@ func (svc *service) GetOrdersByStoreID(storeID int) (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateOrders(req model.GetOrders) (model.Respon, error) {
+func (svc *service) QuoteOrders(req model.GetOrders) (model.Respon, error) {
+	quote, status, err := svc.quote(req)
+	if err != nil {
+		return model.Respon{
+			Status: status,
+			Data:   nil,
+		}, err
+	}
+	return model.Respon{
+		Status: http.StatusOK,
+		Data:   quote,
+	}, nil
+}
+
+func (svc *service) CreateOrders(ctx context.Context, req model.GetOrders) (model.Respon, error) {
+	quote, status, err := svc.quote(req)
+	if err != nil {
+		return model.Respon{
+			Status: status,
+			Data:   nil,
+		}, err
+	}
+
+	sent := model.GetOrdersSent{
+		UserID:       quote.UserID,
+		ShippingID:   quote.ShippingID,
+		Subtotal:     quote.Subtotal,
+		Discount:     quote.Discount,
+		ShippingFee:  quote.ShippingFee,
+		VoucherCode:  quote.VoucherCode,
+		TotalPrice:   quote.TotalPrice,
+		OrderItemReq: quote.OrderItemReq,
+	}
+
+	// start
+	res, err := svc.repo.CreateOrders(ctx, sent)
+	if err != nil {
+		return model.Respon{
+			Status: errorStatus(err),
+			Data:   nil,
+		}, err
+	}
+	return model.Respon{
+		Status: http.StatusOK,
+		Data:   res,
+	}, nil
+}
+
+// quote validates the requested items and prices them, the returned int is the http status on error.
+func (svc *service) quote(req model.GetOrders) (model.Quote, int, error) {
 	var check int
 
-	if req.UserID == 0 || req.ShippingID == 0 || req.TotalPrice == 0 || req.OrderItemReq == nil || len(req.OrderItemReq) == 0 {
+	if req.UserID == 0 || req.ShippingID == 0 || req.OrderItemReq == nil || len(req.OrderItemReq) == 0 {
 		check++
 	}
 
+	var productIDs []int
 	for _, v := range req.OrderItemReq {
-		if v.ProductId <= 0 || v.Quantity <= 0 || v.TotalPrice <= 0 {
+		if v.ProductId <= 0 || v.Quantity <= 0 {
 			check++
 		}
+		productIDs = append(productIDs, v.ProductId)
 	}
 
 	if check != 0 {
+		return model.Quote{}, http.StatusBadRequest, errors.New("invalid input or item null")
+	}
+
+	products, err := svc.repo.GetProductPrices(productIDs)
+	if err != nil {
+		return model.Quote{}, http.StatusInternalServerError, err
+	}
+
+	shippingFee, err := svc.repo.GetShippingFee(req.ShippingID)
+	if err != nil {
+		return model.Quote{}, http.StatusBadRequest, err
+	}
+
+	var discount *model.Voucher
+	if req.VoucherCode != "" {
+		v, err := svc.voucher.ShowVoucher(req.VoucherCode)
+		if errors.Is(err, voucher.ErrVoucherNotFound) {
+			return model.Quote{}, http.StatusBadRequest, err
+		}
+		if err != nil {
+			return model.Quote{}, http.StatusBadGateway, err
+		}
+		discount = &v
+	}
+
+	quote, err := priceOrder(req, products, discount, shippingFee, time.Now())
+	if err != nil {
+		return model.Quote{}, http.StatusBadRequest, err
+	}
+
+	return quote, http.StatusOK, nil
+}
+
+func (svc *service) ShowOrders(req model.OrderItems) (model.Respon, error) {
+	if req.OrderNumber == "" || req.UserId <= 0 {
 		return model.Respon{
 			Status: http.StatusBadRequest,
 			Data:   nil,
-		}, errors.New("invalid input or item null")
+		}, errors.New("invalid input")
 	}
 
 	// start
-	res, err := svc.repo.CreateOrders(req)
+	res, err := svc.repo.ShowOrders(req)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
@@ -95,19 +188,80 @@ func (svc *service) CreateOrders(req model.GetOrders) (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) ShowOrders(req model.OrderItems) (model.Respon, error) {
-	if req.OrderNumber == "" || req.UserId <= 0 {
+func (svc *service) UpdateOrders(ctx context.Context, req model.OrderUpd) (model.Respon, error) {
+	if req.OrderNumber == "" || !IsValidStatus(req.Status) {
+		return model.Respon{
+			Status: http.StatusBadRequest,
+			Data:   nil,
+		}, errors.New("invalid input")
+	}
+
+	current, err := svc.repo.GetOrderByNumber(req.OrderNumber)
+	if err != nil {
+		return model.Respon{
+			Status: http.StatusNotFound,
+			Data:   nil,
+		}, err
+	}
+
+	if !CanTransition(current.Status, req.Status) {
+		return model.Respon{
+			Status: http.StatusConflict,
+			Data:   nil,
+		}, fmt.Errorf("cannot change order status from %s to %s", current.Status, req.Status)
+	}
+
+	return svc.updateStatus(ctx, req, current)
+}
+
+// UpdateOwnOrders is a customer moving their own order, only to a status a customer may
+// move it to, like cancelling it before it is paid. Someone else's order is not found.
+func (svc *service) UpdateOwnOrders(ctx context.Context, userID int, req model.OrderUpd) (model.Respon, error) {
+	if userID <= 0 {
+		return model.Respon{
+			Status: http.StatusUnauthorized,
+			Data:   nil,
+		}, errors.New("missing user")
+	}
+	if req.OrderNumber == "" || !IsValidStatus(req.Status) {
 		return model.Respon{
 			Status: http.StatusBadRequest,
 			Data:   nil,
 		}, errors.New("invalid input")
 	}
 
+	current, err := svc.repo.GetOrderByNumber(req.OrderNumber)
+	if err != nil || current.UserID != userID {
+		return model.Respon{
+			Status: http.StatusNotFound,
+			Data:   nil,
+		}, errors.New("order not found")
+	}
+
+	if !CanCustomerTransition(current.Status, req.Status) {
+		return model.Respon{
+			Status: http.StatusForbidden,
+			Data:   nil,
+		}, fmt.Errorf("cannot change order status from %s to %s", current.Status, req.Status)
+	}
+
+	return svc.updateStatus(ctx, req, current)
+}
+
+func (svc *service) updateStatus(ctx context.Context, req model.OrderUpd, current model.Orders) (model.Respon, error) {
+	if req.Status == model.StatusShipped && req.ReceiptNumber == "" {
+		return model.Respon{
+			Status: http.StatusBadRequest,
+			Data:   nil,
+		}, errors.New("receipt number is required to ship an order")
+	}
+	req.PrevStatus = current.Status
+
 	// start
-	res, err := svc.repo.ShowOrders(req)
+	res, err := svc.repo.UpdateOrders(ctx, req)
 	if err != nil {
 		return model.Respon{
-			Status: http.StatusInternalServerError,
+			Status: errorStatus(err),
 			Data:   nil,
 		}, err
 	}
@@ -117,9 +271,36 @@ func (svc *service) ShowOrders(req model.OrderItems) (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) UpdateOrders(req model.OrderUpd) (model.Respon, error) {
+// GetOrderHistory is the status history of one of the user's orders, someone else's order
+// is not found.
+func (svc *service) GetOrderHistory(userID int, orderNumber string) (model.Respon, error) {
+	if userID <= 0 {
+		return model.Respon{
+			Status: http.StatusUnauthorized,
+			Data:   nil,
+		}, errors.New("missing user")
+	}
+	if orderNumber == "" {
+		return model.Respon{
+			Status: http.StatusBadRequest,
+			Data:   nil,
+		}, errors.New("invalid input")
+	}
 
-	if req.OrderNumber == "" {
+	order, err := svc.repo.GetOrderByNumber(orderNumber)
+	if err != nil || order.UserID != userID {
+		return model.Respon{
+			Status: http.StatusNotFound,
+			Data:   nil,
+		}, errors.New("order not found")
+	}
+
+	return svc.GetAnyOrderHistory(orderNumber)
+}
+
+// GetAnyOrderHistory is the status history of any order, for admins.
+func (svc *service) GetAnyOrderHistory(orderNumber string) (model.Respon, error) {
+	if orderNumber == "" {
 		return model.Respon{
 			Status: http.StatusBadRequest,
 			Data:   nil,
@@ -127,7 +308,7 @@ func (svc *service) UpdateOrders(req model.OrderUpd) (model.Respon, error) {
 	}
 
 	// start
-	res, err := svc.repo.UpdateOrders(req)
+	res, err := svc.repo.GetOrderHistory(orderNumber)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
@@ -139,3 +320,15 @@ func (svc *service) UpdateOrders(req model.OrderUpd) (model.Respon, error) {
 		Data:   res,
 	}, nil
 }
+
+// errorStatus maps a failed order write to the http status returned to the client.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, publisher.ErrReplyTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, repository.ErrOrderRejected):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
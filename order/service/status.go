@@ -0,0 +1,54 @@
+package service
+
+import "order-go/model"
+
+// transitions lists, for every order status, the statuses it may move to next.
+// Statuses without an entry are terminal. A partially refunded order carries on with
+// fulfilment, and may be partially refunded again, so it can move to any later step.
+var transitions = map[string][]string{
+	model.StatusPendingPayment: {model.StatusPaid, model.StatusCancelled},
+	model.StatusPaid:           {model.StatusPacked, model.StatusCancelled, model.StatusRefunded, model.StatusPartiallyRefunded},
+	model.StatusPacked:         {model.StatusShipped, model.StatusCancelled, model.StatusRefunded, model.StatusPartiallyRefunded},
+	model.StatusShipped:        {model.StatusDelivered},
+	model.StatusDelivered:      {model.StatusCompleted, model.StatusRefunded, model.StatusPartiallyRefunded},
+
+	model.StatusPartiallyRefunded: {model.StatusPacked, model.StatusShipped, model.StatusDelivered, model.StatusCompleted,
+		model.StatusRefunded, model.StatusPartiallyRefunded},
+}
+
+// customerTransitions are the moves a customer may make on their own order, the rest
+// are the store's or the payment service's.
+var customerTransitions = map[string][]string{
+	model.StatusPendingPayment: {model.StatusCancelled},
+	model.StatusDelivered:      {model.StatusCompleted},
+}
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case model.StatusPendingPayment, model.StatusPaid, model.StatusPacked, model.StatusShipped,
+		model.StatusDelivered, model.StatusCompleted, model.StatusCancelled, model.StatusRefunded,
+		model.StatusPartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCustomerTransition tells whether a customer may move their own order from one status to
+// the other.
+func CanCustomerTransition(from, to string) bool {
+	for _, next := range customerTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"order-go/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	test_transition := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{name: "pay pending order", from: model.StatusPendingPayment, to: model.StatusPaid, want: true},
+		{name: "cancel pending order", from: model.StatusPendingPayment, to: model.StatusCancelled, want: true},
+		{name: "pack paid order", from: model.StatusPaid, to: model.StatusPacked, want: true},
+		{name: "ship packed order", from: model.StatusPacked, to: model.StatusShipped, want: true},
+		{name: "deliver shipped order", from: model.StatusShipped, to: model.StatusDelivered, want: true},
+		{name: "complete delivered order", from: model.StatusDelivered, to: model.StatusCompleted, want: true},
+		{name: "refund paid order", from: model.StatusPaid, to: model.StatusRefunded, want: true},
+		{name: "refund delivered order", from: model.StatusDelivered, to: model.StatusRefunded, want: true},
+		{name: "refund shipped order", from: model.StatusShipped, to: model.StatusRefunded, want: false},
+		{name: "partially refund delivered order", from: model.StatusDelivered, to: model.StatusPartiallyRefunded, want: true},
+		{name: "partially refund again", from: model.StatusPartiallyRefunded, to: model.StatusPartiallyRefunded, want: true},
+		{name: "ship partially refunded order", from: model.StatusPartiallyRefunded, to: model.StatusShipped, want: true},
+		{name: "partially refund shipped order", from: model.StatusShipped, to: model.StatusPartiallyRefunded, want: false},
+		{name: "ship unpaid order", from: model.StatusPendingPayment, to: model.StatusShipped, want: false},
+		{name: "cancel shipped order", from: model.StatusShipped, to: model.StatusCancelled, want: false},
+		{name: "reopen cancelled order", from: model.StatusCancelled, to: model.StatusPendingPayment, want: false},
+		{name: "move completed order", from: model.StatusCompleted, to: model.StatusRefunded, want: false},
+		{name: "same status", from: model.StatusPaid, to: model.StatusPaid, want: false},
+		{name: "unknown status", from: "true", to: model.StatusPaid, want: false},
+	}
+	for _, tt := range test_transition {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CanTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	require.True(t, IsValidStatus(model.StatusDelivered))
+	require.False(t, IsValidStatus(""))
+	require.False(t, IsValidStatus("false"))
+}
+
+func TestCanCustomerTransition(t *testing.T) {
+	test_transition := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{name: "cancel pending order", from: model.StatusPendingPayment, to: model.StatusCancelled, want: true},
+		{name: "complete delivered order", from: model.StatusDelivered, to: model.StatusCompleted, want: true},
+		{name: "pay pending order", from: model.StatusPendingPayment, to: model.StatusPaid, want: false},
+		{name: "cancel paid order", from: model.StatusPaid, to: model.StatusCancelled, want: false},
+		{name: "refund delivered order", from: model.StatusDelivered, to: model.StatusRefunded, want: false},
+		{name: "ship packed order", from: model.StatusPacked, to: model.StatusShipped, want: false},
+	}
+	for _, tt := range test_transition {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CanCustomerTransition(tt.from, tt.to))
+		})
+	}
+}
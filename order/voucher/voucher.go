@@ -0,0 +1,71 @@
+package voucher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"order-go/helper/timeout"
+	"order-go/model"
+)
+
+var ErrVoucherNotFound = errors.New("voucher not found")
+
+// ErrVoucherUnavailable is a voucher service that didn't answer, or answered with an error
+// of its own. The code may be fine, the customer can try again.
+var ErrVoucherUnavailable = errors.New("voucher service unavailable")
+
+type Voucher interface {
+	ShowVoucher(code string) (model.Voucher, error)
+}
+
+type voucher struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewVoucher(baseURL string) Voucher {
+	return &voucher{
+		baseURL: baseURL,
+		client:  &http.Client{},
+	}
+}
+
+// ShowVoucher looks the code up on the voucher service.
+func (v *voucher) ShowVoucher(code string) (model.Voucher, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	if v.baseURL == "" {
+		return model.Voucher{}, errors.New("voucher service is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL+"/voucher/details?code="+url.QueryEscape(code), nil)
+	if err != nil {
+		return model.Voucher{}, err
+	}
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return model.Voucher{}, fmt.Errorf("%w: %s", ErrVoucherUnavailable, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return model.Voucher{}, ErrVoucherNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return model.Voucher{}, fmt.Errorf("%w: status %d", ErrVoucherUnavailable, res.StatusCode)
+	}
+
+	var body struct {
+		Data model.Voucher
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return model.Voucher{}, errors.New("invalid voucher response")
+	}
+
+	return body.Data, nil
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"events-go"
+	"fmt"
+	"telemetry-go"
+	"time"
+)
+
+// Outbox keeps events in the outbox table instead of sending them straight to RabbitMQ,
+// Run relays them once the broker is reachable.
+type Outbox struct {
+	db     *sql.DB
+	url    string
+	source string
+	wake   chan struct{}
+}
+
+// New relays the events written by source, the service name, to the broker at url.
+func New(db *sql.DB, url, source string) *Outbox {
+	return &Outbox{
+		db:     db,
+		url:    url,
+		source: source,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Publish stores the event, it is relayed right away rather than on the next poll. The
+// trace context of ctx is stored with it so the consumer continues the request's trace.
+func (o *Outbox) Publish(ctx context.Context, body any, queueName string) error {
+	ctx, cancel := context.WithTimeout(telemetry.Detach(ctx), 5*time.Second)
+	defer cancel()
+
+	err := o.enqueue(ctx, o.db, body, queueName)
+	if err != nil {
+		return err
+	}
+
+	o.Wake()
+	return nil
+}
+
+// PublishTx stores the event within tx, it is only relayed once tx commits and never when
+// it rolls back. Call Wake after the commit to relay it right away rather than on the next
+// poll.
+func (o *Outbox) PublishTx(ctx context.Context, tx *sql.Tx, body any, queueName string) error {
+	return o.enqueue(ctx, tx, body, queueName)
+}
+
+// Wake tells the relay there are events to send.
+func (o *Outbox) Wake() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// execer is the *sql.DB or *sql.Tx the event is written with.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (o *Outbox) enqueue(ctx context.Context, db execer, body any, queueName string) error {
+	env, err := events.New(queueName, o.source, body)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("error marshal")
+	}
+
+	traceHeaders, err := json.Marshal(telemetry.Inject(ctx, nil))
+	if err != nil {
+		return fmt.Errorf("error marshal")
+	}
+
+	query := `insert into outbox (source, queue, payload, trace_headers) values ($1, $2, $3, $4)`
+
+	_, err = db.ExecContext(ctx, query, o.source, queueName, payload, traceHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox : %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"events-go"
+	"fmt"
+	"log"
+	"telemetry-go"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	relayInterval = time.Second
+	relayBatch    = 100
+	maxBackoff    = 5 * time.Minute
+)
+
+type outboxEvent struct {
+	id       int64
+	queue    string
+	payload  []byte
+	attempts int
+	// traceHeaders is the trace context of the request that stored the event, if any
+	traceHeaders []byte
+}
+
+// Run relays pending events until ctx is done. Every event is published with a publisher
+// confirm and only marked sent once the broker acks it, failed events are retried with
+// exponential backoff. Delivery is at least once, a consumer may see an event twice.
+func (o *Outbox) Run(ctx context.Context) {
+	attempts := 0
+	for {
+		connected, err := o.relay(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			attempts = 0
+		}
+		attempts++
+
+		wait := backoff(attempts)
+		log.Printf("outbox relay: %v, reconnecting in %s", err, wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relay publishes on one connection until it breaks, connected tells whether it was opened.
+func (o *Outbox) relay(ctx context.Context) (connected bool, err error) {
+	conn, err := amqp.Dial(o.url)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to RabbitMQ : %v", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, fmt.Errorf("failed to open a channel : %v", err)
+	}
+	defer ch.Close()
+
+	err = ch.Confirm(false)
+	if err != nil {
+		return false, fmt.Errorf("failed to enable publisher confirms : %v", err)
+	}
+
+	declared := map[string]bool{}
+
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := o.flush(ctx, ch, declared)
+			if err != nil {
+				return true, err
+			}
+			if n < relayBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// flush publishes one batch of due events. Rows are locked with skip locked so replicas
+// of the same service relay different events.
+func (o *Outbox) flush(ctx context.Context, ch *amqp.Channel, declared map[string]bool) (int, error) {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `select id, queue, payload, attempts, trace_headers from outbox
+	where source = $1 and sent_at is null and next_attempt_at <= now()
+	order by id limit $2 for update skip locked`
+
+	rows, err := tx.QueryContext(ctx, query, o.source, relayBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	events := []outboxEvent{}
+	for rows.Next() {
+		var e outboxEvent
+		err := rows.Scan(&e.id, &e.queue, &e.payload, &e.attempts, &e.traceHeaders)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		err := o.publish(ctx, ch, declared, e)
+		if err != nil {
+			_, err = tx.ExecContext(ctx, `update outbox set attempts = attempts + 1, last_error = $2,
+			next_attempt_at = now() + $3 * interval '1 second' where id = $1`,
+				e.id, err.Error(), backoff(e.attempts+1).Seconds())
+		} else {
+			_, err = tx.ExecContext(ctx, `update outbox set sent_at = now() where id = $1`, e.id)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	if ch.IsClosed() {
+		return len(events), amqp.ErrClosed
+	}
+
+	return len(events), nil
+}
+
+// publish sends e within the trace of the request that stored it, the publish span and
+// the consumer's span are children of the request's span.
+func (o *Outbox) publish(ctx context.Context, ch *amqp.Channel, declared map[string]bool, e outboxEvent) (err error) {
+	var h map[string]interface{}
+	if len(e.traceHeaders) > 0 {
+		_ = json.Unmarshal(e.traceHeaders, &h)
+	}
+	ctx, span := telemetry.StartPublish(telemetry.Extract(ctx, h), e.queue)
+	defer func() { telemetry.EndPublish(span, e.queue, err) }()
+
+	if !declared[e.queue] {
+		_, err := ch.QueueDeclare(
+			e.queue, // queue name
+			true,    // durable
+			false,   // auto delete queue when unused
+			false,   // exclusive
+			false,   // no-wait
+			nil,     // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare a queue : %v", err)
+		}
+		declared[e.queue] = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// events written before the envelope have no id of their own
+	messageID := fmt.Sprintf("%s-%d", o.source, e.id)
+	var env events.Envelope
+	if json.Unmarshal(e.payload, &env) == nil && env.ID != "" {
+		messageID = env.ID
+	}
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		"",      // exchange
+		e.queue, // routing key
+		false,   // mandatory
+		false,   // immediate
+		amqp.Publishing{
+			Headers:      telemetry.Inject(ctx, nil),
+			DeliveryMode: amqp.Persistent,
+			ContentType:  events.ContentType,
+			Type:         e.queue,
+			MessageId:    messageID,
+			Body:         e.payload,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish a message : %v", err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no publisher confirm : %v", err)
+	}
+	if !acked {
+		return errors.New("message nacked by broker")
+	}
+
+	log.Printf(" [x] Sent outbox event %d to %s", e.id, e.queue)
+	return nil
+}
+
+// backoff doubles from one second for every failed attempt, up to maxBackoff.
+func backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 10 {
+		return maxBackoff
+	}
+	wait := time.Second << (attempts - 1)
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
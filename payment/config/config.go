@@ -1,16 +1,33 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Debug 		bool   `mapstructure:"DEBUG"`
 	Port  		string `mapstructure:"PORT"`
-	RabbitMQURL string `mapstructure:"RABBITMQURL"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	RabbitMQURL string `mapstructure:"RABBITMQ_URL"`
 
 	Database `mapstructure:",squash"`
 	Midtrans `mapstructure:",squash"`
+	Gateway  `mapstructure:",squash"`
+	Sweeper  `mapstructure:",squash"`
+}
+
+// Sweeper cancels orders still unpaid PAYMENT_TTL after they were placed, 0 turns it off.
+type Sweeper struct {
+	PaymentTTL    time.Duration `mapstructure:"PAYMENT_TTL"`
+	SweepInterval time.Duration `mapstructure:"SWEEP_INTERVAL"`
+}
+
+type Gateway struct {
+	Name          string `mapstructure:"PAYMENT_GATEWAY"`
+	FakeOutcome   string `mapstructure:"FAKE_GATEWAY_OUTCOME"`
+	FakeNotifyURL string `mapstructure:"FAKE_GATEWAY_NOTIFY_URL"`
 }
 
 type Midtrans struct {
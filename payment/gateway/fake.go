@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"payment-go/model"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSettle  = "settlement"
+	OutcomeExpire  = "expire"
+	OutcomeDeny    = "deny"
+	OutcomePending = "pending"
+)
+
+const (
+	statusRefund        = "refund"
+	statusPartialRefund = "partial_refund"
+)
+
+var ErrNotRefundable = errors.New("transaction can't be refunded")
+
+// statusCodes are the status_code midtrans sends along with each transaction status.
+var statusCodes = map[string]string{
+	OutcomeSettle:       "200",
+	OutcomePending:      "201",
+	OutcomeDeny:         "202",
+	OutcomeExpire:       "407",
+	statusRefund:        "200",
+	statusPartialRefund: "200",
+}
+
+// Fake is an in-process PaymentGateway for local runs and CI. Every payment ends with the
+// outcome scripted for its order, or the default outcome, instead of waiting on a customer.
+type Fake struct {
+	mu       sync.Mutex
+	outcome  string
+	scripted map[string]string
+	payments map[string]model.PaymentStatusResponse
+	refunded map[string]int64
+	refunds  map[string]model.RefundResponse
+	notify   func(model.PaymentStatusResponse) error
+}
+
+func NewFake(outcome string) *Fake {
+	if _, ok := statusCodes[outcome]; !ok {
+		outcome = OutcomeSettle
+	}
+
+	return &Fake{
+		outcome:  outcome,
+		scripted: map[string]string{},
+		payments: map[string]model.PaymentStatusResponse{},
+		refunded: map[string]int64{},
+		refunds:  map[string]model.RefundResponse{},
+	}
+}
+
+// Script sets the outcome of the next payment created for orderID.
+func (f *Fake) Script(orderID, outcome string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.scripted[orderID] = outcome
+}
+
+// OnOutcome is called in the background with the final status of every payment that does not
+// stay pending, the way midtrans would post a notification.
+func (f *Fake) OnOutcome(notify func(model.PaymentStatusResponse) error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.notify = notify
+}
+
+func (f *Fake) CreatePayment(req model.PaymentRequest) (model.PaymentResponse, error) {
+	f.mu.Lock()
+	outcome, ok := f.scripted[req.OrderID]
+	if !ok {
+		outcome = f.outcome
+	}
+	delete(f.scripted, req.OrderID)
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	status := model.PaymentStatusResponse{
+		TransactionTime:   now,
+		GrossAmount:       fmt.Sprintf("%d.00", req.GrossAmount),
+		Currency:          "IDR",
+		OrderID:           req.OrderID,
+		PaymentType:       "fake",
+		TransactionID:     fmt.Sprintf("fake-%s-%d", req.OrderID, time.Now().UnixNano()),
+		TransactionStatus: outcome,
+		StatusMessage:     "fake gateway " + outcome,
+	}
+	if outcome == OutcomeSettle {
+		status.SettlementTime = now
+	}
+	f.payments[req.OrderID] = status
+	notify := f.notify
+	f.mu.Unlock()
+
+	if notify != nil && outcome != OutcomePending {
+		go func() {
+			if err := notify(status); err != nil {
+				log.Printf("fake gateway notify order %s: %v", req.OrderID, err)
+			}
+		}()
+	}
+
+	return model.PaymentResponse{
+		Token:       status.TransactionID,
+		RedirectURL: "http://fake-gateway.local/pay/" + status.TransactionID,
+	}, nil
+}
+
+func (f *Fake) CheckPayment(orderID string) (model.PaymentStatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.payments[orderID]
+	if !ok {
+		return model.PaymentStatusResponse{}, ErrTransactionNotFound
+	}
+
+	return status, nil
+}
+
+func (f *Fake) ExpirePayment(orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.payments[orderID]
+	if !ok {
+		return ErrTransactionNotFound
+	}
+	if status.TransactionStatus != OutcomePending {
+		return fmt.Errorf("transaction is %s", status.TransactionStatus)
+	}
+
+	status.TransactionStatus = OutcomeExpire
+	status.StatusMessage = "fake gateway " + OutcomeExpire
+	f.payments[orderID] = status
+
+	return nil
+}
+
+// Refund refunds a settled payment, the transaction moves to partial_refund until the
+// whole gross amount is refunded. A refund key used before returns the first refund.
+func (f *Fake) Refund(req model.RefundRequest) (model.RefundResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if res, ok := f.refunds[req.IdempotencyKey]; ok {
+		return res, nil
+	}
+
+	status, ok := f.payments[req.OrderID]
+	if !ok {
+		return model.RefundResponse{}, ErrTransactionNotFound
+	}
+	switch status.TransactionStatus {
+	case OutcomeSettle, statusPartialRefund:
+	default:
+		return model.RefundResponse{}, fmt.Errorf("%w: transaction is %s", ErrNotRefundable, status.TransactionStatus)
+	}
+
+	gross, _ := strconv.ParseFloat(status.GrossAmount, 64)
+	refunded := f.refunded[req.OrderID] + req.Amount
+	if req.Amount <= 0 || float64(refunded) > gross {
+		return model.RefundResponse{}, fmt.Errorf("%w: refund amount %d exceeds what is left", ErrNotRefundable, req.Amount)
+	}
+
+	status.TransactionStatus = statusPartialRefund
+	if float64(refunded) == gross {
+		status.TransactionStatus = statusRefund
+	}
+	status.StatusMessage = "fake gateway " + status.TransactionStatus
+	f.payments[req.OrderID] = status
+	f.refunded[req.OrderID] = refunded
+
+	res := model.RefundResponse{
+		OrderID:           req.OrderID,
+		RefundKey:         req.IdempotencyKey,
+		TransactionID:     status.TransactionID,
+		Amount:            req.Amount,
+		TransactionStatus: status.TransactionStatus,
+	}
+	f.refunds[req.IdempotencyKey] = res
+
+	return res, nil
+}
+
+// StatusCode returns the midtrans status_code for a transaction status.
+func StatusCode(transactionStatus string) string {
+	return statusCodes[transactionStatus]
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"payment-go/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeOutcome(t *testing.T) {
+	test_outcome := []struct {
+		name       string
+		outcome    string
+		scripted   string
+		wantStatus string
+		wantNotify bool
+	}{
+		{name: "default settle", outcome: OutcomeSettle, wantStatus: OutcomeSettle, wantNotify: true},
+		{name: "scripted expire", outcome: OutcomeSettle, scripted: OutcomeExpire, wantStatus: OutcomeExpire, wantNotify: true},
+		{name: "scripted deny", outcome: OutcomeSettle, scripted: OutcomeDeny, wantStatus: OutcomeDeny, wantNotify: true},
+		{name: "default pending", outcome: OutcomePending, wantStatus: OutcomePending},
+		{name: "unknown default falls back to settle", outcome: "paid", wantStatus: OutcomeSettle, wantNotify: true},
+	}
+	for _, tt := range test_outcome {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFake(tt.outcome)
+			if tt.scripted != "" {
+				fake.Script("10", tt.scripted)
+			}
+
+			notified := make(chan model.PaymentStatusResponse, 1)
+			fake.OnOutcome(func(status model.PaymentStatusResponse) error {
+				notified <- status
+				return nil
+			})
+
+			res, err := fake.CreatePayment(model.PaymentRequest{OrderID: "10", UserID: 1, GrossAmount: 150000})
+			require.NoError(t, err)
+			require.NotEmpty(t, res.Token)
+			require.NotEmpty(t, res.RedirectURL)
+
+			status, err := fake.CheckPayment("10")
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, status.TransactionStatus)
+			require.Equal(t, "150000.00", status.GrossAmount)
+
+			select {
+			case got := <-notified:
+				require.True(t, tt.wantNotify)
+				require.Equal(t, tt.wantStatus, got.TransactionStatus)
+			case <-time.After(100 * time.Millisecond):
+				require.False(t, tt.wantNotify)
+			}
+		})
+	}
+}
+
+func TestFakeScriptIsUsedOnce(t *testing.T) {
+	fake := NewFake(OutcomeSettle)
+	fake.Script("10", OutcomeDeny)
+
+	_, err := fake.CreatePayment(model.PaymentRequest{OrderID: "10", GrossAmount: 1000})
+	require.NoError(t, err)
+	status, _ := fake.CheckPayment("10")
+	require.Equal(t, OutcomeDeny, status.TransactionStatus)
+
+	_, err = fake.CreatePayment(model.PaymentRequest{OrderID: "10", GrossAmount: 1000})
+	require.NoError(t, err)
+	status, _ = fake.CheckPayment("10")
+	require.Equal(t, OutcomeSettle, status.TransactionStatus)
+}
+
+func TestFakeCheckUnknownPayment(t *testing.T) {
+	_, err := NewFake(OutcomeSettle).CheckPayment("404")
+	require.ErrorIs(t, err, ErrTransactionNotFound)
+}
+
+func TestFakeRefund(t *testing.T) {
+	fake := NewFake(OutcomeSettle)
+	_, err := fake.CreatePayment(model.PaymentRequest{OrderID: "10", GrossAmount: 1000})
+	require.NoError(t, err)
+
+	res, err := fake.Refund(model.RefundRequest{OrderID: "10", Amount: 400, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.Equal(t, "partial_refund", res.TransactionStatus)
+
+	// the same key doesn't refund again
+	res, err = fake.Refund(model.RefundRequest{OrderID: "10", Amount: 400, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(400), res.Amount)
+
+	_, err = fake.Refund(model.RefundRequest{OrderID: "10", Amount: 700, IdempotencyKey: "key-2"})
+	require.ErrorIs(t, err, ErrNotRefundable)
+
+	res, err = fake.Refund(model.RefundRequest{OrderID: "10", Amount: 600, IdempotencyKey: "key-3"})
+	require.NoError(t, err)
+	require.Equal(t, "refund", res.TransactionStatus)
+
+	status, _ := fake.CheckPayment("10")
+	require.Equal(t, "refund", status.TransactionStatus)
+}
+
+func TestFakeRefundUnsettledPayment(t *testing.T) {
+	fake := NewFake(OutcomePending)
+	_, err := fake.CreatePayment(model.PaymentRequest{OrderID: "10", GrossAmount: 1000})
+	require.NoError(t, err)
+
+	_, err = fake.Refund(model.RefundRequest{OrderID: "10", Amount: 1000, IdempotencyKey: "key-1"})
+	require.ErrorIs(t, err, ErrNotRefundable)
+}
+
+func TestFakeExpirePayment(t *testing.T) {
+	fake := NewFake(OutcomePending)
+	_, err := fake.CreatePayment(model.PaymentRequest{OrderID: "10", GrossAmount: 1000})
+	require.NoError(t, err)
+
+	require.NoError(t, fake.ExpirePayment("10"))
+	status, _ := fake.CheckPayment("10")
+	require.Equal(t, OutcomeExpire, status.TransactionStatus)
+
+	require.Error(t, fake.ExpirePayment("10"))
+	require.ErrorIs(t, fake.ExpirePayment("404"), ErrTransactionNotFound)
+}
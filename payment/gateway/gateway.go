@@ -0,0 +1,22 @@
+package gateway
+
+import (
+	"errors"
+	"payment-go/model"
+)
+
+var ErrTransactionNotFound = errors.New("transaction doesn't exist")
+
+// PaymentGateway is implemented by every payment provider the payment service can use.
+// Statuses follow the midtrans transaction_status values: pending, capture, settlement,
+// deny, cancel, expire, failure, refund and partial_refund.
+type PaymentGateway interface {
+	CreatePayment(req model.PaymentRequest) (model.PaymentResponse, error)
+	// CheckPayment returns ErrTransactionNotFound when the customer never picked a payment method.
+	CheckPayment(orderID string) (model.PaymentStatusResponse, error)
+	// ExpirePayment closes a pending transaction so it can no longer be paid.
+	ExpirePayment(orderID string) error
+	// Refund gives back req.Amount of a settled transaction. Providers treat
+	// req.IdempotencyKey as the refund key so a retried refund is applied once.
+	Refund(req model.RefundRequest) (model.RefundResponse, error)
+}
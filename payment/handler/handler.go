@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"payment-go/helper/response"
 	"payment-go/model"
+	"payment-go/repository"
 	"payment-go/service"
 	"strconv"
 
@@ -44,19 +48,7 @@ func (h *handler) CheckPayment(ctx *gin.Context) {
 		response.ResponseError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	response.ResponseSuccess(ctx, http.StatusOK, model.PaymentStatusResponse{
-		TransactionTime   : res.TransactionTime,
-		GrossAmount       : res.GrossAmount,
-		Currency          : res.Currency,
-		OrderID           : res.OrderID,
-		PaymentType       : res.PaymentType,
-		TransactionID     : res.TransactionID,
-		TransactionStatus : res.TransactionStatus,
-		SettlementTime    : res.SettlementTime,
-		StatusMessage     : res.StatusMessage,
-		Acquirer          : res.Acquirer,
-		Metadata          : res.Metadata,
-	})
+	response.ResponseSuccess(ctx, http.StatusOK, res)
 }
 
 func (h *handler) CreatePaymentLog(ctx *gin.Context) {
@@ -78,15 +70,138 @@ func (h *handler) CreatePaymentLog(ctx *gin.Context) {
 		return
 	}
 
-	if req.TotalPayment <= 0 {
-		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("total payment should not be empty"))
+	res, err := h.svc.CreatePaymentLog(req)
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		response.ResponseError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrNotPayable):
+		response.ResponseError(ctx, http.StatusConflict, err)
+	case err != nil:
+		response.ResponseError(ctx, http.StatusInternalServerError, err)
+	default:
+		response.ResponseSuccess(ctx, http.StatusOK, res)
+	}
+}
+
+func (h *handler) GetPaymentLogs(ctx *gin.Context) {
+	orderID, ok := ctx.GetQuery("order_id")
+	if !ok {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("query order_id should not be empty"))
+		return
+	}
+
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil || orderIDInt <= 0 {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("order_id should be positive number"))
 		return
 	}
 
-	res, err := h.svc.CreatePaymentLog(req)
+	res, err := h.svc.GetPaymentLogs(orderIDInt)
 	if err != nil {
 		response.ResponseError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	response.ResponseSuccess(ctx, http.StatusOK, res)
-}
\ No newline at end of file
+}
+
+func (h *handler) GetPaymentLogByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("id should be positive number"))
+		return
+	}
+
+	res, err := h.svc.GetPaymentLogByID(id)
+	if errors.Is(err, repository.ErrPaymentLogNotFound) {
+		response.ResponseError(ctx, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		response.ResponseError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	response.ResponseSuccess(ctx, http.StatusOK, res)
+}
+
+func (h *handler) Refund(ctx *gin.Context) {
+	orderID, err := strconv.Atoi(ctx.Param("order_id"))
+	if err != nil || orderID <= 0 {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("order_id should be positive number"))
+		return
+	}
+
+	req := model.RefundRequest{}
+	err = ctx.ShouldBind(&req)
+	if err != nil {
+		response.ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	req.OrderID = strconv.Itoa(orderID)
+
+	if key := ctx.GetHeader("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	if req.IdempotencyKey == "" {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("idempotency key should not be empty"))
+		return
+	}
+
+	if req.Reason == "" {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("reason should not be empty"))
+		return
+	}
+
+	if req.Amount < 0 {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("amount should be positive number"))
+		return
+	}
+
+	res, err := h.svc.Refund(req)
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		response.ResponseError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrNotRefundable), errors.Is(err, service.ErrRefundKeyReused):
+		response.ResponseError(ctx, http.StatusConflict, err)
+	case errors.Is(err, service.ErrRefundAmount):
+		response.ResponseError(ctx, http.StatusUnprocessableEntity, err)
+	case err != nil:
+		response.ResponseError(ctx, http.StatusInternalServerError, err)
+	default:
+		response.ResponseSuccess(ctx, http.StatusOK, res)
+	}
+}
+
+func (h *handler) Notification(ctx *gin.Context) {
+	payload, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		response.ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	req := model.MidtransNotification{}
+	err = json.Unmarshal(payload, &req)
+	if err != nil {
+		response.ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.OrderID == "" || req.TransactionID == "" || req.TransactionStatus == "" {
+		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("order_id, transaction_id and transaction_status should not be empty"))
+		return
+	}
+
+	err = h.svc.HandleNotification(req, payload)
+	switch {
+	case errors.Is(err, service.ErrInvalidSignature):
+		response.ResponseError(ctx, http.StatusUnauthorized, err)
+	case errors.Is(err, repository.ErrOrderNotFound):
+		response.ResponseError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrAmountMismatch):
+		response.ResponseError(ctx, http.StatusUnprocessableEntity, err)
+	case err != nil:
+		response.ResponseError(ctx, http.StatusInternalServerError, err)
+	default:
+		response.ResponseSuccess(ctx, http.StatusOK, nil)
+	}
+}
@@ -7,4 +7,8 @@ import (
 type Handlerer interface {
 	CheckPayment(ctx *gin.Context)
 	CreatePaymentLog(ctx *gin.Context)
+	GetPaymentLogs(ctx *gin.Context)
+	GetPaymentLogByID(ctx *gin.Context)
+	Refund(ctx *gin.Context)
+	Notification(ctx *gin.Context)
 }
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"payment-go/helper/response"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -46,4 +48,20 @@ func Logger(logger *zerolog.Logger) gin.HandlerFunc {
 			logger.WithLevel(zerolog.InfoLevel).Msg(msg)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// RequireRole lets through only the requests of users in one of roles, the gateway
+// sends the role of the user it authenticated in the user-role header.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetHeader("user-role")
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+		response.ResponseError(c, http.StatusForbidden, errors.New("you are not allowed to do this"))
+		c.Abort()
+	}
+}
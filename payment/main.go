@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"outbox-go"
 	"payment-go/config"
+	"payment-go/gateway"
 	"payment-go/handler"
 	"payment-go/helper/logging"
 	"payment-go/helper/middleware"
 	midtransRepo "payment-go/midtrans"
 	"payment-go/package/db"
+	"payment-go/package/lock"
 	"payment-go/repository"
 	"payment-go/server"
 	"payment-go/service"
+	"payment-go/sweeper"
+	"telemetry-go"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +34,16 @@ func main() {
 
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "payment", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
 		logger.Fatal().Err(errDB).Msg("db failed to connect")
@@ -52,19 +68,54 @@ func main() {
 	c.New(ServerKey, midtrans.Sandbox)
 	s.New(ServerKey, midtrans.Sandbox)
 
-	midtrans := midtransRepo.NewMidtrans(c, s)
-	repo := repository.NewRepository(sqlDB.SQLDB, midtrans)
-	svc := service.NewService(repo)
+	// PAYMENT_GATEWAY=fake settles, expires or denies payments in process, see gateway.Fake
+	var paymentGateway gateway.PaymentGateway
+	switch config.Gateway.Name {
+	case "fake":
+		fake := gateway.NewFake(config.Gateway.FakeOutcome)
+		if config.Gateway.FakeNotifyURL != "" {
+			fake.OnOutcome(midtransRepo.NewNotifier(config.Gateway.FakeNotifyURL, ServerKey))
+		}
+		paymentGateway = fake
+		logger.Warn().Msgf("using fake payment gateway, outcome %s", config.Gateway.FakeOutcome)
+	default:
+		paymentGateway = midtransRepo.NewMidtrans(c, s)
+	}
+
+	// events are written to the outbox table and relayed to RabbitMQ until the server shuts down
+	pub := outbox.New(sqlDB.SQLDB, config.RabbitMQURL, "payment")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pub.Run(ctx)
+
+	repo := repository.NewRepository(sqlDB.SQLDB, paymentGateway, pub)
+	svc := service.NewService(repo, ServerKey)
 	handler := handler.NewHandler(svc)
 
+	// one replica at a time sweeps unpaid orders, see sweeper.LockKey
+	if config.Sweeper.PaymentTTL > 0 {
+		interval := config.Sweeper.SweepInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		locker := lock.NewAdvisoryLock(sqlDB.SQLDB, sweeper.LockKey)
+		go sweeper.NewSweeper(svc, locker, interval, config.Sweeper.PaymentTTL, logger).Run(ctx)
+	}
+
 	// routing
 	router := gin.New()
+	router.Use(telemetry.Middleware("payment"))
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
 	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 
 	paymentLog := router.Group("/payments")
-	paymentLog.GET("/", handler.CheckPayment)
+	paymentLog.GET("/", handler.GetPaymentLogs)
+	paymentLog.GET("/:id", handler.GetPaymentLogByID)
+	paymentLog.GET("/status", handler.CheckPayment)
 	paymentLog.POST("/", handler.CreatePaymentLog)
+	paymentLog.POST("/notification", handler.Notification)
+	paymentLog.POST("/:order_id/refund", middleware.RequireRole("admin", "customer_service"), handler.Refund)
 
 	srv := &http.Server{
 		Addr:         ":" + config.Port,
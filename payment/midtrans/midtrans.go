@@ -1,7 +1,14 @@
 package midtrans
 
 import (
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"payment-go/gateway"
+	"payment-go/model"
+
+	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 )
@@ -10,31 +17,109 @@ import (
 // snapclient used for generating redirect_url to midtrans
 type Midtrans struct {
 	coreapiclient coreapi.Client
-	snapclient 	  snap.Client
+	snapclient    snap.Client
 }
 
-func NewMidtrans(coreapiclient coreapi.Client, snapclient snap.Client) MidtransInterface {
+func NewMidtrans(coreapiclient coreapi.Client, snapclient snap.Client) gateway.PaymentGateway {
 	return Midtrans{
 		coreapiclient: coreapiclient,
-		snapclient: 	snapclient,
+		snapclient:    snapclient,
 	}
 }
 
-func (m Midtrans) CheckPayment(orderID string) (*coreapi.TransactionStatusResponse, error) {
+func (m Midtrans) CheckPayment(orderID string) (model.PaymentStatusResponse, error) {
 	// get transaction status by order id in midtrans
 	resp, err := m.coreapiclient.CheckTransaction(orderID)
+	if err != nil && err.GetStatusCode() == http.StatusNotFound {
+		return model.PaymentStatusResponse{}, gateway.ErrTransactionNotFound
+	}
+	if err != nil {
+		return model.PaymentStatusResponse{}, fmt.Errorf(err.GetMessage())
+	}
+
+	return model.PaymentStatusResponse{
+		TransactionTime:   resp.TransactionTime,
+		GrossAmount:       resp.GrossAmount,
+		Currency:          resp.Currency,
+		OrderID:           resp.OrderID,
+		PaymentType:       resp.PaymentType,
+		TransactionID:     resp.TransactionID,
+		TransactionStatus: resp.TransactionStatus,
+		SettlementTime:    resp.SettlementTime,
+		StatusMessage:     resp.StatusMessage,
+		FraudStatus:       resp.FraudStatus,
+		Acquirer:          resp.Acquirer,
+		Metadata:          resp.Metadata,
+	}, nil
+}
+
+func (m Midtrans) ExpirePayment(orderID string) error {
+	_, err := m.coreapiclient.ExpireTransaction(orderID)
+	if err != nil && err.GetStatusCode() == http.StatusNotFound {
+		return gateway.ErrTransactionNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf(err.GetMessage())
+		return fmt.Errorf(err.GetMessage())
 	}
-	return resp, nil
+
+	return nil
 }
 
-func(m Midtrans) CreatePayment(req *snap.Request) (*snap.Response, error) {
+func (m Midtrans) CreatePayment(req model.PaymentRequest) (model.PaymentResponse, error) {
+	// prepare midtrans request data
+	snapReq := &snap.Request{
+		Metadata: model.Customer{
+			UserID: req.UserID,
+		},
+		EnabledPayments: []snap.SnapPaymentType{
+			snap.PaymentTypeAlfamart,
+			snap.PaymentTypeAkulaku,
+			snap.PaymentTypeBCAKlikpay,
+			snap.PaymentTypeBRIEpay,
+			snap.PaymentTypeGopay,
+			snap.PaymentTypeIndomaret,
+			snap.PaymentTypeMandiriEcash,
+		},
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: req.GrossAmount,
+		},
+	}
+
 	// create transaction in midtrans
-	resp, err := m.snapclient.CreateTransaction(req)
+	resp, err := m.snapclient.CreateTransaction(snapReq)
 	if err != nil {
-		return nil, fmt.Errorf(err.GetMessage())
+		return model.PaymentResponse{}, fmt.Errorf(err.GetMessage())
 	}
 
-	return resp, nil
-}
\ No newline at end of file
+	return model.PaymentResponse{
+		Token:       resp.Token,
+		RedirectURL: resp.RedirectURL,
+	}, nil
+}
+
+func (m Midtrans) Refund(req model.RefundRequest) (model.RefundResponse, error) {
+	resp, err := m.coreapiclient.RefundTransaction(req.OrderID, &coreapi.RefundReq{
+		RefundKey: req.IdempotencyKey,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return model.RefundResponse{}, fmt.Errorf(err.GetMessage())
+	}
+
+	return model.RefundResponse{
+		OrderID:           resp.OrderID,
+		RefundKey:         resp.RefundKey,
+		TransactionID:     resp.TransactionID,
+		Amount:            req.Amount,
+		TransactionStatus: resp.TransactionStatus,
+	}, nil
+}
+
+// SignatureKey is sha512(order_id + status_code + gross_amount + server key), see
+// https://docs.midtrans.com/docs/https-notification-webhooks
+func SignatureKey(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package midtrans
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"payment-go/gateway"
+	"payment-go/model"
+)
+
+// NewNotifier posts a transaction status to url as a signed midtrans notification. It lets the
+// fake gateway drive the real notification endpoint when the midtrans sandbox is not used.
+func NewNotifier(url, serverKey string) func(model.PaymentStatusResponse) error {
+	return func(status model.PaymentStatusResponse) error {
+		req := model.MidtransNotification{
+			TransactionTime:   status.TransactionTime,
+			TransactionStatus: status.TransactionStatus,
+			TransactionID:     status.TransactionID,
+			StatusMessage:     status.StatusMessage,
+			StatusCode:        gateway.StatusCode(status.TransactionStatus),
+			PaymentType:       status.PaymentType,
+			OrderID:           status.OrderID,
+			GrossAmount:       status.GrossAmount,
+			FraudStatus:       status.FraudStatus,
+			Currency:          status.Currency,
+			SettlementTime:    status.SettlementTime,
+		}
+		req.SignatureKey = SignatureKey(req.OrderID, req.StatusCode, req.GrossAmount, serverKey)
+
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		res, err := http.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("notification rejected with status %d", res.StatusCode)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package mocks
+
+import (
+	"payment-go/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RepositoryMock struct {
+	mock.Mock
+}
+
+func NewRepositoryMock() *RepositoryMock {
+	return &RepositoryMock{}
+}
+
+func (m *RepositoryMock) CheckPayment(orderID string) (model.PaymentStatusResponse, error) {
+	ret := m.Called(orderID)
+	result := ret.Get(0).(model.PaymentStatusResponse)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) ExpirePayment(orderID string, upd model.OrderUpd) error {
+	ret := m.Called(orderID, upd)
+	return ret.Error(0)
+}
+
+func (m *RepositoryMock) CreatePaymentLog(req model.PaymentLogRequest) (model.PaymentResponse, error) {
+	ret := m.Called(req)
+	result := ret.Get(0).(model.PaymentResponse)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) GetPaymentLogs(orderID int) ([]model.PaymentLog, error) {
+	ret := m.Called(orderID)
+	result := ret.Get(0).([]model.PaymentLog)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) GetPaymentLogByID(id int) (model.PaymentLog, error) {
+	ret := m.Called(id)
+	result := ret.Get(0).(model.PaymentLog)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) Refund(req model.RefundRequest) (model.RefundResponse, error) {
+	ret := m.Called(req)
+	result := ret.Get(0).(model.RefundResponse)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) SaveRefund(req model.RefundRequest, res model.RefundResponse, upd model.OrderUpd) error {
+	ret := m.Called(req, res, upd)
+	return ret.Error(0)
+}
+
+func (m *RepositoryMock) UpdateOrder(upd model.OrderUpd) error {
+	ret := m.Called(upd)
+	return ret.Error(0)
+}
+
+func (m *RepositoryMock) LockOrder(orderID string) (func(), error) {
+	ret := m.Called(orderID)
+	unlock, _ := ret.Get(0).(func())
+	err := ret.Error(1)
+	return unlock, err
+}
+
+func (m *RepositoryMock) GetRefundByKey(key string) (model.RefundResponse, error) {
+	ret := m.Called(key)
+	result := ret.Get(0).(model.RefundResponse)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) GetRefundedAmount(orderID string) (int64, error) {
+	ret := m.Called(orderID)
+	result := ret.Get(0).(int64)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) GetOrderByID(orderID string) (model.Order, error) {
+	ret := m.Called(orderID)
+	result := ret.Get(0).(model.Order)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) GetUnpaidOrders(before time.Time, limit int) ([]model.Order, error) {
+	ret := m.Called(before, limit)
+	result := ret.Get(0).([]model.Order)
+	err := ret.Error(1)
+	return result, err
+}
+
+func (m *RepositoryMock) DelayOrderCheck(orderID int) error {
+	ret := m.Called(orderID)
+	return ret.Error(0)
+}
+
+func (m *RepositoryMock) SaveNotification(req model.MidtransNotification, payload []byte) (int, bool, error) {
+	ret := m.Called(req, payload)
+	id := ret.Get(0).(int)
+	processed := ret.Get(1).(bool)
+	err := ret.Error(2)
+	return id, processed, err
+}
+
+func (m *RepositoryMock) MarkNotificationProcessed(id int, upd *model.OrderUpd) error {
+	ret := m.Called(id, upd)
+	return ret.Error(0)
+}
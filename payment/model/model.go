@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Customer struct {
 	UserID int `json:"user_id"`
@@ -16,14 +19,98 @@ type PaymentStatusResponse struct {
 	TransactionStatus      string          `json:"transaction_status"`
 	SettlementTime         string          `json:"settlement_time"`
 	StatusMessage          string          `json:"status_message"`
+	FraudStatus            string          `json:"fraud_status"`
 	Acquirer               string          `json:"acquirer"`
 	Metadata               interface{}     `json:"metadata"`
 }
 
+// PaymentRequest asks a payment gateway to open a transaction for an order.
+type PaymentRequest struct {
+	OrderID     string
+	UserID      int
+	GrossAmount int64
+}
+
+// PaymentResponse is where the customer is sent to pay.
+type PaymentResponse struct {
+	Token       string `json:"token"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+// PaymentLog is a snap transaction, a status check or a refund kept for reconciliation.
+type PaymentLog struct {
+	Id            int             `json:"id"`
+	OrderID       int             `json:"order_id"`
+	UserID        int             `json:"user_id"`
+	Kind          string          `json:"kind"`
+	TransactionID string          `json:"transaction_id"`
+	SnapToken     string          `json:"snap_token"`
+	RedirectURL   string          `json:"redirect_url"`
+	GrossAmount   float64         `json:"gross_amount"`
+	PaymentType   string          `json:"payment_type"`
+	Status        string          `json:"status"`
+	RefundKey     string          `json:"refund_key"`
+	RawPayload    json.RawMessage `json:"raw_payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// PaymentLogRequest opens a payment for an order, the amount charged is the order's total.
 type PaymentLogRequest struct {
 	UserID       int       `json:"user_id"`
 	OrderID      int       `json:"order_id"`
-	TotalPayment int64     `json:"total_payment"`
+	TotalPayment int64     `json:"-"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// MidtransNotification is the body midtrans posts to the payment notification url.
+type MidtransNotification struct {
+	TransactionTime   string `json:"transaction_time"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionID     string `json:"transaction_id"`
+	StatusMessage     string `json:"status_message"`
+	StatusCode        string `json:"status_code"`
+	SignatureKey      string `json:"signature_key"`
+	PaymentType       string `json:"payment_type"`
+	OrderID           string `json:"order_id"`
+	MerchantID        string `json:"merchant_id"`
+	GrossAmount       string `json:"gross_amount"`
+	FraudStatus       string `json:"fraud_status"`
+	Currency          string `json:"currency"`
+	SettlementTime    string `json:"settlement_time"`
+}
+
+type Order struct {
+	Id          int     `json:"id"`
+	OrderNumber string  `json:"order_number"`
+	Status      string  `json:"status"`
+	TotalPrice  float64 `json:"total_price"`
+}
+
+// RefundRequest gives back all or part of what was paid for an order. An empty amount
+// refunds whatever has not been refunded yet. Requests sent again with the same
+// idempotency key return the first refund instead of refunding twice.
+type RefundRequest struct {
+	OrderID        string `json:"-"`
+	Amount         int64  `json:"amount"`
+	Reason         string `json:"reason"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type RefundResponse struct {
+	OrderID           string `json:"order_id"`
+	RefundKey         string `json:"refund_key"`
+	TransactionID     string `json:"transaction_id"`
+	Amount            int64  `json:"amount"`
+	TransactionStatus string `json:"transaction_status"`
+	OrderStatus       string `json:"order_status"`
+}
+
+// OrderUpd is consumed from the update_order queue by consumer-worker.
+type OrderUpd struct {
+	OrderNumber   string `json:"order_number"`
+	Status        string `json:"status"`
+	PrevStatus    string `json:"prev_status"`
+	ReceiptNumber string `json:"receipt_number"`
+	Note          string `json:"note"`
+}
@@ -0,0 +1,51 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+)
+
+// AdvisoryLock is a postgres session level advisory lock. Every replica tries the same key,
+// the one that gets it is the leader until it unlocks or its connection drops.
+type AdvisoryLock struct {
+	db  *sql.DB
+	key int64
+}
+
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{
+		db:  db,
+		key: key,
+	}
+}
+
+// TryLock takes the lock without waiting, ok is false when another session holds it.
+// The lock lives on a dedicated connection that unlock gives back to the pool.
+func (l *AdvisoryLock) TryLock(ctx context.Context) (unlock func(), ok bool, err error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = conn.QueryRowContext(ctx, `select pg_try_advisory_lock($1)`, l.key).Scan(&ok)
+	if err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock = func() {
+		// ctx may be done by now, unlock with a fresh one
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// closing the connection drops the lock if the unlock fails
+		if _, err := conn.ExecContext(ctx, `select pg_advisory_unlock($1)`, l.key); err != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,15 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Publisher stores the events of the service in its outbox, see outbox-go. PublishTx
+// writes the event within the transaction of the rows it goes with, Wake relays it
+// once that transaction is committed.
+type Publisher interface {
+	Publish(ctx context.Context, body any, queueName string) error
+	PublishTx(ctx context.Context, tx *sql.Tx, body any, queueName string) error
+	Wake()
+}
@@ -2,12 +2,24 @@ package repository
 
 import (
 	"payment-go/model"
-
-	"github.com/midtrans/midtrans-go/coreapi"
-	"github.com/midtrans/midtrans-go/snap"
+	"time"
 )
 
 type Repositorier interface {
-	CheckPayment(orderID string) (*coreapi.TransactionStatusResponse, error)
-	CreatePaymentLog(req model.PaymentLogRequest) (res *snap.Response, err error)
-}
\ No newline at end of file
+	CheckPayment(orderID string) (model.PaymentStatusResponse, error)
+	ExpirePayment(orderID string, upd model.OrderUpd) error
+	CreatePaymentLog(req model.PaymentLogRequest) (model.PaymentResponse, error)
+	GetPaymentLogs(orderID int) ([]model.PaymentLog, error)
+	GetPaymentLogByID(id int) (model.PaymentLog, error)
+	Refund(req model.RefundRequest) (model.RefundResponse, error)
+	SaveRefund(req model.RefundRequest, res model.RefundResponse, upd model.OrderUpd) error
+	UpdateOrder(upd model.OrderUpd) error
+	LockOrder(orderID string) (unlock func(), err error)
+	GetRefundByKey(key string) (model.RefundResponse, error)
+	GetRefundedAmount(orderID string) (int64, error)
+	GetOrderByID(orderID string) (model.Order, error)
+	GetUnpaidOrders(before time.Time, limit int) ([]model.Order, error)
+	DelayOrderCheck(orderID int) error
+	SaveNotification(req model.MidtransNotification, payload []byte) (id int, processed bool, err error)
+	MarkNotificationProcessed(id int, upd *model.OrderUpd) error
+}
@@ -1,58 +1,420 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
-	midtransrepo "payment-go/midtrans"
+	"payment-go/gateway"
+	"payment-go/helper/timeout"
 	"payment-go/model"
+	"payment-go/publisher"
+	"strconv"
+	"time"
+)
+
+// orderLockClass keeps the order locks apart from other advisory locks on the same ids.
+const orderLockClass = 5006
+
+// updateOrderQueue is where consumer-worker takes the order moves from.
+const updateOrderQueue = "update_order"
 
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
-	"github.com/midtrans/midtrans-go/snap"
+var (
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrPaymentLogNotFound = errors.New("payment log not found")
+	ErrRefundNotFound     = errors.New("refund not found")
 )
 
 type repository struct {
-	db        *sql.DB
-	midtrans  midtransrepo.MidtransInterface
+	db      *sql.DB
+	gateway gateway.PaymentGateway
+	pub     publisher.Publisher
 }
 
-func NewRepository(db *sql.DB, midtrans midtransrepo.MidtransInterface) Repositorier {
+func NewRepository(db *sql.DB, gateway gateway.PaymentGateway, pub publisher.Publisher) Repositorier {
 	return &repository{
-		db:        db,
-		midtrans:  midtrans,
+		db:      db,
+		gateway: gateway,
+		pub:     pub,
+	}
+}
+
+func (repo *repository) CheckPayment(orderID string) (res model.PaymentStatusResponse, err error) {
+	res, err = repo.gateway.CheckPayment(orderID)
+	if err != nil {
+		return model.PaymentStatusResponse{}, err
+	}
+
+	orderIDInt, _ := strconv.Atoi(orderID)
+	grossAmount, _ := strconv.ParseFloat(res.GrossAmount, 64)
+	raw, _ := json.Marshal(res)
+
+	err = repo.savePaymentLog(model.PaymentLog{
+		OrderID:       orderIDInt,
+		Kind:          "status_check",
+		TransactionID: res.TransactionID,
+		GrossAmount:   grossAmount,
+		PaymentType:   res.PaymentType,
+		Status:        res.TransactionStatus,
+		RawPayload:    raw,
+	}, nil)
+	if err != nil {
+		return model.PaymentStatusResponse{}, err
+	}
+
+	return res, nil
+}
+
+// ExpirePayment closes a pending transaction at the gateway and logs it along with upd,
+// the cancel of the order.
+func (repo *repository) ExpirePayment(orderID string, upd model.OrderUpd) error {
+	err := repo.gateway.ExpirePayment(orderID)
+	if err != nil {
+		return err
+	}
+
+	orderIDInt, _ := strconv.Atoi(orderID)
+
+	return repo.savePaymentLog(model.PaymentLog{
+		OrderID:    orderIDInt,
+		Kind:       "expire",
+		Status:     "expire",
+		RawPayload: json.RawMessage("null"),
+	}, &upd)
+}
+
+func (repo *repository) CreatePaymentLog(req model.PaymentLogRequest) (res model.PaymentResponse, err error) {
+	res, err = repo.gateway.CreatePayment(model.PaymentRequest{
+		OrderID:     fmt.Sprint(req.OrderID),
+		UserID:      req.UserID,
+		GrossAmount: req.TotalPayment,
+	})
+	if err != nil {
+		return res, fmt.Errorf("error payment gateway : %v", err.Error())
+	}
+
+	raw, _ := json.Marshal(res)
+
+	err = repo.savePaymentLog(model.PaymentLog{
+		OrderID:     req.OrderID,
+		UserID:      req.UserID,
+		Kind:        "snap",
+		SnapToken:   res.Token,
+		RedirectURL: res.RedirectURL,
+		GrossAmount: float64(req.TotalPayment),
+		Status:      "pending",
+		RawPayload:  raw,
+	}, nil)
+	if err != nil {
+		return model.PaymentResponse{}, err
+	}
+
+	return
+}
+
+// savePaymentLog records log in payment_logs, with upd in the outbox when it isn't nil.
+// Both are written or neither is, a logged refund or expiry always moves the order.
+func (repo *repository) savePaymentLog(log model.PaymentLog, upd *model.OrderUpd) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	query := `insert into payment_logs (order_id, user_id, kind, transaction_id, snap_token, redirect_url, gross_amount, payment_type, status, refund_key, raw_payload)
+	values ($1, nullif($2, 0), $3, nullif($4, ''), nullif($5, ''), nullif($6, ''), $7, nullif($8, ''), $9, nullif($10, ''), $11)`
+
+	_, err = trx.ExecContext(ctx, query,
+		log.OrderID, log.UserID, log.Kind, log.TransactionID, log.SnapToken,
+		log.RedirectURL, log.GrossAmount, log.PaymentType, log.Status, log.RefundKey, []byte(log.RawPayload),
+	)
+	if err != nil {
+		return fmt.Errorf("error save payment log : %v", err)
+	}
+
+	return repo.commit(ctx, trx, upd)
+}
+
+// commit publishes upd, when it isn't nil, within trx and commits it.
+func (repo *repository) commit(ctx context.Context, trx *sql.Tx, upd *model.OrderUpd) error {
+	if upd != nil {
+		err := repo.pub.PublishTx(ctx, trx, *upd, updateOrderQueue)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := trx.Commit()
+	if err != nil {
+		return err
+	}
+
+	if upd != nil {
+		repo.pub.Wake()
+	}
+	return nil
+}
+
+const selectPaymentLog = `select id, order_id, coalesce(user_id, 0), kind, coalesce(transaction_id, ''), coalesce(snap_token, ''), coalesce(redirect_url, ''),
+	coalesce(gross_amount, 0), coalesce(payment_type, ''), coalesce(status, ''), coalesce(refund_key, ''), coalesce(raw_payload, 'null'), created_at from payment_logs`
+
+func scanPaymentLog(row interface{ Scan(dest ...any) error }) (model.PaymentLog, error) {
+	var log model.PaymentLog
+	var raw []byte
+	err := row.Scan(&log.Id, &log.OrderID, &log.UserID, &log.Kind, &log.TransactionID, &log.SnapToken, &log.RedirectURL,
+		&log.GrossAmount, &log.PaymentType, &log.Status, &log.RefundKey, &raw, &log.CreatedAt)
+	log.RawPayload = raw
+	return log, err
+}
+
+func (repo *repository) GetPaymentLogs(orderID int) ([]model.PaymentLog, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, selectPaymentLog+` where order_id = $1 order by created_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []model.PaymentLog{}
+	for rows.Next() {
+		log, err := scanPaymentLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
+func (repo *repository) GetPaymentLogByID(id int) (model.PaymentLog, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	log, err := scanPaymentLog(repo.db.QueryRowContext(ctx, selectPaymentLog+` where id = $1`, id))
+	if err == sql.ErrNoRows {
+		return model.PaymentLog{}, ErrPaymentLogNotFound
+	}
+	if err != nil {
+		return model.PaymentLog{}, err
+	}
+
+	return log, nil
+}
+
+// Refund asks the payment gateway for the refund, SaveRefund records it.
+func (repo *repository) Refund(req model.RefundRequest) (model.RefundResponse, error) {
+	res, err := repo.gateway.Refund(req)
+	if err != nil {
+		return model.RefundResponse{}, fmt.Errorf("error payment gateway : %v", err.Error())
 	}
+
+	return res, nil
 }
 
-func (repo *repository) CheckPayment(orderID string) (res *coreapi.TransactionStatusResponse, err error) {
-	return repo.midtrans.CheckPayment(orderID)
+// SaveRefund records a refund in payment_logs under its idempotency key, along with upd,
+// the order's move to refunded or partially_refunded.
+func (repo *repository) SaveRefund(req model.RefundRequest, res model.RefundResponse, upd model.OrderUpd) error {
+	orderIDInt, _ := strconv.Atoi(req.OrderID)
+	raw, _ := json.Marshal(struct {
+		Reason string `json:"reason"`
+		model.RefundResponse
+	}{req.Reason, res})
+
+	return repo.savePaymentLog(model.PaymentLog{
+		OrderID:       orderIDInt,
+		Kind:          "refund",
+		TransactionID: res.TransactionID,
+		GrossAmount:   float64(res.Amount),
+		Status:        res.TransactionStatus,
+		RefundKey:     req.IdempotencyKey,
+		RawPayload:    raw,
+	}, &upd)
 }
 
-func (repo *repository) CreatePaymentLog(req model.PaymentLogRequest) (res *snap.Response, err error) {
-	// prepare midtrans request data
-	snapReq := &snap.Request{
-		Metadata: model.Customer {
-			UserID: req.UserID,
-		},
-		EnabledPayments: []snap.SnapPaymentType{
-			snap.PaymentTypeAlfamart,
-			snap.PaymentTypeAkulaku,
-			snap.PaymentTypeBCAKlikpay,
-			snap.PaymentTypeBRIEpay,
-			snap.PaymentTypeGopay,
-			snap.PaymentTypeIndomaret,
-			snap.PaymentTypeMandiriEcash,
-		},
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  fmt.Sprint(req.OrderID),
-			GrossAmt: req.TotalPayment,
-		},
+// UpdateOrder asks consumer-worker to move an order, for the moves that come without a
+// payment log of their own.
+func (repo *repository) UpdateOrder(upd model.OrderUpd) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	return repo.pub.Publish(ctx, upd, updateOrderQueue)
+}
+
+// LockOrder takes a lock on the order for this replica and the others until unlock is called,
+// the refunds of an order are made one after the other.
+func (repo *repository) LockOrder(orderID string) (unlock func(), err error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	conn, err := repo.db.Conn(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// send data to midtrans
-	res, err = repo.midtrans.CreatePayment(snapReq)
+	_, err = conn.ExecContext(ctx, `select pg_advisory_lock($1, hashtext($2))`, orderLockClass, orderID)
 	if err != nil {
-		return res, fmt.Errorf("error midtrans : %v", err.Error())
+		conn.Close()
+		return nil, err
+	}
+
+	unlock = func() {
+		ctx, cancel := timeout.NewCtxTimeout()
+		defer cancel()
+
+		// closing the connection drops the lock if the unlock fails
+		if _, err := conn.ExecContext(ctx, `select pg_advisory_unlock($1, hashtext($2))`, orderLockClass, orderID); err != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
 	}
 
+	return unlock, nil
+}
+
+// GetRefundByKey returns the refund recorded under an idempotency key, or ErrRefundNotFound.
+func (repo *repository) GetRefundByKey(key string) (model.RefundResponse, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	log, err := scanPaymentLog(repo.db.QueryRowContext(ctx, selectPaymentLog+` where kind = 'refund' and refund_key = $1`, key))
+	if err == sql.ErrNoRows {
+		return model.RefundResponse{}, ErrRefundNotFound
+	}
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	var res model.RefundResponse
+	if err := json.Unmarshal(log.RawPayload, &res); err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	return res, nil
+}
+
+// GetRefundedAmount sums the refunds already made for an order.
+func (repo *repository) GetRefundedAmount(orderID string) (int64, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	query := `select coalesce(sum(gross_amount), 0) from payment_logs where kind = 'refund' and order_id::text = $1`
+
+	var amount float64
+	err := repo.db.QueryRowContext(ctx, query, orderID).Scan(&amount)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(amount), nil
+}
+
+func (repo *repository) GetOrderByID(orderID string) (model.Order, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	query := `select id, order_number, status, coalesce(total_price, 0) from orders where id::text = $1`
+
+	var order model.Order
+	err := repo.db.QueryRowContext(ctx, query, orderID).Scan(&order.Id, &order.OrderNumber, &order.Status, &order.TotalPrice)
+	if err == sql.ErrNoRows {
+		return model.Order{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return model.Order{}, err
+	}
+
+	return order, nil
+}
+
+// GetUnpaidOrders returns up to limit orders still waiting for payment that were created before the given time, oldest first.
+// Orders put off with DelayOrderCheck are left out until their next check.
+func (repo *repository) GetUnpaidOrders(before time.Time, limit int) ([]model.Order, error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	query := `select o.id, o.order_number, o.status, coalesce(o.total_price, 0) from orders o
+	left join payment_sweep_checks c on c.order_id = o.id
+	where o.status = 'pending_payment' and o.created_at < $1 and (c.next_check_at is null or c.next_check_at <= now())
+	order by o.created_at, o.id limit $2`
+
+	rows, err := repo.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []model.Order{}
+	for rows.Next() {
+		var order model.Order
+		err := rows.Scan(&order.Id, &order.OrderNumber, &order.Status, &order.TotalPrice)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// DelayOrderCheck puts off the next sweep of an unpaid order the sweeper couldn't settle, twice as long after every
+// attempt up to an hour.
+func (repo *repository) DelayOrderCheck(orderID int) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	query := `insert into payment_sweep_checks (order_id, attempts, next_check_at) values ($1, 1, now() + interval '1 minute')
+	on conflict (order_id) do update set attempts = payment_sweep_checks.attempts + 1,
+	next_check_at = now() + least(interval '1 minute' * power(2, payment_sweep_checks.attempts), interval '1 hour')`
+
+	_, err := repo.db.ExecContext(ctx, query, orderID)
+	return err
+}
+
+// SaveNotification stores a midtrans notification once per transaction and status.
+// A notification midtrans sends again returns the stored row, processed tells whether
+// it was already applied to the order.
+func (repo *repository) SaveNotification(req model.MidtransNotification, payload []byte) (id int, processed bool, err error) {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	query := `insert into payment_notifications (transaction_id, order_id, transaction_status, fraud_status, status_code, payment_type, gross_amount, payload)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	on conflict (transaction_id, transaction_status) do update set updated_at = now()
+	returning id, processed`
+
+	err = repo.db.QueryRowContext(ctx, query,
+		req.TransactionID, req.OrderID, req.TransactionStatus, req.FraudStatus,
+		req.StatusCode, req.PaymentType, req.GrossAmount, payload,
+	).Scan(&id, &processed)
 	return
-}
\ No newline at end of file
+}
+
+// MarkNotificationProcessed marks a notification applied, along with upd, the order move
+// it led to, when it isn't nil.
+func (repo *repository) MarkNotificationProcessed(id int, upd *model.OrderUpd) error {
+	ctx, cancel := timeout.NewCtxTimeout()
+	defer cancel()
+
+	trx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer trx.Rollback()
+
+	query := `update payment_notifications set processed = true, updated_at = now() where id = $1`
+
+	_, err = trx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return repo.commit(ctx, trx, upd)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"payment-go/gateway"
+	"payment-go/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sweepBatch caps how many orders one sweep looks at, the rest wait for the next sweep.
+const sweepBatch = 100
+
+// ExpireUnpaidOrders goes through the orders still waiting for payment that were created
+// before the given time and settles them with the gateway: orders paid without a notification
+// reaching us are marked paid, everything else is expired at the gateway and cancelled, which
+// releases its stock in consumer-worker. Orders it can't settle are checked again later. It
+// returns how many orders were moved.
+func (svc *service) ExpireUnpaidOrders(before time.Time) (int, error) {
+	orders, err := svc.repo.GetUnpaidOrders(before, sweepBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	failed := []string{}
+	for _, order := range orders {
+		ok, err := svc.expireOrder(order)
+		if ok {
+			moved++
+			continue
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("order %d: %v", order.Id, err))
+		}
+
+		// left pending, it's checked again later so it doesn't hold back the orders after it
+		err = svc.repo.DelayOrderCheck(order.Id)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("order %d: %v", order.Id, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return moved, fmt.Errorf("expire unpaid orders: %s", strings.Join(failed, "; "))
+	}
+
+	return moved, nil
+}
+
+func (svc *service) expireOrder(order model.Order) (bool, error) {
+	orderID := strconv.Itoa(order.Id)
+
+	upd := model.OrderUpd{
+		OrderNumber: order.OrderNumber,
+		Status:      orderCancelled,
+		PrevStatus:  order.Status,
+		Note:        "payment expired",
+	}
+
+	res, err := svc.repo.CheckPayment(orderID)
+	switch {
+	case errors.Is(err, gateway.ErrTransactionNotFound):
+		// the customer never picked a payment method
+	case err != nil:
+		return false, err
+	case res.TransactionStatus == "pending":
+		// expire first so the customer can't pay for an order we cancel, the cancel is
+		// logged along with the expiry
+		err = svc.repo.ExpirePayment(orderID, upd)
+		if !errors.Is(err, gateway.ErrTransactionNotFound) {
+			return err == nil, err
+		}
+	default:
+		upd.Status = orderStatusFor(model.MidtransNotification{
+			TransactionStatus: res.TransactionStatus,
+			FraudStatus:       res.FraudStatus,
+		})
+		upd.Note = "midtrans " + res.TransactionStatus
+
+		if upd.Status == orderPaid {
+			err = checkAmount(order, res.GrossAmount)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if !canMoveOrder(order.Status, upd.Status) {
+		return false, nil
+	}
+
+	err = svc.repo.UpdateOrder(upd)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
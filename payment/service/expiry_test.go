@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"payment-go/gateway"
+	"payment-go/mocks"
+	"payment-go/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpireUnpaidOrders(t *testing.T) {
+	test_expire := []struct {
+		name              string
+		transactionStatus string
+		grossAmount       string
+		checkErr          error
+		wantErr           error
+		wantExpire        bool
+		wantStatus        string
+		wantNote          string
+	}{
+		{name: "never paid", checkErr: gateway.ErrTransactionNotFound, wantStatus: orderCancelled, wantNote: "payment expired"},
+		{name: "still pending", transactionStatus: "pending", wantExpire: true, wantStatus: orderCancelled, wantNote: "payment expired"},
+		{name: "expired at gateway", transactionStatus: "expire", wantStatus: orderCancelled, wantNote: "midtrans expire"},
+		{name: "settled without notification", transactionStatus: "settlement", grossAmount: "150000.00", wantStatus: orderPaid, wantNote: "midtrans settlement"},
+		{name: "settled with another amount", transactionStatus: "settlement", grossAmount: "1000.00", wantErr: ErrAmountMismatch},
+		{name: "challenged by fraud detection", transactionStatus: "capture"},
+	}
+	for _, tt := range test_expire {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := mocks.NewRepositoryMock()
+			service := NewService(repoMock, testServerKey)
+
+			before := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+			order := model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: orderPendingPayment, TotalPrice: 150000}
+
+			fraudStatus := ""
+			if tt.transactionStatus == "capture" {
+				fraudStatus = "challenge"
+			}
+
+			repoMock.On("GetUnpaidOrders", before, sweepBatch).Return([]model.Order{order}, nil)
+			repoMock.On("CheckPayment", "10").Return(model.PaymentStatusResponse{
+				OrderID:           "10",
+				TransactionStatus: tt.transactionStatus,
+				FraudStatus:       fraudStatus,
+				GrossAmount:       tt.grossAmount,
+			}, tt.checkErr)
+			repoMock.On("ExpirePayment", "10", mock.Anything).Return(nil)
+			repoMock.On("DelayOrderCheck", 10).Return(nil)
+			repoMock.On("UpdateOrder", mock.Anything).Return(nil)
+
+			moved, err := service.ExpireUnpaidOrders(before)
+			if tt.wantErr != nil {
+				require.ErrorContains(t, err, tt.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.wantStatus == "" {
+				require.Equal(t, 0, moved)
+				repoMock.AssertNotCalled(t, "ExpirePayment", mock.Anything, mock.Anything)
+				repoMock.AssertNotCalled(t, "UpdateOrder", mock.Anything)
+				repoMock.AssertCalled(t, "DelayOrderCheck", 10)
+				return
+			}
+
+			require.Equal(t, 1, moved)
+			repoMock.AssertNotCalled(t, "DelayOrderCheck", mock.Anything)
+			upd := model.OrderUpd{
+				OrderNumber: order.OrderNumber,
+				Status:      tt.wantStatus,
+				PrevStatus:  orderPendingPayment,
+				Note:        tt.wantNote,
+			}
+			if tt.wantExpire {
+				// the cancel is logged along with the expiry
+				repoMock.AssertCalled(t, "ExpirePayment", "10", upd)
+				repoMock.AssertNotCalled(t, "UpdateOrder", mock.Anything)
+				return
+			}
+			repoMock.AssertNotCalled(t, "ExpirePayment", mock.Anything, mock.Anything)
+			repoMock.AssertCalled(t, "UpdateOrder", upd)
+		})
+	}
+}
+
+func TestExpireUnpaidOrdersKeepsGoing(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	before := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	repoMock.On("GetUnpaidOrders", before, sweepBatch).Return([]model.Order{
+		{Id: 10, OrderNumber: "ABCDE12345", Status: orderPendingPayment},
+		{Id: 11, OrderNumber: "FGHIJ67890", Status: orderPendingPayment},
+	}, nil)
+	repoMock.On("CheckPayment", "10").Return(model.PaymentStatusResponse{}, fmt.Errorf("midtrans down"))
+	repoMock.On("CheckPayment", "11").Return(model.PaymentStatusResponse{}, gateway.ErrTransactionNotFound)
+	repoMock.On("DelayOrderCheck", 10).Return(nil)
+	repoMock.On("UpdateOrder", mock.Anything).Return(nil)
+
+	moved, err := service.ExpireUnpaidOrders(before)
+	require.ErrorContains(t, err, "order 10: midtrans down")
+	require.Equal(t, 1, moved)
+
+	// the failed order waits for a later sweep, the next batch starts with newer orders
+	repoMock.AssertCalled(t, "DelayOrderCheck", 10)
+	repoMock.AssertNotCalled(t, "DelayOrderCheck", 11)
+}
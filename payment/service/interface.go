@@ -2,12 +2,15 @@ package service
 
 import (
 	"payment-go/model"
-
-	"github.com/midtrans/midtrans-go/coreapi"
-	"github.com/midtrans/midtrans-go/snap"
+	"time"
 )
 
 type Servicer interface {
-	CheckPayment(orderID string) (res *coreapi.TransactionStatusResponse, err error)
-	CreatePaymentLog(req model.PaymentLogRequest) (res *snap.Response, err error)
-}
\ No newline at end of file
+	CheckPayment(orderID string) (model.PaymentStatusResponse, error)
+	CreatePaymentLog(req model.PaymentLogRequest) (model.PaymentResponse, error)
+	GetPaymentLogs(orderID int) ([]model.PaymentLog, error)
+	GetPaymentLogByID(id int) (model.PaymentLog, error)
+	Refund(req model.RefundRequest) (model.RefundResponse, error)
+	HandleNotification(req model.MidtransNotification, payload []byte) error
+	ExpireUnpaidOrders(before time.Time) (int, error)
+}
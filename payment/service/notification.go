@@ -0,0 +1,105 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"payment-go/midtrans"
+	"payment-go/model"
+	"strconv"
+)
+
+const (
+	orderPendingPayment = "pending_payment"
+	orderPaid           = "paid"
+	orderPacked         = "packed"
+	orderDelivered      = "delivered"
+	orderCancelled      = "cancelled"
+	orderRefunded       = "refunded"
+
+	orderPartiallyRefunded = "partially_refunded"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature key")
+	ErrNotRefundable    = errors.New("order can't be refunded")
+	ErrRefundAmount     = errors.New("invalid refund amount")
+	ErrRefundKeyReused  = errors.New("idempotency key was used for another order")
+	ErrNotPayable       = errors.New("order isn't waiting for payment")
+	// ErrAmountMismatch is a payment whose gross amount isn't the order's total, the order
+	// stays where it is until someone looks at it.
+	ErrAmountMismatch = errors.New("paid amount doesn't match the order total")
+)
+
+// orderTransitions lists, for the order status a notification leads to, the current order
+// statuses it may be applied from. It mirrors the transitions enforced by the order service.
+var orderTransitions = map[string][]string{
+	orderPaid:      {orderPendingPayment},
+	orderCancelled: {orderPendingPayment, orderPaid},
+	orderRefunded:  {orderPaid, orderPacked, orderDelivered, orderPartiallyRefunded},
+
+	orderPartiallyRefunded: {orderPaid, orderPacked, orderDelivered, orderPartiallyRefunded},
+}
+
+func verifySignature(req model.MidtransNotification, serverKey string) bool {
+	want := midtrans.SignatureKey(req.OrderID, req.StatusCode, req.GrossAmount, serverKey)
+	return subtle.ConstantTimeCompare([]byte(want), []byte(req.SignatureKey)) == 1
+}
+
+// orderAmount is the order's total as the whole rupiah amount charged at the gateway.
+func orderAmount(order model.Order) int64 {
+	return int64(math.Round(order.TotalPrice))
+}
+
+// checkAmount compares the gross amount the gateway reports for a payment with the order's
+// total, a payment of another amount must not mark the order paid.
+func checkAmount(order model.Order, grossAmount string) error {
+	paid, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil || int64(math.Round(paid)) != orderAmount(order) {
+		return fmt.Errorf("%w: paid %q for order %d of %d", ErrAmountMismatch, grossAmount, order.Id, orderAmount(order))
+	}
+	return nil
+}
+
+// orderStatusFor maps a midtrans transaction status to the order status it results in,
+// an empty string means the order stays as it is.
+func orderStatusFor(req model.MidtransNotification) string {
+	switch req.TransactionStatus {
+	case "capture":
+		switch req.FraudStatus {
+		case "accept", "":
+			return orderPaid
+		case "deny":
+			return orderCancelled
+		}
+		// challenge waits for the merchant to accept or deny in the dashboard
+		return ""
+	case "settlement":
+		return orderPaid
+	case "expire", "cancel", "deny", "failure":
+		return orderCancelled
+	case "refund":
+		return orderRefunded
+	case "partial_refund":
+		return orderPartiallyRefunded
+	}
+	return ""
+}
+
+// refundOrderStatus maps the transaction status after a refund to the order status.
+func refundOrderStatus(transactionStatus string) string {
+	if transactionStatus == "refund" {
+		return orderRefunded
+	}
+	return orderPartiallyRefunded
+}
+
+func canMoveOrder(from, to string) bool {
+	for _, v := range orderTransitions[to] {
+		if v == from {
+			return true
+		}
+	}
+	return false
+}
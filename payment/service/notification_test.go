@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"payment-go/midtrans"
+	"payment-go/mocks"
+	"payment-go/model"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testServerKey = "SB-Mid-server-test"
+
+// fakeNotifier builds notifications the way midtrans sends them, signed with its server key.
+type fakeNotifier struct {
+	serverKey string
+}
+
+func (f fakeNotifier) notify(orderID, transactionStatus, fraudStatus string) (model.MidtransNotification, []byte) {
+	req := model.MidtransNotification{
+		TransactionTime:   "2023-06-01 10:00:00",
+		TransactionStatus: transactionStatus,
+		TransactionID:     "trx-" + orderID + "-" + transactionStatus,
+		StatusCode:        "200",
+		PaymentType:       "gopay",
+		OrderID:           orderID,
+		GrossAmount:       "150000.00",
+		FraudStatus:       fraudStatus,
+		Currency:          "IDR",
+	}
+	req.SignatureKey = midtrans.SignatureKey(req.OrderID, req.StatusCode, req.GrossAmount, f.serverKey)
+
+	payload, _ := json.Marshal(req)
+	return req, payload
+}
+
+func TestHandleNotification(t *testing.T) {
+	notifier := fakeNotifier{serverKey: testServerKey}
+
+	test_notification := []struct {
+		name              string
+		transactionStatus string
+		fraudStatus       string
+		orderStatus       string
+		wantStatus        string
+	}{
+		{name: "capture accepted", transactionStatus: "capture", fraudStatus: "accept", orderStatus: orderPendingPayment, wantStatus: orderPaid},
+		{name: "capture challenged", transactionStatus: "capture", fraudStatus: "challenge", orderStatus: orderPendingPayment},
+		{name: "capture denied", transactionStatus: "capture", fraudStatus: "deny", orderStatus: orderPendingPayment, wantStatus: orderCancelled},
+		{name: "settlement", transactionStatus: "settlement", orderStatus: orderPendingPayment, wantStatus: orderPaid},
+		{name: "pending", transactionStatus: "pending", orderStatus: orderPendingPayment},
+		{name: "expire", transactionStatus: "expire", orderStatus: orderPendingPayment, wantStatus: orderCancelled},
+		{name: "cancel", transactionStatus: "cancel", orderStatus: orderPendingPayment, wantStatus: orderCancelled},
+		{name: "deny", transactionStatus: "deny", orderStatus: orderPendingPayment, wantStatus: orderCancelled},
+		{name: "failure", transactionStatus: "failure", orderStatus: orderPendingPayment, wantStatus: orderCancelled},
+		{name: "refund", transactionStatus: "refund", orderStatus: orderPaid, wantStatus: orderRefunded},
+		{name: "partial refund", transactionStatus: "partial_refund", orderStatus: orderDelivered, wantStatus: orderPartiallyRefunded},
+		{name: "partial refund already applied", transactionStatus: "partial_refund", orderStatus: orderPartiallyRefunded},
+		{name: "settlement on cancelled order", transactionStatus: "settlement", orderStatus: orderCancelled},
+		{name: "expire on paid order", transactionStatus: "expire", orderStatus: orderPacked},
+	}
+	for _, tt := range test_notification {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := mocks.NewRepositoryMock()
+			service := NewService(repoMock, testServerKey)
+
+			req, payload := notifier.notify("10", tt.transactionStatus, tt.fraudStatus)
+			order := model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: tt.orderStatus, TotalPrice: 150000}
+
+			repoMock.On("SaveNotification", req, payload).Return(1, false, nil)
+			repoMock.On("GetOrderByID", "10").Return(order, nil)
+			repoMock.On("MarkNotificationProcessed", 1, mock.Anything).Return(nil)
+
+			err := service.HandleNotification(req, payload)
+			require.NoError(t, err)
+
+			if tt.wantStatus == "" {
+				repoMock.AssertCalled(t, "MarkNotificationProcessed", 1, (*model.OrderUpd)(nil))
+				return
+			}
+			// the order's move is stored along with the notification being processed
+			repoMock.AssertCalled(t, "MarkNotificationProcessed", 1, &model.OrderUpd{
+				OrderNumber: order.OrderNumber,
+				Status:      tt.wantStatus,
+				PrevStatus:  tt.orderStatus,
+				Note:        "midtrans " + tt.transactionStatus,
+			})
+		})
+	}
+}
+
+func TestHandleNotificationInvalidSignature(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	req, payload := fakeNotifier{serverKey: "another-key"}.notify("10", "settlement", "")
+
+	err := service.HandleNotification(req, payload)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	repoMock.AssertNotCalled(t, "SaveNotification", mock.Anything, mock.Anything)
+}
+
+func TestHandleNotificationDuplicate(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	req, payload := fakeNotifier{serverKey: testServerKey}.notify("10", "settlement", "")
+	repoMock.On("SaveNotification", req, payload).Return(1, true, nil)
+
+	err := service.HandleNotification(req, payload)
+	require.NoError(t, err)
+	repoMock.AssertNotCalled(t, "MarkNotificationProcessed", mock.Anything, mock.Anything)
+}
+
+func TestHandleNotificationNotStored(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	req, payload := fakeNotifier{serverKey: testServerKey}.notify("10", "settlement", "")
+	repoMock.On("SaveNotification", req, payload).Return(1, false, nil)
+	repoMock.On("GetOrderByID", "10").Return(model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: orderPendingPayment, TotalPrice: 150000}, nil)
+	repoMock.On("MarkNotificationProcessed", 1, mock.Anything).Return(fmt.Errorf("db down"))
+
+	// neither the order's move nor the processed mark is stored, the retried notification
+	// from midtrans is applied again
+	err := service.HandleNotification(req, payload)
+	require.Error(t, err)
+}
+
+func TestHandleNotificationAmountMismatch(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	// signed right, but the order costs more than what was paid
+	req, payload := fakeNotifier{serverKey: testServerKey}.notify("10", "settlement", "")
+	repoMock.On("SaveNotification", req, payload).Return(1, false, nil)
+	repoMock.On("GetOrderByID", "10").Return(model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: orderPendingPayment, TotalPrice: 1500000}, nil)
+
+	err := service.HandleNotification(req, payload)
+	require.ErrorIs(t, err, ErrAmountMismatch)
+	repoMock.AssertNotCalled(t, "MarkNotificationProcessed", mock.Anything, mock.Anything)
+}
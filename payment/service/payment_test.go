@@ -0,0 +1,41 @@
+package service
+
+import (
+	"payment-go/mocks"
+	"payment-go/model"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePaymentLog(t *testing.T) {
+	test_payment := []struct {
+		name        string
+		orderStatus string
+		wantErr     error
+	}{
+		{name: "order waiting for payment", orderStatus: orderPendingPayment},
+		{name: "order paid already", orderStatus: orderPaid, wantErr: ErrNotPayable},
+		{name: "order cancelled", orderStatus: orderCancelled, wantErr: ErrNotPayable},
+	}
+	for _, tt := range test_payment {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := mocks.NewRepositoryMock()
+			service := NewService(repoMock, testServerKey)
+
+			repoMock.On("GetOrderByID", "10").Return(model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: tt.orderStatus, TotalPrice: 149999.6}, nil)
+			repoMock.On("CreatePaymentLog", mock.Anything).Return(model.PaymentResponse{Token: "snap-token"}, nil)
+
+			// whatever the client sends, the order's total is charged
+			_, err := service.CreatePaymentLog(model.PaymentLogRequest{UserID: 2, OrderID: 10, TotalPayment: 1})
+			require.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr != nil {
+				repoMock.AssertNotCalled(t, "CreatePaymentLog", mock.Anything)
+				return
+			}
+			repoMock.AssertCalled(t, "CreatePaymentLog", model.PaymentLogRequest{UserID: 2, OrderID: 10, TotalPayment: 150000})
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"payment-go/mocks"
+	"payment-go/model"
+	"payment-go/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefund(t *testing.T) {
+	test_refund := []struct {
+		name        string
+		orderStatus string
+		refunded    int64
+		amount      int64
+		wantAmount  int64
+		wantStatus  string
+		wantErr     error
+	}{
+		{name: "full refund", orderStatus: orderPaid, wantAmount: 150000, wantStatus: orderRefunded},
+		{name: "partial refund", orderStatus: orderPacked, amount: 50000, wantAmount: 50000, wantStatus: orderPartiallyRefunded},
+		{name: "rest of partially refunded order", orderStatus: orderPartiallyRefunded, refunded: 50000, wantAmount: 100000, wantStatus: orderRefunded},
+		{name: "more than paid", orderStatus: orderPaid, amount: 200000, wantErr: ErrRefundAmount},
+		{name: "more than left", orderStatus: orderPartiallyRefunded, refunded: 100000, amount: 60000, wantErr: ErrRefundAmount},
+		{name: "shipped order", orderStatus: "shipped", wantErr: ErrNotRefundable},
+		{name: "unpaid order", orderStatus: orderPendingPayment, wantErr: ErrNotRefundable},
+		{name: "refunded order", orderStatus: orderRefunded, wantErr: ErrNotRefundable},
+	}
+	for _, tt := range test_refund {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := mocks.NewRepositoryMock()
+			service := NewService(repoMock, testServerKey)
+
+			req := model.RefundRequest{OrderID: "10", Amount: tt.amount, Reason: "damaged", IdempotencyKey: "key-1"}
+			order := model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: tt.orderStatus, TotalPrice: 150000}
+
+			transactionStatus := "partial_refund"
+			if tt.wantStatus == orderRefunded {
+				transactionStatus = "refund"
+			}
+
+			unlocked := false
+			repoMock.On("LockOrder", "10").Return(func() { unlocked = true }, nil)
+			repoMock.On("GetRefundByKey", "key-1").Return(model.RefundResponse{}, repository.ErrRefundNotFound)
+			repoMock.On("GetOrderByID", "10").Return(order, nil)
+			repoMock.On("GetRefundedAmount", "10").Return(tt.refunded, nil)
+			refund := model.RefundResponse{
+				OrderID:           "10",
+				RefundKey:         "key-1",
+				Amount:            tt.wantAmount,
+				TransactionStatus: transactionStatus,
+			}
+			repoMock.On("Refund", mock.Anything).Return(refund, nil)
+			repoMock.On("SaveRefund", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			res, err := service.Refund(req)
+			require.True(t, unlocked)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				repoMock.AssertNotCalled(t, "Refund", mock.Anything)
+				repoMock.AssertNotCalled(t, "SaveRefund", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, res.OrderStatus)
+
+			req.Amount = tt.wantAmount
+			repoMock.AssertCalled(t, "Refund", req)
+
+			// the refund is logged along with the order's move
+			refund.OrderStatus = tt.wantStatus
+			repoMock.AssertCalled(t, "SaveRefund", req, refund, model.OrderUpd{
+				OrderNumber: order.OrderNumber,
+				Status:      tt.wantStatus,
+				PrevStatus:  tt.orderStatus,
+				Note:        "refund " + fmt.Sprint(tt.wantAmount) + ": damaged",
+			})
+		})
+	}
+}
+
+func TestRefundRetried(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	done := model.RefundResponse{OrderID: "10", RefundKey: "key-1", Amount: 150000, TransactionStatus: "refund"}
+	repoMock.On("LockOrder", "10").Return(func() {}, nil)
+	repoMock.On("GetRefundByKey", "key-1").Return(done, nil)
+	repoMock.On("GetOrderByID", "10").Return(model.Order{Id: 10, OrderNumber: "ABCDE12345", Status: orderRefunded, TotalPrice: 150000}, nil)
+
+	res, err := service.Refund(model.RefundRequest{OrderID: "10", Reason: "damaged", IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.Equal(t, orderRefunded, res.OrderStatus)
+	repoMock.AssertNotCalled(t, "Refund", mock.Anything)
+	repoMock.AssertNotCalled(t, "UpdateOrder", mock.Anything)
+}
+
+func TestRefundKeyReused(t *testing.T) {
+	repoMock := mocks.NewRepositoryMock()
+	service := NewService(repoMock, testServerKey)
+
+	repoMock.On("LockOrder", "10").Return(func() {}, nil)
+	repoMock.On("GetRefundByKey", "key-1").Return(model.RefundResponse{OrderID: "11", RefundKey: "key-1"}, nil)
+
+	_, err := service.Refund(model.RefundRequest{OrderID: "10", Reason: "damaged", IdempotencyKey: "key-1"})
+	require.ErrorIs(t, err, ErrRefundKeyReused)
+}
@@ -1,26 +1,184 @@
 package service
 
 import (
-	"github.com/midtrans/midtrans-go/coreapi"
-	"github.com/midtrans/midtrans-go/snap"
+	"errors"
+	"fmt"
 	"payment-go/model"
 	"payment-go/repository"
+	"strconv"
 )
 
 type service struct {
-	repo repository.Repositorier
+	repo      repository.Repositorier
+	serverKey string
 }
 
-func NewService(repo repository.Repositorier) Servicer {
+func NewService(repo repository.Repositorier, serverKey string) Servicer {
 	return &service{
-		repo: repo,
+		repo:      repo,
+		serverKey: serverKey,
 	}
 }
 
-func (svc *service) CheckPayment(orderID string) (res *coreapi.TransactionStatusResponse, err error) {
+func (svc *service) CheckPayment(orderID string) (model.PaymentStatusResponse, error) {
 	return svc.repo.CheckPayment(orderID)
 }
 
-func (svc *service) CreatePaymentLog(req model.PaymentLogRequest) (res *snap.Response, err error) {
+// CreatePaymentLog opens a snap transaction for an order waiting for payment, charged
+// with the order's total.
+func (svc *service) CreatePaymentLog(req model.PaymentLogRequest) (model.PaymentResponse, error) {
+	order, err := svc.repo.GetOrderByID(strconv.Itoa(req.OrderID))
+	if err != nil {
+		return model.PaymentResponse{}, err
+	}
+	if order.Status != orderPendingPayment {
+		return model.PaymentResponse{}, fmt.Errorf("%w: order is %s", ErrNotPayable, order.Status)
+	}
+
+	req.TotalPayment = orderAmount(order)
 	return svc.repo.CreatePaymentLog(req)
-}
\ No newline at end of file
+}
+
+func (svc *service) GetPaymentLogs(orderID int) ([]model.PaymentLog, error) {
+	return svc.repo.GetPaymentLogs(orderID)
+}
+
+func (svc *service) GetPaymentLogByID(id int) (model.PaymentLog, error) {
+	return svc.repo.GetPaymentLogByID(id)
+}
+
+// Refund gives back all or part of an order's payment through the gateway and asks
+// consumer-worker to move the order to refunded or partially_refunded.
+// A request retried with the same idempotency key returns the refund already made.
+// Refunds of the same order wait for each other, so what is left to refund can't be
+// refunded twice.
+func (svc *service) Refund(req model.RefundRequest) (model.RefundResponse, error) {
+	unlock, err := svc.repo.LockOrder(req.OrderID)
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+	defer unlock()
+
+	res, err := svc.repo.GetRefundByKey(req.IdempotencyKey)
+	if err == nil {
+		if res.OrderID != req.OrderID {
+			return model.RefundResponse{}, ErrRefundKeyReused
+		}
+		return svc.replayRefund(res)
+	}
+	if !errors.Is(err, repository.ErrRefundNotFound) {
+		return model.RefundResponse{}, err
+	}
+
+	order, err := svc.repo.GetOrderByID(req.OrderID)
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	if !canMoveOrder(order.Status, orderPartiallyRefunded) {
+		return model.RefundResponse{}, fmt.Errorf("%w: order is %s", ErrNotRefundable, order.Status)
+	}
+
+	refunded, err := svc.repo.GetRefundedAmount(req.OrderID)
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	remaining := orderAmount(order) - refunded
+	if req.Amount == 0 {
+		req.Amount = remaining
+	}
+	if req.Amount <= 0 || req.Amount > remaining {
+		return model.RefundResponse{}, fmt.Errorf("%w: %d left to refund", ErrRefundAmount, remaining)
+	}
+
+	res, err = svc.repo.Refund(req)
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+	res.OrderStatus = refundOrderStatus(res.TransactionStatus)
+
+	err = svc.repo.SaveRefund(req, res, model.OrderUpd{
+		OrderNumber: order.OrderNumber,
+		Status:      res.OrderStatus,
+		PrevStatus:  order.Status,
+		Note:        fmt.Sprintf("refund %d: %s", req.Amount, req.Reason),
+	})
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	return res, nil
+}
+
+// replayRefund answers a retried refund. The order event is published again when the
+// order never got there, consumer-worker drops it as stale if the first one is still on
+// its way.
+func (svc *service) replayRefund(res model.RefundResponse) (model.RefundResponse, error) {
+	res.OrderStatus = refundOrderStatus(res.TransactionStatus)
+
+	order, err := svc.repo.GetOrderByID(res.OrderID)
+	if err != nil {
+		return model.RefundResponse{}, err
+	}
+
+	if order.Status != res.OrderStatus && canMoveOrder(order.Status, res.OrderStatus) {
+		err = svc.repo.UpdateOrder(model.OrderUpd{
+			OrderNumber: order.OrderNumber,
+			Status:      res.OrderStatus,
+			PrevStatus:  order.Status,
+			Note:        fmt.Sprintf("refund %d", res.Amount),
+		})
+		if err != nil {
+			return model.RefundResponse{}, err
+		}
+	}
+
+	return res, nil
+}
+
+// HandleNotification verifies and records a midtrans notification, then asks
+// consumer-worker to move the order when the transaction status calls for it.
+// Notifications midtrans retries are only applied once.
+func (svc *service) HandleNotification(req model.MidtransNotification, payload []byte) error {
+	if !verifySignature(req, svc.serverKey) {
+		return ErrInvalidSignature
+	}
+
+	id, processed, err := svc.repo.SaveNotification(req, payload)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	var upd *model.OrderUpd
+	status := orderStatusFor(req)
+	if status != "" {
+		order, err := svc.repo.GetOrderByID(req.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if status == orderPaid {
+			// left unprocessed, the mismatch stays in payment_notifications for a look
+			err = checkAmount(order, req.GrossAmount)
+			if err != nil {
+				return err
+			}
+		}
+
+		// a refund made through Refund already moved the order
+		if order.Status != status && canMoveOrder(order.Status, status) {
+			upd = &model.OrderUpd{
+				OrderNumber: order.OrderNumber,
+				Status:      status,
+				PrevStatus:  order.Status,
+				Note:        "midtrans " + req.TransactionStatus,
+			}
+		}
+	}
+
+	return svc.repo.MarkNotificationProcessed(id, upd)
+}
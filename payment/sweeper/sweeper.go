@@ -0,0 +1,71 @@
+package sweeper
+
+import (
+	"context"
+	"payment-go/service"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LockKey is the advisory lock every payment replica competes for, only the holder sweeps.
+const LockKey int64 = 5006_0001
+
+type Locker interface {
+	TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
+
+// Sweeper periodically cancels orders left unpaid for longer than the payment ttl.
+type Sweeper struct {
+	svc      service.Servicer
+	lock     Locker
+	interval time.Duration
+	ttl      time.Duration
+	logger   *zerolog.Logger
+}
+
+func NewSweeper(svc service.Servicer, lock Locker, interval, ttl time.Duration, logger *zerolog.Logger) *Sweeper {
+	return &Sweeper{
+		svc:      svc,
+		lock:     lock,
+		interval: interval,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// Run sweeps every interval until ctx is done.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Sweeper) sweep(ctx context.Context) {
+	unlock, ok, err := s.lock.TryLock(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("sweeper failed to take lock")
+		return
+	}
+	if !ok {
+		s.logger.Debug().Msg("sweeper lock held by another replica")
+		return
+	}
+	defer unlock()
+
+	moved, err := s.svc.ExpireUnpaidOrders(time.Now().Add(-s.ttl))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("sweeper failed")
+	}
+	if moved > 0 {
+		s.logger.Info().Msgf("sweeper moved %d unpaid orders", moved)
+	}
+}
@@ -68,7 +68,7 @@ func (h *handler) CreateProduct(ctx *gin.Context) {
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.CreateProduct(data)
+	res, err := h.svc.CreateProduct(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
@@ -90,7 +90,7 @@ func (h *handler) UpdateProduct(ctx *gin.Context) {
 		numi = num
 	}
 
-	res, err := h.svc.UpdateProduct(data, numi)
+	res, err := h.svc.UpdateProduct(ctx.Request.Context(), data, numi)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
@@ -1,15 +1,17 @@
 package main
 
 import (
+	"context"
+	"outbox-go"
 	"product-go/config"
 	"product-go/handler"
 	"product-go/helper/failerror"
 	"product-go/helper/logging"
 	"product-go/helper/middleware"
 	"product-go/package/db"
-	"product-go/publisher"
 	"product-go/repository"
 	"product-go/service"
+	"telemetry-go"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -22,11 +24,23 @@ func main() {
 
 	logger := logging.New(conf.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "product", conf.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	db, err := db.NewGormDB(conf.Debug, conf.DatabaseDriver, conf.DatabaseURL)
 	failerror.FailError(err, "error new gorm")
 	logger.Debug().Msg("DB Connected")
 
-	pub := publisher.NewPublisher()
+	// events are written to the outbox table and relayed to RabbitMQ in the background
+	pub := outbox.New(db.SQLDB, conf.RabbitMQ, "product")
+	go pub.Run(context.Background())
 
 	repoProduct := repository.NewRepository(db.SQLDB, pub)
 	product := service.NewService(repoProduct)
@@ -40,6 +54,8 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 	failerror.FailError(err, "error loadconfig")
 	// server
 	r := gin.New()
+	r.Use(telemetry.Middleware("product"))
+	r.GET("/metrics", gin.WrapH(telemetry.Handler()))
 
 	// middleware
 	r.Use(middleware.Logger(logger))
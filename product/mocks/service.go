@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"product-go/model"
 
 	"github.com/stretchr/testify/mock"
@@ -27,17 +28,13 @@ func (m *ServiceMock) ShowProduct(id int) (model.Product, error) {
 	return result, err
 }
 
-func (m *ServiceMock) CreateProduct(req []model.Product) ([]model.Product, error) {
-	ret := m.Called(req)
-	result := ret.Get(0).([]model.Product)
-	err := ret.Error(1)
-	return result, err
+func (m *ServiceMock) CreateProduct(ctx context.Context, req []model.Product) error {
+	ret := m.Called(ctx, req)
+	return ret.Error(0)
 }
-func (m *ServiceMock) UpdateProduct(req model.ProductUpd) (model.Product, error) {
-	ret := m.Called(req)
-	result := ret.Get(0).(model.Product)
-	err := ret.Error(1)
-	return result, err
+func (m *ServiceMock) UpdateProduct(ctx context.Context, req model.ProductUpd) error {
+	ret := m.Called(ctx, req)
+	return ret.Error(0)
 }
 func (m *ServiceMock) DeleteProduct(id int) (int, error) {
 	ret := m.Called(id)
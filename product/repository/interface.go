@@ -1,11 +1,14 @@
 package repository
 
-import "product-go/model"
+import (
+	"context"
+	"product-go/model"
+)
 
 type Repositorier interface {
 	GetProduct(req model.ProductSearch) ([]model.Product, error)
 	ShowProduct(id int) (model.Product, error)
-	CreateProduct(req []model.Product) ([]model.Product, error)
-	UpdateProduct(req model.ProductUpd) (model.Product, error)
+	CreateProduct(ctx context.Context, req []model.Product) error
+	UpdateProduct(ctx context.Context, req model.ProductUpd) error
 	DeleteProduct(id int) (int, error)
 }
@@ -1,14 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"log"
 	"product-go/helper/failerror"
 	"product-go/helper/timeout"
 	"product-go/model"
 	"product-go/publisher"
-	"time"
 )
 
 type repository struct {
@@ -131,102 +130,25 @@ func (repo *repository) ShowProduct(id int) (model.Product, error) {
 	return data, nil
 }
 
-func (repo *repository) CreateProduct(req []model.Product) ([]model.Product, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
-	defer cancel()
-
-	err := repo.sent.Public(req, "create_product")
+// CreateProduct publishes the create_product event, consumer-worker writes the products. It returns once
+// the event is stored in the outbox, the products show up by their sku once it has.
+func (repo *repository) CreateProduct(ctx context.Context, req []model.Product) error {
+	err := repo.sent.Publish(ctx, req, "create_product")
 	if err != nil {
-		return nil, errors.New("failed publisher")
-	}
-
-	time.Sleep(3 * time.Second)
-
-	var result []model.Product
-
-	for _, v := range req {
-
-		queryImage := `select i.name, i.image_url from image i join image_products ip on ip.image_id = i.id join products p on p.id  = ip.product_id  where sku = $1`
-
-		rows, err := repo.db.QueryContext(ctx, queryImage, v.Sku)
-		failerror.FailError(err, "error query")
-
-		var imageProduct = []model.ProductImage{}
-		for rows.Next() {
-			var images model.ProductImage
-			err := rows.Scan(&images.Name, &images.ImageURL)
-			failerror.FailError(err, "error scan")
-			imageProduct = append(imageProduct, images)
-		}
-
-		queryProduct := `select  id, store_id, category_id, size_id, color_id , name, brand,subtitle , description , unit_price , status , stock ,sku , weight , created_at , updated_at from products p where sku = $1`
-
-		rowsProduct, err := repo.db.QueryContext(ctx, queryProduct, v.Sku)
-		failerror.FailError(err, "error prepare")
-
-		var temp model.ProductResult
-		for rowsProduct.Next() {
-			rowsProduct.Scan(&temp.Id, &temp.StoreID, &temp.CategoryID, &temp.SizeID, &temp.ColorID, &temp.Name, &temp.Brand, &temp.Subtitle, &temp.Description, &temp.UnitPrice, &temp.Status, &temp.Stock, &temp.Sku, &temp.Weight, &temp.Created_at, &temp.Update_at)
-		}
-		if temp.Id == 0 {
-			continue
-		}
-
-		result = append(result, model.Product{
-			Id:           temp.Id,
-			StoreID:      temp.StoreID,
-			CategoryID:   temp.CategoryID,
-			SizeID:       temp.SizeID,
-			ColorID:      temp.ColorID,
-			Name:         temp.Name,
-			Brand:        temp.Brand,
-			Subtitle:     temp.Subtitle,
-			Description:  temp.Description,
-			UnitPrice:    temp.UnitPrice,
-			Status:       temp.Status,
-			Stock:        temp.Stock,
-			Sku:          temp.Sku,
-			Weight:       temp.Weight,
-			ProductImage: imageProduct,
-			Created_at:   temp.Created_at,
-			Update_at:    temp.Update_at,
-		})
+		return errors.New("failed publisher")
 	}
 
-	if result == nil {
-		return []model.Product{}, errors.New("error create product")
-	}
-
-	return result, nil
+	return nil
 }
 
-func (repo *repository) UpdateProduct(req model.ProductUpd) (model.Product, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
-	defer cancel()
-
-	err := repo.sent.Public(req, "update_product")
+// UpdateProduct publishes the update_product event, consumer-worker writes the change.
+func (repo *repository) UpdateProduct(ctx context.Context, req model.ProductUpd) error {
+	err := repo.sent.Publish(ctx, req, "update_product")
 	if err != nil {
-		return model.Product{}, errors.New("failed publisher")
-	}
-
-	log.Println(req)
-	time.Sleep(1 * time.Second)
-
-	query := `select id, store_id, category_id, size_id, color_id , name, brand,subtitle , description , unit_price , status , stock ,sku , weight , created_at , updated_at from products p where id = $1`
-
-	result, err := repo.db.QueryContext(ctx, query, req.Id)
-	failerror.FailError(err, "error prepare")
-
-	var temp model.Product
-	for result.Next() {
-		result.Scan(&temp.Id, &temp.StoreID, &temp.CategoryID, &temp.SizeID, &temp.ColorID, &temp.Name, &temp.Brand, &temp.Subtitle, &temp.Description, &temp.UnitPrice, &temp.Status, &temp.Stock, &temp.Sku, &temp.Weight, &temp.Created_at, &temp.Update_at)
-	}
-
-	if temp.Id == 0 {
-		return model.Product{}, errors.New("error create product")
+		return errors.New("failed publisher")
 	}
 
-	return temp, nil
+	return nil
 }
 
 func (repo *repository) DeleteProduct(id int) (int, error) {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
@@ -56,7 +57,7 @@ func (svc *service) ShowProduct(id int) (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateProduct(req []model.ProductReq) (model.Respon, error) {
+func (svc *service) CreateProduct(ctx context.Context, req []model.ProductReq) (model.Respon, error) {
 
 	var data []model.Product
 
@@ -100,20 +101,21 @@ func (svc *service) CreateProduct(req []model.ProductReq) (model.Respon, error)
 	}
 
 	// start
-	res, err := svc.repo.CreateProduct(data)
+	err := svc.repo.CreateProduct(ctx, data)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
 			Data:   nil,
 		}, err
 	}
+	// consumer-worker writes them shortly, the sku finds each one
 	return model.Respon{
-		Status: http.StatusOK,
-		Data:   res,
+		Status: http.StatusAccepted,
+		Data:   data,
 	}, nil
 }
 
-func (svc *service) UpdateProduct(req model.ProductUpd, id int) (model.Respon, error) {
+func (svc *service) UpdateProduct(ctx context.Context, req model.ProductUpd, id int) (model.Respon, error) {
 
 	log.Println(id, req)
 
@@ -141,7 +143,7 @@ func (svc *service) UpdateProduct(req model.ProductUpd, id int) (model.Respon, e
 	}
 
 	// start
-	res, err := svc.repo.UpdateProduct(data)
+	err := svc.repo.UpdateProduct(ctx, data)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
@@ -149,8 +151,8 @@ func (svc *service) UpdateProduct(req model.ProductUpd, id int) (model.Respon, e
 		}, err
 	}
 	return model.Respon{
-		Status: http.StatusOK,
-		Data:   res,
+		Status: http.StatusAccepted,
+		Data:   data,
 	}, nil
 }
 
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"product-go/mocks"
 	"product-go/model"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -198,12 +200,12 @@ func Test_Product_Create(t *testing.T) {
 			repoMock := mocks.NewServiceMock()
 			service := NewService(repoMock)
 
-			repoMock.On("CreateProduct", tt.req).Return(tt.wantRes, tt.err)
+			repoMock.On("CreateProduct", mock.Anything, mock.Anything).Return(tt.err)
 
-			res, err := service.CreateProduct(tt.req)
-			if res.Status == http.StatusOK {
+			res, err := service.CreateProduct(context.Background(), tt.req)
+			if res.Status == http.StatusAccepted {
 				require.NoError(t, err)
-				require.Equal(t, http.StatusOK, res.Status)
+				require.Equal(t, http.StatusAccepted, res.Status)
 			} else if res.Status == http.StatusBadRequest {
 				require.Error(t, err)
 				require.Equal(t, http.StatusBadRequest, res.Status)
@@ -228,7 +230,7 @@ func Test_Product_Update(t *testing.T) {
 			name: "success Update product",
 			id:   1,
 			req: model.ProductUpd{
-				Id: 1,
+				Id:      1,
 				StoreID: 1,
 			},
 			wantRes: model.Product{
@@ -246,7 +248,7 @@ func Test_Product_Update(t *testing.T) {
 			name: "success Update product",
 			id:   1,
 			req: model.ProductUpd{
-				Id: 1,
+				Id:      1,
 				StoreID: 1,
 			},
 			wantRes: model.Product{
@@ -261,12 +263,12 @@ func Test_Product_Update(t *testing.T) {
 			repoMock := mocks.NewServiceMock()
 			service := NewService(repoMock)
 
-			repoMock.On("UpdateProduct", tt.req).Return(tt.wantRes, tt.err)
+			repoMock.On("UpdateProduct", mock.Anything, mock.Anything).Return(tt.err)
 
-			res, err := service.UpdateProduct(tt.req, tt.id)
-			if res.Status == http.StatusOK {
+			res, err := service.UpdateProduct(context.Background(), tt.req, tt.id)
+			if res.Status == http.StatusAccepted {
 				require.NoError(t, err)
-				require.Equal(t, http.StatusOK, res.Status)
+				require.Equal(t, http.StatusAccepted, res.Status)
 			} else if res.Status == http.StatusBadRequest {
 				require.Error(t, err)
 				require.Equal(t, http.StatusBadRequest, res.Status)
@@ -7,6 +7,7 @@ import (
 type Config struct {
 	Debug 		bool   `mapstructure:"DEBUG"`
 	Port  		string `mapstructure:"PORT"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	RabbitMQURL string `mapstructure:"RABBITMQURL"`
 
 	Database `mapstructure:",squash"`
@@ -1,18 +1,20 @@
 package main
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
-	"review-go/package/db"
+	"outbox-go"
 	"review-go/config"
 	"review-go/handler"
 	"review-go/helper/logging"
 	"review-go/helper/middleware"
-	"review-go/publisher"
-	"review-go/service"
-	"review-go/server"
+	"review-go/package/db"
 	"review-go/repository"
+	"review-go/server"
+	"review-go/service"
+	"telemetry-go"
 	"time"
 )
 
@@ -24,6 +26,16 @@ func main() {
 
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "review", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
 		logger.Fatal().Err(errDB).Msg("db failed to connect")
@@ -35,12 +47,19 @@ func main() {
 		_ = sqlDB.Close()
 	}()
 
-	publisher := publisher.NewPublisher()
-	repo := repository.NewRepository(sqlDB.SQLDB, publisher)
+	// events are written to the outbox table and relayed to RabbitMQ until the server shuts down
+	pub := outbox.New(sqlDB.SQLDB, config.RabbitMQURL, "review")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pub.Run(ctx)
+
+	repo := repository.NewRepository(sqlDB.SQLDB, pub)
 	svc := service.NewService(repo)
 	handler := handler.NewHandler(svc)
 
 	router := gin.New()
+	router.Use(telemetry.Middleware("review"))
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
 	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 
@@ -59,4 +78,4 @@ func main() {
 	if srvErr := server.Run(srv, logger); srvErr != nil {
 		logger.Fatal().Err(srvErr).Msg("server shutdown failed")
 	}
-}
\ No newline at end of file
+}
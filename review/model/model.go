@@ -13,4 +13,13 @@ type ReviewRequest struct {
 	ProductID 	int 	`json:"product_id"`
 	Rating		int 	`json:"rating_id"`
 	ReviewText	string 	`json:"review_text"`
-}
\ No newline at end of file
+}
+
+// ReviewEvent is version 2 of the create_reviews event, the rating is sent as rating
+// instead of the rating_id of version 1.
+type ReviewEvent struct {
+	UserID     int    `json:"user_id"`
+	ProductID  int    `json:"product_id"`
+	Rating     int    `json:"rating"`
+	ReviewText string `json:"review_text"`
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"review-go/model"
 )
 
@@ -8,6 +9,6 @@ type Repositorier interface {
 	GetByProductID(productID int) (res []model.Review, err error)
 	GetReviewByID(reviewID int) (res model.Review, err error)
 	GetDetail(userID, productID int) (res model.Review, err error)
-	Create(req []model.ReviewRequest) (res []model.Review, err error)
+	Create(ctx context.Context, req []model.ReviewRequest) (err error)
 	Delete(reviewID int) (err error)
 }
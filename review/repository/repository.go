@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"review-go/model"
 	"review-go/publisher"
@@ -73,22 +72,17 @@ func (repo *repository) GetDetail(userID, productID int) (res model.Review, err
 	return
 }
 
-func (repo *repository) Create(req []model.ReviewRequest) (res []model.Review, err error) {
-	// publish data to RabbitMQ
-	err = repo.publisher.Publish(req, "create_reviews")
-	if err != nil {
-		err = fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
-		return
+// Create publishes the create_reviews event, consumer-worker writes it. It returns once the event is
+// stored in the outbox, before consumer-worker has written it.
+func (repo *repository) Create(ctx context.Context, req []model.ReviewRequest) (err error) {
+	event := make([]model.ReviewEvent, 0, len(req))
+	for _, v := range req {
+		event = append(event, model.ReviewEvent{UserID: v.UserID, ProductID: v.ProductID, Rating: v.Rating, ReviewText: v.ReviewText})
 	}
 
-	time.Sleep(3 * time.Second)
-
-	for _, v := range req {
-		result, err := repo.GetDetail(v.UserID, v.ProductID)
-		if err != nil {
-			return []model.Review{}, errors.New("error get by user id after create")
-		}
-		res = append(res, result)
+	err = repo.publisher.Publish(ctx, event, "create_reviews")
+	if err != nil {
+		return fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
 	}
 	return
 }
@@ -1,11 +1,12 @@
 package service
 
 import (
+	"context"
 	"review-go/model"
 )
 
 type Servicer interface {
 	GetByProductID(productID int) (res []model.Review, err error)
-	Create(req []model.ReviewRequest) (res []model.Review, err error)
+	Create(ctx context.Context, req []model.ReviewRequest) (err error)
 	Delete(reviewID int) (err error)
-}
\ No newline at end of file
+}
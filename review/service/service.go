@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"review-go/model"
 	"review-go/repository"
@@ -20,8 +21,8 @@ func (svc *service) GetByProductID(productID int) (res []model.Review, err error
 	return svc.repo.GetByProductID(productID)
 }
 
-func (svc *service) Create(req []model.ReviewRequest) (res []model.Review, err error) {
-	return svc.repo.Create(req)
+func (svc *service) Create(ctx context.Context, req []model.ReviewRequest) (err error) {
+	return svc.repo.Create(ctx, req)
 }
 
 func (svc *service) Delete(reviewID int) (err error) {
@@ -1,15 +1,17 @@
 package main
 
 import (
+	"context"
+	"outbox-go"
 	"shippings-go/config"
 	"shippings-go/handler"
 	"shippings-go/helper/failerror"
 	"shippings-go/helper/logging"
 	"shippings-go/helper/middleware"
 	"shippings-go/package/db"
-	"shippings-go/publisher"
 	"shippings-go/repository"
 	"shippings-go/service"
+	"telemetry-go"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -22,11 +24,23 @@ func main() {
 
 	logger := logging.New(conf.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "shippings", conf.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	db, err := db.NewGormDB(conf.Debug, conf.DatabaseDriver, conf.DatabaseURL)
 	failerror.FailError(err, "error new gorm")
 	logger.Debug().Msg("DB Connected")
 
-	pub := publisher.NewPublisher()
+	// events are written to the outbox table and relayed to RabbitMQ in the background
+	pub := outbox.New(db.SQLDB, conf.RabbitMQ, "shippings")
+	go pub.Run(context.Background())
 
 	repoShipping := repository.NewRepository(db.SQLDB, pub)
 	Shipping := service.NewService(repoShipping)
@@ -40,6 +54,8 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 	failerror.FailError(err, "error loadconfig")
 	// server
 	r := gin.New()
+	r.Use(telemetry.Middleware("shippings"))
+	r.GET("/metrics", gin.WrapH(telemetry.Handler()))
 
 	// middleware
 	r.Use(middleware.Logger(logger))
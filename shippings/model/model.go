@@ -7,12 +7,14 @@ import (
 type Shipping struct {
 	Id         int       `json:"id"`
 	Name       string    `json:"name"`
+	Fee        float64   `json:"fee"`
 	Created_at time.Time `json:"created_at"`
 	Update_at  time.Time `json:"updated_at"`
 }
 
 type ShippingReq struct {
-	Name string `json:"name"`
+	Name string  `json:"name"`
+	Fee  float64 `json:"fee"`
 }
 
 type Respon struct {
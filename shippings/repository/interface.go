@@ -1,9 +1,12 @@
 package repository
 
-import "shippings-go/model"
+import (
+	"context"
+	"shippings-go/model"
+)
 
 type Repositorier interface {
 	GetShipping() ([]model.Shipping, error)
-	CreateShipping(req []model.ShippingReq) ([]model.Shipping, error)
+	CreateShipping(ctx context.Context, req []model.ShippingReq) error
 	DeleteShipping(id int) (int, error)
 }
@@ -1,13 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"shippings-go/helper/failerror"
 	"shippings-go/helper/timeout"
 	"shippings-go/model"
 	"shippings-go/publisher"
-	"time"
 )
 
 type repository struct {
@@ -26,7 +26,7 @@ func (repo *repository) GetShipping() ([]model.Shipping, error) {
 	ctx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
-	query := `select * from Shippings`
+	query := `select id, name, fee, created_at, updated_at from Shippings`
 
 	result, err := repo.db.QueryContext(ctx, query)
 	failerror.FailError(err, "fail query")
@@ -34,61 +34,36 @@ func (repo *repository) GetShipping() ([]model.Shipping, error) {
 	var data = []model.Shipping{}
 	for result.Next() {
 		var temp model.Shipping
-		result.Scan(&temp.Id, &temp.Name, &temp.Created_at, &temp.Update_at)
+		result.Scan(&temp.Id, &temp.Name, &temp.Fee, &temp.Created_at, &temp.Update_at)
 		data = append(data, temp)
 	}
 
 	return data, nil
 }
 
-func (repo *repository) CreateShipping(sent []model.ShippingReq) ([]model.Shipping, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
+// CreateShipping publishes the create_shipping event, consumer-worker writes the shippings. It returns once
+// the event is stored in the outbox, the shippings show up by their name once it has.
+func (repo *repository) CreateShipping(ctx context.Context, sent []model.ShippingReq) error {
+	queryCtx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
 	for _, v := range sent {
 		var idCheck int
 		queryCheck := `select id from Shippings where name = $1`
-		err := repo.db.QueryRowContext(ctx, queryCheck, v.Name).Scan(&idCheck)
+		err := repo.db.QueryRowContext(queryCtx, queryCheck, v.Name).Scan(&idCheck)
 		failerror.FailError(err, "error exec")
 
 		if idCheck != 0 {
-			return nil, errors.New("name " + v.Name + " already exist")
+			return errors.New("name " + v.Name + " already exist")
 		}
 	}
 
-	err := repo.sent.Public(sent, "create_shipping")
+	err := repo.sent.Publish(ctx, sent, "create_shipping")
 	if err != nil {
-		return nil, errors.New("failed publisher")
-	}
-
-	time.Sleep(1 * time.Second)
-
-	var resultss []model.Shipping
-	query := `select * from Shippings where name = $1`
-
-	stmt, err := repo.db.PrepareContext(ctx, query)
-	failerror.FailError(err, "error prepare")
-
-	for _, v := range sent {
-
-		result, err := stmt.QueryContext(ctx, v.Name)
-		failerror.FailError(err, "error prepare")
-
-		var temp model.Shipping
-		for result.Next() {
-			result.Scan(&temp.Id, &temp.Name, &temp.Created_at, &temp.Update_at)
-		}
-		if temp.Id == 0 {
-			continue
-		}
-		resultss = append(resultss, temp)
-	}
-
-	if resultss == nil {
-		return nil, errors.New("error create product")
+		return errors.New("failed publisher")
 	}
 
-	return resultss, nil
+	return nil
 }
 
 func (repo *repository) DeleteShipping(id int) (int, error) {
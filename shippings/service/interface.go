@@ -1,9 +1,12 @@
 package service
 
-import "shippings-go/model"
+import (
+	"context"
+	"shippings-go/model"
+)
 
 type Servicer interface {
 	GetShipping() (model.Respon, error)
-	CreateShipping(req []model.ShippingReq) (model.Respon, error)
+	CreateShipping(ctx context.Context, req []model.ShippingReq) (model.Respon, error)
 	DeleteShipping(idShipping int) (model.Respon, error)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"shippings-go/model"
@@ -33,16 +34,17 @@ func (svc *service) GetShipping() (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateShipping(req []model.ShippingReq) (model.Respon, error) {
+func (svc *service) CreateShipping(ctx context.Context, req []model.ShippingReq) (model.Respon, error) {
 
 	var check []model.ShippingReq
 
 	for _, v := range req {
-		if v.Name == "" {
+		if v.Name == "" || v.Fee < 0 {
 			continue
 		}
 		data := model.ShippingReq{
 			Name: v.Name,
+			Fee:  v.Fee,
 		}
 		check = append(check, data)
 	}
@@ -55,16 +57,17 @@ func (svc *service) CreateShipping(req []model.ShippingReq) (model.Respon, error
 	}
 
 	// start
-	res, err := svc.repo.CreateShipping(check)
+	err := svc.repo.CreateShipping(ctx, check)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
 			Data:   nil,
 		}, err
 	}
+	// consumer-worker writes them shortly, the name finds each one
 	return model.Respon{
-		Status: http.StatusOK,
-		Data:   res,
+		Status: http.StatusAccepted,
+		Data:   check,
 	}, nil
 }
 
@@ -36,7 +36,7 @@ func (h *handler) CreateSize(ctx *gin.Context) {
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.CreateSize(data)
+	res, err := h.svc.CreateSize(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
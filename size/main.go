@@ -1,15 +1,17 @@
 package main
 
 import (
+	"context"
+	"outbox-go"
 	"size-go/config"
 	"size-go/handler"
 	"size-go/helper/failerror"
 	"size-go/helper/logging"
 	"size-go/helper/middleware"
 	"size-go/package/db"
-	"size-go/publisher"
 	"size-go/repository"
 	"size-go/service"
+	"telemetry-go"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -22,11 +24,23 @@ func main() {
 
 	logger := logging.New(conf.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "size", conf.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	db, err := db.NewGormDB(conf.Debug, conf.DatabaseDriver, conf.DatabaseURL)
 	failerror.FailError(err, "error new gorm")
 	logger.Debug().Msg("DB Connected")
 
-	pub := publisher.NewPublisher()
+	// events are written to the outbox table and relayed to RabbitMQ in the background
+	pub := outbox.New(db.SQLDB, conf.RabbitMQ, "size")
+	go pub.Run(context.Background())
 
 	repoSize := repository.NewRepository(db.SQLDB, pub)
 	Size := service.NewService(repoSize)
@@ -40,6 +54,8 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 	failerror.FailError(err, "error loadconfig")
 	// server
 	r := gin.New()
+	r.Use(telemetry.Middleware("size"))
+	r.GET("/metrics", gin.WrapH(telemetry.Handler()))
 
 	// middleware
 	r.Use(middleware.Logger(logger))
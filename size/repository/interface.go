@@ -1,9 +1,12 @@
 package repository
 
-import "size-go/model"
+import (
+	"context"
+	"size-go/model"
+)
 
 type Repositorier interface {
 	GetSize() ([]model.Size, error)
-	CreateSize(req []model.SizeReq) ([]model.Size, error)
+	CreateSize(ctx context.Context, req []model.SizeReq) error
 	DeleteSize(id int) (int, error)
 }
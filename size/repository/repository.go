@@ -1,13 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"size-go/helper/failerror"
 	"size-go/helper/timeout"
 	"size-go/model"
 	"size-go/publisher"
-	"time"
 )
 
 type repository struct {
@@ -41,54 +41,29 @@ func (repo *repository) GetSize() ([]model.Size, error) {
 	return data, nil
 }
 
-func (repo *repository) CreateSize(sent []model.SizeReq) ([]model.Size, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
+// CreateSize publishes the create_size event, consumer-worker writes the sizes. It returns once
+// the event is stored in the outbox, the sizes show up by their name once it has.
+func (repo *repository) CreateSize(ctx context.Context, sent []model.SizeReq) error {
+	queryCtx, cancel := timeout.NewCtxTimeout()
 	defer cancel()
 
 	for _, v := range sent {
 		var idCheck int
 		queryCheck := `select id from product_sizes where name = $1`
-		err := repo.db.QueryRowContext(ctx, queryCheck, v.Name).Scan(&idCheck)
+		err := repo.db.QueryRowContext(queryCtx, queryCheck, v.Name).Scan(&idCheck)
 		failerror.FailError(err, "error exec")
 
 		if idCheck != 0 {
-			return nil, errors.New("name " + v.Name + " already exist")
+			return errors.New("name " + v.Name + " already exist")
 		}
 	}
 
-	err := repo.sent.Public(sent, "create_size")
+	err := repo.sent.Publish(ctx, sent, "create_size")
 	if err != nil {
-		return nil, errors.New("failed publisher")
-	}
-
-	time.Sleep(1 * time.Second)
-
-	var resultss []model.Size
-	query := `select * from product_sizes where name = $1`
-
-	stmt, err := repo.db.PrepareContext(ctx, query)
-	failerror.FailError(err, "error prepare")
-
-	for _, v := range sent {
-
-		result, err := stmt.QueryContext(ctx, v.Name)
-		failerror.FailError(err, "error prepare")
-
-		var temp model.Size
-		for result.Next() {
-			result.Scan(&temp.Id, &temp.Name, &temp.Created_at, &temp.Update_at)
-		}
-		if temp.Id == 0 {
-			continue
-		}
-		resultss = append(resultss, temp)
-	}
-
-	if resultss == nil {
-		return nil, errors.New("error create product")
+		return errors.New("failed publisher")
 	}
 
-	return resultss, nil
+	return nil
 }
 
 func (repo *repository) DeleteSize(id int) (int, error) {
@@ -1,9 +1,12 @@
 package service
 
-import "size-go/model"
+import (
+	"context"
+	"size-go/model"
+)
 
 type Servicer interface {
 	GetSize() (model.Respon, error)
-	CreateSize(req []model.SizeReq) (model.Respon, error)
+	CreateSize(ctx context.Context, req []model.SizeReq) (model.Respon, error)
 	DeleteSize(idSize int) (model.Respon, error)
 }
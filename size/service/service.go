@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"size-go/model"
@@ -32,7 +33,7 @@ func (svc *service) GetSize() (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateSize(req []model.SizeReq) (model.Respon, error) {
+func (svc *service) CreateSize(ctx context.Context, req []model.SizeReq) (model.Respon, error) {
 
 	var check []model.SizeReq
 
@@ -54,16 +55,17 @@ func (svc *service) CreateSize(req []model.SizeReq) (model.Respon, error) {
 	}
 
 	// start
-	res, err := svc.repo.CreateSize(check)
+	err := svc.repo.CreateSize(ctx, check)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
 			Data:   nil,
 		}, err
 	}
+	// consumer-worker writes them shortly, the name finds each one
 	return model.Respon{
-		Status: http.StatusOK,
-		Data:   res,
+		Status: http.StatusAccepted,
+		Data:   check,
 	}, nil
 }
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"splash-screen-go/config"
@@ -12,6 +13,7 @@ import (
 	"splash-screen-go/repository"
 	"splash-screen-go/server"
 	"splash-screen-go/service"
+	"telemetry-go"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,16 @@ func main() {
 
 	logger := logging.New(config.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "splash-screen", config.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	sqlDB, errDB := db.NewGormDB(config.Debug, config.Database.Driver, config.Database.URL)
 	if errDB != nil {
 		logger.Fatal().Err(errDB).Msg("db failed to connect")
@@ -42,6 +54,8 @@ func main() {
 	handler := handler.NewHandler(svc)
 
 	router := gin.New()
+	router.Use(telemetry.Middleware("splash-screen"))
+	router.GET("/metrics", gin.WrapH(telemetry.Handler()))
 	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 
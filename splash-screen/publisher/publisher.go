@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"splash-screen-go/config"
+	"telemetry-go"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -72,6 +73,7 @@ func (p *publisher) Publish(body interface{}, queueName string) (err error) {
 			Body:         jsonByte,
 		})
 	if err != nil {
+		telemetry.PublishFailed(q.Name)
 		err = fmt.Errorf("failed to publish a message")
 		return
 	}
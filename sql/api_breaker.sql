@@ -0,0 +1,9 @@
+-- per endpoint circuit breaker of the gateway, null uses its BREAKER_* default. An endpoint
+-- with any breaker_* column set gets a breaker of its own instead of its service's
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS breaker_failure_threshold int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS breaker_open_timeout int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS breaker_half_open_probes int;
+
+-- the response while the breaker is open, null answers 503 with the gateway's message
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS fallback_status int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS fallback_body text;
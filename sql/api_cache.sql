@@ -0,0 +1,5 @@
+-- gateway response cache of GET requests, per endpoint
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS cache_ttl int;
+
+-- product listing and details, consumer-worker invalidates them when products change
+UPDATE api_managements SET cache_ttl = 60 WHERE service_name = 'product';
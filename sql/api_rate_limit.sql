@@ -0,0 +1,11 @@
+-- per endpoint rate limits of the gateway, null uses its RATE_LIMIT_* default
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS rate_limit_ip int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS rate_limit_user int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS rate_limit_route int;
+ALTER TABLE api_managements ADD COLUMN IF NOT EXISTS rate_limit_window int;
+
+-- login and register are where credentials get guessed, the gateway picks the new
+-- limits up once its cached entry expires, within 10 minutes
+UPDATE api_managements
+SET rate_limit_ip = 10, rate_limit_window = 60
+WHERE endpoint_url LIKE '%/auth/login' OR endpoint_url LIKE '%/auth/register';
@@ -1,6 +1,7 @@
 -- for group is request group, policy group (g, _, _)
 INSERT INTO casbin_rule (ptype, v0, v1) VALUES ('g', 'admin', 'role_admin');
 INSERT INTO casbin_rule (ptype, v0, v1) VALUES ('g', 'user', 'role_user');
+INSERT INTO casbin_rule (ptype, v0, v1) VALUES ('g', 'customer_service', 'role_customer_service');
 
 -- for policy is policy group, resource, and action (p, _, endpoint, http method)
 -- examples :
@@ -9,3 +10,10 @@ INSERT INTO casbin_rule (ptype, v0, v1) VALUES ('g', 'user', 'role_user');
 INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES ('p', 'role_admin', 'ping', 'GET');
 INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES ('p', 'role_user', 'ping', 'GET');
 
+
+-- admin API of the gateway's routes
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES ('p', 'role_admin', 'admin/routes*', '(GET)|(POST)|(PUT)|(DELETE)');
+
+-- refunds of orders, the payment service checks the role again
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES ('p', 'role_admin', 'payments/*/refund', 'POST');
+INSERT INTO casbin_rule (ptype, v0, v1, v2) VALUES ('p', 'role_customer_service', 'payments/*/refund', 'POST');
@@ -5,6 +5,16 @@ CREATE TABLE "api_managements" (
   "endpoint_url" text NOT NULL,
   "hashed_endpoint_url" text UNIQUE NOT NULL,
   "is_available" boolean NOT NULL DEFAULT false,
+  "rate_limit_ip" int,
+  "rate_limit_user" int,
+  "rate_limit_route" int,
+  "rate_limit_window" int,
+  "cache_ttl" int,
+  "breaker_failure_threshold" int,
+  "breaker_open_timeout" int,
+  "breaker_half_open_probes" int,
+  "fallback_status" int,
+  "fallback_body" text,
   "created_at" timestamp NOT NULL DEFAULT (now()),
   "updated_at" timestamp NOT NULL DEFAULT (now())
 );
@@ -96,7 +106,7 @@ CREATE TABLE "products" (
   "description" text,
   "unit_price" float,
   "status" boolean,
-  "stock" int,
+  "stock" int CHECK ("stock" >= 0),
   "SKU" varchar(255),
   "weight" float,
   "created_at" timestamp DEFAULT (now()),
@@ -146,25 +156,96 @@ CREATE TABLE "orders" (
   "id" int PRIMARY KEY,
   "user_id" int,
   "shipping_id" int,
+  "subtotal" float,
+  "discount" float NOT NULL DEFAULT 0,
+  "shipping_fee" float NOT NULL DEFAULT 0,
+  "voucher_code" varchar(255),
   "total_price" float,
-  "status" varchar(255),
+  "status" varchar(255) NOT NULL DEFAULT 'pending_payment',
+  "order_number" varchar(255) UNIQUE NOT NULL,
+  "receipt_number" varchar(255),
   "created_at" timestamp DEFAULT (now()),
   "updated_at" timestamp DEFAULT (now())
 );
 
+CREATE TABLE "order_status_histories" (
+  "id" serial not null PRIMARY KEY,
+  "order_id" int NOT NULL,
+  "from_status" varchar(255),
+  "to_status" varchar(255) NOT NULL,
+  "note" text,
+  "created_at" timestamp DEFAULT (now())
+);
+
 CREATE TABLE "order_items" (
   "id" int PRIMARY KEY,
   "order_id" int,
   "product_id" int,
   "quantity" int,
   "unit_price" float,
+  "total_price" float,
   "created_at" timestamp DEFAULT (now()),
   "updated_at" timestamp DEFAULT (now())
 );
 
+CREATE TABLE "payment_logs" (
+  "id" serial not null PRIMARY KEY,
+  "order_id" int NOT NULL,
+  "user_id" int,
+  "kind" varchar(255) NOT NULL,
+  "transaction_id" varchar(255),
+  "snap_token" varchar(255),
+  "redirect_url" text,
+  "gross_amount" float,
+  "payment_type" varchar(255),
+  "status" varchar(255),
+  "refund_key" varchar(255) UNIQUE,
+  "raw_payload" jsonb,
+  "created_at" timestamp DEFAULT (now())
+);
+
+CREATE TABLE "payment_notifications" (
+  "id" serial not null PRIMARY KEY,
+  "transaction_id" varchar(255) NOT NULL,
+  "order_id" varchar(255) NOT NULL,
+  "transaction_status" varchar(255) NOT NULL,
+  "fraud_status" varchar(255),
+  "status_code" varchar(255),
+  "payment_type" varchar(255),
+  "gross_amount" varchar(255),
+  "payload" jsonb,
+  "processed" boolean NOT NULL DEFAULT false,
+  "created_at" timestamp DEFAULT (now()),
+  "updated_at" timestamp DEFAULT (now()),
+  UNIQUE ("transaction_id", "transaction_status")
+);
+
+CREATE TABLE "outbox" (
+  "id" bigserial not null PRIMARY KEY,
+  "source" varchar(255) NOT NULL,
+  "queue" varchar(255) NOT NULL,
+  "payload" jsonb NOT NULL,
+  "trace_headers" jsonb,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" text,
+  "next_attempt_at" timestamp NOT NULL DEFAULT (now()),
+  "sent_at" timestamp,
+  "created_at" timestamp DEFAULT (now())
+);
+
+CREATE TABLE "processed_messages" (
+  "consumer" varchar(255) NOT NULL,
+  "message_id" varchar(255) NOT NULL,
+  "processed_at" timestamp DEFAULT (now()),
+  PRIMARY KEY ("consumer", "message_id")
+);
+
 CREATE TABLE "shippings" (
   "id" int PRIMARY KEY,
-  "receipt_number" text
+  "name" varchar(255),
+  "fee" float NOT NULL DEFAULT 0,
+  "created_at" timestamp DEFAULT (now()),
+  "updated_at" timestamp DEFAULT (now())
 );
 
 CREATE TABLE "reviews" (
@@ -207,6 +288,22 @@ CREATE TABLE "promotion_codes" (
   "updated_at" timestamp DEFAULT (now())
 );
 
+COMMENT ON COLUMN "api_managements"."rate_limit_window" IS 'seconds, the rate_limit_* columns are requests per window, null uses the gateway default, 0 is no limit';
+
+COMMENT ON COLUMN "api_managements"."cache_ttl" IS 'seconds the gateway caches GET responses, null or 0 does not cache';
+
+COMMENT ON COLUMN "api_managements"."breaker_open_timeout" IS 'seconds, the breaker_* columns give the endpoint a circuit breaker of its own, null uses the gateway default';
+
+COMMENT ON COLUMN "api_managements"."fallback_body" IS 'JSON answered with fallback_status while the breaker is open, null answers 503';
+
+COMMENT ON COLUMN "orders"."status" IS 'pending_payment, paid, packed, shipped, delivered, completed, cancelled, refunded, partially_refunded';
+
+COMMENT ON COLUMN "payment_logs"."kind" IS 'snap, status_check, refund, expire';
+
+COMMENT ON COLUMN "outbox"."source" IS 'service that wrote the event, only its relay publishes it';
+
+COMMENT ON COLUMN "processed_messages"."message_id" IS 'amqp message id, written in the same transaction as the message write';
+
 COMMENT ON COLUMN "promotions"."start_date" IS 'keterangan tanggal voucher abis';
 
 ALTER TABLE "user_settings" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
@@ -241,6 +338,8 @@ ALTER TABLE "orders" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
 
 ALTER TABLE "orders" ADD FOREIGN KEY ("shipping_id") REFERENCES "shippings" ("id");
 
+ALTER TABLE "order_status_histories" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id");
+
 ALTER TABLE "order_items" ADD FOREIGN KEY ("order_id") REFERENCES "orders" ("id");
 
 ALTER TABLE "order_items" ADD FOREIGN KEY ("product_id") REFERENCES "products" ("id");
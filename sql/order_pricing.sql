@@ -0,0 +1,10 @@
+-- price snapshots stored on every order by the order service
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal float;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount float NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_fee float NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS voucher_code varchar(255);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_price float;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS total_price float;
+
+-- flat fee charged per order for each shipping option
+ALTER TABLE shippings ADD COLUMN IF NOT EXISTS fee float NOT NULL DEFAULT 0;
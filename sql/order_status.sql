@@ -0,0 +1,7 @@
+-- orders.status used to hold a boolean ("true" once paid), move existing rows to the named statuses
+UPDATE orders SET status = 'paid' WHERE status IN ('true', 't');
+UPDATE orders SET status = 'pending_payment' WHERE status IS NULL OR status IN ('false', 'f');
+
+-- seed the history so every existing order has its current status recorded
+INSERT INTO order_status_histories (order_id, to_status, note)
+SELECT id, status, 'migrated' FROM orders;
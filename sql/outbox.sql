@@ -0,0 +1,19 @@
+-- events waiting to be relayed to RabbitMQ by the service that wrote them
+CREATE TABLE IF NOT EXISTS outbox (
+  id bigserial not null PRIMARY KEY,
+  source varchar(255) NOT NULL,
+  queue varchar(255) NOT NULL,
+  payload jsonb NOT NULL,
+  trace_headers jsonb,
+  attempts int NOT NULL DEFAULT 0,
+  last_error text,
+  next_attempt_at timestamp NOT NULL DEFAULT (now()),
+  sent_at timestamp,
+  created_at timestamp DEFAULT (now())
+);
+
+-- the relays only ever look at unsent rows
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (source, next_attempt_at) WHERE sent_at IS NULL;
+
+-- the trace context of the request that stored the event, the relay publishes within it
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS trace_headers jsonb;
@@ -0,0 +1,2 @@
+-- refunds are logged in payment_logs under the idempotency key sent to the gateway
+ALTER TABLE payment_logs ADD COLUMN IF NOT EXISTS refund_key varchar(255) UNIQUE;
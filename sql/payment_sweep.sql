@@ -0,0 +1,7 @@
+-- unpaid orders the sweeper couldn't settle wait here before being checked again, so a few
+-- stuck orders don't take every batch from the newer ones
+CREATE TABLE IF NOT EXISTS "payment_sweep_checks" (
+  "order_id" int PRIMARY KEY REFERENCES "orders" ("id"),
+  "attempts" int NOT NULL DEFAULT 0,
+  "next_check_at" timestamp NOT NULL
+);
@@ -0,0 +1,10 @@
+-- messages every consumer has already applied, so a redelivery is skipped
+CREATE TABLE IF NOT EXISTS processed_messages (
+  consumer varchar(255) NOT NULL,
+  message_id varchar(255) NOT NULL,
+  processed_at timestamp DEFAULT (now()),
+  PRIMARY KEY (consumer, message_id)
+);
+
+-- lets old rows be pruned once no redelivery can arrive anymore
+CREATE INDEX IF NOT EXISTS processed_messages_processed_at_idx ON processed_messages (processed_at);
@@ -0,0 +1,9 @@
+-- keys the auth service signs tokens with, the private key is sealed with its
+-- JWT_KEY_ENCRYPTION_KEY. A key is deleted once no token it signed can still be valid
+CREATE TABLE IF NOT EXISTS signing_keys (
+  kid varchar(64) PRIMARY KEY,
+  private_key bytea NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX IF NOT EXISTS signing_keys_created_at_idx ON signing_keys (created_at);
@@ -0,0 +1,12 @@
+-- email accounts log in once their email is verified, the accounts there were before
+-- verification keep logging in
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name = 'users' AND column_name = 'email_verified_at'
+  ) THEN
+    ALTER TABLE users ADD COLUMN email_verified_at timestamp;
+    UPDATE users SET email_verified_at = created_at;
+  END IF;
+END $$;
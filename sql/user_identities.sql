@@ -0,0 +1,12 @@
+-- the accounts of OpenID Connect providers a user signs in with, a provider account is
+-- known by its subject since its email can change
+CREATE TABLE IF NOT EXISTS user_identities (
+  provider varchar(64) NOT NULL,
+  subject varchar(255) NOT NULL,
+  user_id int NOT NULL REFERENCES users (id),
+  email varchar(255) NOT NULL,
+  created_at timestamp DEFAULT (now()),
+  PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
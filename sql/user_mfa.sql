@@ -0,0 +1,18 @@
+-- the TOTP second factor of a user, the secret is sealed with JWT_KEY_ENCRYPTION_KEY. It
+-- logs in once enabled_at is set, by the first code of the enrollment
+CREATE TABLE IF NOT EXISTS user_mfa (
+  user_id int PRIMARY KEY REFERENCES users (id),
+  secret bytea NOT NULL,
+  enabled_at timestamp,
+  -- the time step of the last code taken, a code can't log in twice
+  last_used_step bigint NOT NULL DEFAULT 0,
+  created_at timestamp DEFAULT (now())
+);
+
+-- the one-time recovery codes of a user, HMAC-SHA256 of the code
+CREATE TABLE IF NOT EXISTS user_mfa_recovery_codes (
+  user_id int NOT NULL REFERENCES users (id),
+  code_hash varchar(64) NOT NULL,
+  used_at timestamp,
+  PRIMARY KEY (user_id, code_hash)
+);
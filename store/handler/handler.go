@@ -61,12 +61,13 @@ func (h *handler) Create(ctx *gin.Context) {
 		}
 	}
 
-	res, err := h.svc.Create(req)
+	err = h.svc.Create(ctx.Request.Context(), req)
 	if err != nil {
 		response.ResponseError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	response.ResponseSuccess(ctx, http.StatusOK, res)
+	// consumer-worker writes it shortly, it shows up in the list once it has
+	response.ResponseSuccess(ctx, http.StatusAccepted, req)
 }
 
 func (h *handler) Delete(ctx *gin.Context) {
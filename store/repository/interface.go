@@ -1,12 +1,13 @@
 package repository
 
 import (
+	"context"
 	"store-go/model"
 )
 
 type Repositorier interface {
 	Get() (res []model.Store, err error)
 	GetStoreByName(name string) (res model.Store, err error)
-	Create(req []model.StoreRequest) (res []model.Store, err error)
+	Create(ctx context.Context, req []model.StoreRequest) (err error)
 	Delete(storeID int) (err error)
 }
@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"store-go/model"
 	"store-go/publisher"
@@ -57,22 +56,12 @@ func (repo *repository) GetStoreByName(name string) (res model.Store, err error)
 	return
 }
 
-func (repo *repository) Create(req []model.StoreRequest) (res []model.Store, err error) {
-	// publish data to RabbitMQ
-	err = repo.publisher.Publish(req, "create_stores")
+// Create publishes the create_stores event, consumer-worker writes it. It returns once the event is
+// stored in the outbox, before consumer-worker has written it.
+func (repo *repository) Create(ctx context.Context, req []model.StoreRequest) (err error) {
+	err = repo.publisher.Publish(ctx, req, "create_stores")
 	if err != nil {
-		err = fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
-		return
-	}
-
-	time.Sleep(3 * time.Second)
-
-	for _, v := range req {
-		result, err := repo.GetStoreByName(v.Name)
-		if err != nil {
-			return []model.Store{}, errors.New("error get store by name after create")
-		}
-		res = append(res, result)
+		return fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
 	}
 	return
 }
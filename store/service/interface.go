@@ -1,12 +1,13 @@
 package service
 
 import (
+	"context"
 	"store-go/model"
 )
 
 type Servicer interface {
 	Get() (res []model.Store, err error)
 	GetStoreByName(name string) (res model.Store, err error)
-	Create(req []model.StoreRequest) (res []model.Store, err error)
+	Create(ctx context.Context, req []model.StoreRequest) (err error)
 	Delete(storeID int) (err error)
 }
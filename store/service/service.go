@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"store-go/model"
@@ -35,7 +36,7 @@ func (svc *service) GetStoreByName(name string) (res model.Store, err error) {
 	return
 }
 
-func (svc *service) Create(req []model.StoreRequest) (res []model.Store, err error) {
+func (svc *service) Create(ctx context.Context, req []model.StoreRequest) (err error) {
 	// check in each userID, ProductID already exist in store or not
 	for _, v := range req {
 		_, err := svc.GetStoreByName(v.Name)
@@ -43,11 +44,11 @@ func (svc *service) Create(req []model.StoreRequest) (res []model.Store, err err
 			continue
 		} else {
 			err = fmt.Errorf("store with name %s already exist", v.Name)
-			return []model.Store{}, err
+			return err
 		}
 	}
 
-	return svc.repo.Create(req)
+	return svc.repo.Create(ctx, req)
 }
 
 func (svc *service) Delete(storeID int) (err error) {
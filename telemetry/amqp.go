@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headers carries the trace context in AMQP message headers, amqp091's and go-rabbitmq's
+// Table convert to and from map[string]interface{}.
+type headers map[string]interface{}
+
+func (h headers) Get(key string) string {
+	v, _ := h[key].(string)
+	return v
+}
+
+func (h headers) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headers) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Inject writes the trace context of ctx into the message headers h, it returns h or new
+// headers when h is nil.
+func Inject(ctx context.Context, h map[string]interface{}) map[string]interface{} {
+	if h == nil {
+		h = map[string]interface{}{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headers(h))
+	return h
+}
+
+// Extract returns ctx with the trace context of the message headers h, for a message
+// published later than the request it belongs to, as the outbox relay does.
+func Extract(ctx context.Context, h map[string]interface{}) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headers(h))
+}
+
+// StartPublish starts the span of publishing a message to queue, Inject the returned ctx
+// into the message's headers and end the span once the broker took it.
+func StartPublish(ctx context.Context, queue string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, queue+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", queue),
+		),
+	)
+}
+
+// StartConsume starts the span of handling a message from queue, a child of the span that
+// published it when its headers carry one.
+func StartConsume(h map[string]interface{}, queue string) (context.Context, trace.Span) {
+	return tracer().Start(Extract(context.Background(), h), queue+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.source.name", queue),
+		),
+	)
+}
+
+// EndPublish ends the span of StartPublish, a failed publish is recorded on it and counted.
+func EndPublish(span trace.Span, queue string, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		PublishFailed(queue)
+	}
+	span.End()
+}
+
+// EndConsume ends the span of StartConsume, recording err when handling the message failed.
+func EndConsume(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
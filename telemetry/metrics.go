@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route and status.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to handle an HTTP request, by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	upstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_upstream_errors_total",
+		Help: "Requests the gateway couldn't get an answer to from the service, by reason.",
+	}, []string{"upstream", "reason"})
+
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gateway_circuit_breaker_state",
+		Help: "State of the circuit breaker of a service: 0 closed, 1 open, 2 half-open.",
+	}, []string{"upstream"})
+
+	publishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_publish_failures_total",
+		Help: "Messages that couldn't be published to RabbitMQ, by queue.",
+	}, []string{"queue"})
+)
+
+// Handler serves the metrics of the process, routed at /metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// UpstreamError counts a request to upstream, a service name, that failed for reason.
+func UpstreamError(upstream, reason string) {
+	upstreamErrors.WithLabelValues(upstream, reason).Inc()
+}
+
+// BreakerState records the state of the circuit breaker of upstream.
+func BreakerState(upstream string, state int) {
+	breakerState.WithLabelValues(upstream).Set(float64(state))
+}
+
+// PublishFailed counts a message to queue that couldn't be published.
+func PublishFailed(queue string) {
+	publishFailures.WithLabelValues(queue).Inc()
+}
@@ -0,0 +1,77 @@
+// Package telemetry is what every service exposes about itself: Prometheus metrics on
+// /metrics and OpenTelemetry traces. The trace context travels in HTTP headers between
+// the gateway and the services and in the message headers over RabbitMQ, so one checkout
+// can be followed from the gateway to the order service to the consumer-worker.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation = "telemetry-go"
+
+// Setup installs the tracer of service and returns its shutdown, which flushes the spans
+// not exported yet. Spans are exported over OTLP/HTTP to endpoint, http://collector:4318
+// for instance. With an empty endpoint they are still propagated, only not exported.
+func Setup(ctx context.Context, service, endpoint string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return SetupWithExporter(service, nil).Shutdown, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid otlp endpoint %q", endpoint)
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return SetupWithExporter(service, exporter).Shutdown, nil
+}
+
+// SetupWithExporter is Setup exporting to exporter, tests pass an in-memory one and
+// ForceFlush the provider before reading it.
+func SetupWithExporter(service string, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service))),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	return provider
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Detach keeps the span of ctx but drops its deadline and cancellation, for work that
+// has its own timeout and must not stop when the request that started it does.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
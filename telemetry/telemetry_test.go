@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		path      string
+		route     string
+		wantRoute string
+	}{
+		{name: "path pattern", path: "/products/7", wantRoute: "/products/:id"},
+		{name: "named route", path: "/products/8", route: "product detail", wantRoute: "product detail"},
+		{name: "unmatched", path: "/nothing", wantRoute: "unmatched"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := SetupWithExporter("product", exporter)
+			t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+			router := gin.New()
+			router.Use(Middleware("product"))
+			router.GET("/products/:id", func(ctx *gin.Context) {
+				if tt.route != "" {
+					SetRoute(ctx, tt.route)
+				}
+				ctx.Status(http.StatusOK)
+			})
+
+			before := testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, tt.wantRoute, "200")) +
+				testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, tt.wantRoute, "404"))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			after := testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, tt.wantRoute, "200")) +
+				testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, tt.wantRoute, "404"))
+			require.Equal(t, before+1, after)
+
+			require.NoError(t, provider.ForceFlush(context.Background()))
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+			require.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+		})
+	}
+}
+
+func TestAMQPPropagation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := SetupWithExporter("order", exporter)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	ctx, publish := StartPublish(context.Background(), "create_order")
+	h := Inject(ctx, nil)
+	EndPublish(publish, "create_order", nil)
+	require.NotEmpty(t, h["traceparent"])
+
+	_, consume := StartConsume(h, "create_order")
+	EndConsume(consume, errors.New("order rejected"))
+
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, trace.SpanKindProducer, spans[0].SpanKind)
+	require.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind)
+	require.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	require.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+func TestDetach(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := SetupWithExporter("order", exporter)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	parent, cancel := context.WithCancel(context.Background())
+	parent, span := tracer().Start(parent, "request")
+	cancel()
+
+	detached := Detach(parent)
+	require.NoError(t, detached.Err())
+	require.Equal(t, span.SpanContext(), trace.SpanContextFromContext(detached))
+	span.End()
+
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	require.Len(t, exporter.GetSpans(), 1)
+}
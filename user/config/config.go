@@ -5,8 +5,9 @@ import (
 )
 
 type Config struct {
-	Debug bool   `mapstructure:"DEBUG"`
-	Port  string `mapstructure:"PORT"`
+	Debug        bool   `mapstructure:"DEBUG"`
+	Port         string `mapstructure:"PORT"`
+	OTLPEndpoint string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 
 	Database `mapstructure:",squash"`
 	Redis    `mapstructure:",squash"`
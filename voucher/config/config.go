@@ -14,6 +14,7 @@ type Config struct {
 	PgPort         string `mapstructure:"PGPORT"`
 	PgUser         string `mapstructure:"PGUSER"`
 	Port           string `mapstructure:"PORT"`
+	OTLPEndpoint   string `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	Debug          bool   `mapstructure:"DEBUG"`
 	RabbitMQ       string `mapstructure:"RABBITMQ"`
 }
@@ -47,7 +47,7 @@ func (h *handler) CreateVoucher(ctx *gin.Context) {
 	err := ctx.ShouldBindJSON(&data)
 	failerror.FailError(err, "error bind json")
 
-	res, err := h.svc.CreateVoucher(data)
+	res, err := h.svc.CreateVoucher(ctx.Request.Context(), data)
 	if err != nil {
 		response.ResponseError(ctx, res.Status, err)
 	} else {
@@ -1,13 +1,15 @@
 package main
 
 import (
+	"context"
+	"outbox-go"
+	"telemetry-go"
 	"voucher-go/config"
 	"voucher-go/handler"
 	"voucher-go/helper/failerror"
 	"voucher-go/helper/logging"
 	"voucher-go/helper/middleware"
 	"voucher-go/package/db"
-	"voucher-go/publisher"
 	"voucher-go/repository"
 	"voucher-go/service"
 
@@ -22,11 +24,23 @@ func main() {
 
 	logger := logging.New(conf.Debug)
 
+	shutdownTracer, errTracer := telemetry.Setup(context.Background(), "voucher", conf.OTLPEndpoint)
+	if errTracer != nil {
+		logger.Fatal().Err(errTracer).Msg("tracer failed to start")
+	}
+	defer func() {
+		if errShut := shutdownTracer(context.Background()); errShut != nil {
+			logger.Error().Err(errShut).Msg("tracer failed to flush")
+		}
+	}()
+
 	db, err := db.NewGormDB(conf.Debug, conf.DatabaseDriver, conf.DatabaseURL)
 	failerror.FailError(err, "error new gorm")
 	logger.Debug().Msg("DB Connected")
 
-	pub := publisher.NewPublisher()
+	// events are written to the outbox table and relayed to RabbitMQ in the background
+	pub := outbox.New(db.SQLDB, conf.RabbitMQ, "voucher")
+	go pub.Run(context.Background())
 
 	repoVoucher := repository.NewRepository(db.SQLDB, pub)
 	Voucher := service.NewService(repoVoucher)
@@ -40,6 +54,8 @@ func NewServer(hand handler.Handlerer, logger *zerolog.Logger) {
 	failerror.FailError(err, "error loadconfig")
 	// server
 	r := gin.New()
+	r.Use(telemetry.Middleware("voucher"))
+	r.GET("/metrics", gin.WrapH(telemetry.Handler()))
 
 	// middleware
 	r.Use(middleware.Logger(logger))
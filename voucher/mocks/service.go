@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"voucher-go/model"
 
 	"github.com/stretchr/testify/mock"
@@ -27,8 +28,8 @@ func (m *ServiceMock) ShowVoucher(code string) (model.Voucher, error) {
 	return result, err
 }
 
-func (m *ServiceMock) CreateVoucher(req []model.VoucherReq) ([]model.Voucher, error) {
-	ret := m.Called(req)
+func (m *ServiceMock) CreateVoucher(ctx context.Context, req []model.VoucherReq) ([]model.Voucher, error) {
+	ret := m.Called(ctx, req)
 	result := ret.Get(0).([]model.Voucher)
 	err := ret.Error(1)
 	return result, err
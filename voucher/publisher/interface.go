@@ -1,5 +1,7 @@
 package publisher
 
+import "context"
+
 type Publisher interface {
-	Public(req any, queueName string) error
+	Publish(ctx context.Context, body any, queueName string) error
 }
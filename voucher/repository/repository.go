@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"time"
@@ -11,6 +12,9 @@ import (
 	"voucher-go/publisher"
 )
 
+// ErrVoucherNotFound is a code no voucher has.
+var ErrVoucherNotFound = errors.New("code voucher not found")
+
 type repository struct {
 	db   *sql.DB
 	sent publisher.Publisher
@@ -57,16 +61,16 @@ func (repo *repository) ShowVoucher(code string) (model.Voucher, error) {
 	}
 
 	if temp.Id == 0 {
-		return temp, errors.New("code voucher not found")
+		return temp, ErrVoucherNotFound
 	}
 
 	return temp, nil
 }
 
-func (repo *repository) CreateVoucher(req []model.VoucherReq) ([]model.Voucher, error) {
-	ctx, cancel := timeout.NewCtxTimeout()
-	defer cancel()
-
+// CreateVoucher publishes the create_voucher event, consumer-worker writes the vouchers. It returns
+// the vouchers with their codes once the event is stored in the outbox, the code finds each one
+// once consumer-worker has written it.
+func (repo *repository) CreateVoucher(ctx context.Context, req []model.VoucherReq) ([]model.Voucher, error) {
 	var sent []model.Voucher
 
 	for _, v := range req {
@@ -90,39 +94,12 @@ func (repo *repository) CreateVoucher(req []model.VoucherReq) ([]model.Voucher,
 		sent = append(sent, inrandom)
 	}
 
-	err := repo.sent.Public(sent, "create_voucher")
+	err := repo.sent.Publish(ctx, sent, "create_voucher")
 	if err != nil {
 		return nil, errors.New("failed publisher")
 	}
 
-	time.Sleep(1 * time.Second)
-
-	var resultss []model.Voucher
-	query := `select * from voucher where code = $1`
-
-	stmt, err := repo.db.PrepareContext(ctx, query)
-	failerror.FailError(err, "error prepare")
-
-	for _, v := range sent {
-
-		result, err := stmt.QueryContext(ctx, v.Code)
-		failerror.FailError(err, "error prepare")
-
-		var temp model.Voucher
-		for result.Next() {
-			result.Scan(&temp.Id, &temp.StoreID, &temp.ProductID, &temp.CategoryID, &temp.Discount, &temp.Name, &temp.Code, &temp.StartDate, &temp.EndDate, &temp.Created_at, &temp.Update_at)
-		}
-		if temp.Id == 0 {
-			continue
-		}
-		resultss = append(resultss, temp)
-	}
-
-	if resultss == nil {
-		return nil, errors.New("error create product")
-	}
-
-	return resultss, nil
+	return sent, nil
 }
 
 func (repo *repository) DeleteVoucher(id int) (int, error) {
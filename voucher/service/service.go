@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"voucher-go/model"
@@ -44,6 +45,12 @@ func (svc *service) ShowVoucher(code string) (model.Respon, error) {
 
 	// start
 	res, err := svc.repo.ShowVoucher(code)
+	if errors.Is(err, repository.ErrVoucherNotFound) {
+		return model.Respon{
+			Status: http.StatusNotFound,
+			Data:   nil,
+		}, err
+	}
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
@@ -56,7 +63,7 @@ func (svc *service) ShowVoucher(code string) (model.Respon, error) {
 	}, nil
 }
 
-func (svc *service) CreateVoucher(req []model.VoucherReq) (model.Respon, error) {
+func (svc *service) CreateVoucher(ctx context.Context, req []model.VoucherReq) (model.Respon, error) {
 
 	var check []model.VoucherReq
 
@@ -87,15 +94,16 @@ func (svc *service) CreateVoucher(req []model.VoucherReq) (model.Respon, error)
 	}
 
 	// start
-	res, err := svc.repo.CreateVoucher(check)
+	res, err := svc.repo.CreateVoucher(ctx, check)
 	if err != nil {
 		return model.Respon{
 			Status: http.StatusInternalServerError,
 			Data:   nil,
 		}, err
 	}
+	// consumer-worker writes them shortly, the code finds each one
 	return model.Respon{
-		Status: http.StatusOK,
+		Status: http.StatusAccepted,
 		Data:   res,
 	}, nil
 }
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
 	"voucher-go/mocks"
 	"voucher-go/model"
+	"voucher-go/repository"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -75,6 +79,12 @@ func TestServiceDetail(t *testing.T) {
 			wantRes: model.Voucher{},
 			err:     fmt.Errorf("some error"),
 			wantErr: true,
+		}, {
+			name:    "failed get detail voucher unknown code",
+			code:    "unknown",
+			wantRes: model.Voucher{},
+			err:     repository.ErrVoucherNotFound,
+			wantErr: true,
 		}, {
 			name:    "failed get detail voucher no code",
 			code:    "ajsdfhkuj",
@@ -97,6 +107,9 @@ func TestServiceDetail(t *testing.T) {
 			} else if res.Status == http.StatusBadRequest {
 				require.Error(t, err)
 				require.Equal(t, http.StatusBadRequest, res.Status)
+			} else if errors.Is(tt.err, repository.ErrVoucherNotFound) {
+				require.ErrorIs(t, err, repository.ErrVoucherNotFound)
+				require.Equal(t, http.StatusNotFound, res.Status)
 			} else if res.Status == http.StatusInternalServerError {
 				require.Error(t, err)
 				require.Equal(t, http.StatusInternalServerError, res.Status)
@@ -162,15 +175,18 @@ func TestServiceCreate(t *testing.T) {
 			repoMock := mocks.NewServiceMock()
 			service := NewService(repoMock)
 
-			repoMock.On("CreateVoucher", tt.req).Return(tt.wantRes, tt.err)
+			repoMock.On("CreateVoucher", mock.Anything, tt.req).Return(tt.wantRes, tt.err)
 
-			res, err := service.CreateVoucher(tt.req)
-			if res.Status == http.StatusOK {
+			res, err := service.CreateVoucher(context.Background(), tt.req)
+			if res.Status == http.StatusAccepted {
 				require.NoError(t, err)
-				require.Equal(t, http.StatusOK, res.Status)
+				require.Equal(t, tt.wantRes, res.Data)
 			} else if res.Status == http.StatusBadRequest {
 				require.Error(t, err)
 				require.Equal(t, http.StatusBadRequest, res.Status)
+			} else if errors.Is(tt.err, repository.ErrVoucherNotFound) {
+				require.ErrorIs(t, err, repository.ErrVoucherNotFound)
+				require.Equal(t, http.StatusNotFound, res.Status)
 			} else if res.Status == http.StatusInternalServerError {
 				require.Error(t, err)
 				require.Equal(t, http.StatusInternalServerError, res.Status)
@@ -221,6 +237,9 @@ func TestServiceDelete(t *testing.T) {
 			} else if res.Status == http.StatusBadRequest {
 				require.Error(t, err)
 				require.Equal(t, http.StatusBadRequest, res.Status)
+			} else if errors.Is(tt.err, repository.ErrVoucherNotFound) {
+				require.ErrorIs(t, err, repository.ErrVoucherNotFound)
+				require.Equal(t, http.StatusNotFound, res.Status)
 			} else if res.Status == http.StatusInternalServerError {
 				require.Error(t, err)
 				require.Equal(t, http.StatusInternalServerError, res.Status)
@@ -2,8 +2,8 @@ package handler
 
 import (
 	"fmt"
-	"net/http"
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"strconv"
 	"wishlist-go/helper/response"
 	"wishlist-go/model"
@@ -43,13 +43,13 @@ func (h *handler) Get(ctx *gin.Context) {
 }
 
 func (h *handler) GetDetail(ctx *gin.Context) {
-	userIDString, ok := ctx.GetQuery("user_id")	
+	userIDString, ok := ctx.GetQuery("user_id")
 	if !ok {
 		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("query param user_id should not be empty"))
 		return
 	}
 
-	wishlistIDString, ok := ctx.GetQuery("user_id")	
+	wishlistIDString, ok := ctx.GetQuery("user_id")
 	if !ok {
 		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("query param wishlist_id should not be empty"))
 		return
@@ -66,7 +66,7 @@ func (h *handler) GetDetail(ctx *gin.Context) {
 		response.ResponseError(ctx, http.StatusBadRequest, err)
 		return
 	}
-		
+
 	if userID <= 0 {
 		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("userID must be positive number"))
 		return
@@ -106,16 +106,17 @@ func (h *handler) Create(ctx *gin.Context) {
 		}
 	}
 
-	res, err := h.svc.Create(req)
+	err = h.svc.Create(ctx.Request.Context(), req)
 	if err != nil {
 		response.ResponseError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	response.ResponseSuccess(ctx, http.StatusOK, res)
+	// consumer-worker writes it shortly, it shows up in the list once it has
+	response.ResponseSuccess(ctx, http.StatusAccepted, req)
 }
 
 func (h *handler) Delete(ctx *gin.Context) {
-	wishlistIDString, ok := ctx.GetQuery("wishlist_id")	
+	wishlistIDString, ok := ctx.GetQuery("wishlist_id")
 	if !ok {
 		response.ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("query param user_id should not be empty"))
 		return
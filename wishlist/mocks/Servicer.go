@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	model "wishlist-go/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,30 +15,18 @@ type Servicer struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: req
-func (_m *Servicer) Create(req []model.WishlistRequest) ([]model.Wishlist, error) {
-	ret := _m.Called(req)
+// Create provides a mock function with given fields: ctx, req
+func (_m *Servicer) Create(ctx context.Context, req []model.WishlistRequest) error {
+	ret := _m.Called(ctx, req)
 
-	var r0 []model.Wishlist
-	var r1 error
-	if rf, ok := ret.Get(0).(func([]model.WishlistRequest) ([]model.Wishlist, error)); ok {
-		return rf(req)
-	}
-	if rf, ok := ret.Get(0).(func([]model.WishlistRequest) []model.Wishlist); ok {
-		r0 = rf(req)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Wishlist)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func([]model.WishlistRequest) error); ok {
-		r1 = rf(req)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.WishlistRequest) error); ok {
+		r0 = rf(ctx, req)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
 // Delete provides a mock function with given fields: userID, wishlistID
@@ -1,5 +1,7 @@
 package publisher
 
+import "context"
+
 type PublisherInterface interface {
-	Publish(body interface{}, queueName string) (err error)
-}
\ No newline at end of file
+	Publish(ctx context.Context, body interface{}, queueName string) (err error)
+}
@@ -2,6 +2,7 @@
 package repository
 
 import (
+	"context"
 	"wishlist-go/model"
 )
 
@@ -9,6 +10,6 @@ type Repositorier interface {
 	Get(userID int) (res []model.Wishlist, err error)
 	GetByID(wishlistID int) (res model.Wishlist, err error)
 	GetDetail(userID, productID int) (res model.Wishlist, err error)
-	Create(req []model.WishlistRequest) (res []model.Wishlist, err error)
+	Create(ctx context.Context, req []model.WishlistRequest) (err error)
 	Delete(wishlistID int) (err error)
-}
\ No newline at end of file
+}
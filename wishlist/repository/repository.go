@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 	"wishlist-go/model"
@@ -73,22 +72,12 @@ func (repo *repository) GetDetail(userID, productID int) (res model.Wishlist, er
 	return
 }
 
-func (repo *repository) Create(req []model.WishlistRequest) (res []model.Wishlist, err error) {
-	// publish data to RabbitMQ
-	err = repo.publisher.Publish(req, "create_wishlists")
+// Create publishes the create_wishlists event, consumer-worker writes it. It returns once the event is
+// stored in the outbox, before consumer-worker has written it.
+func (repo *repository) Create(ctx context.Context, req []model.WishlistRequest) (err error) {
+	err = repo.publisher.Publish(ctx, req, "create_wishlists")
 	if err != nil {
-		err = fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
-		return
-	}
-
-	time.Sleep(3 * time.Second)
-
-	for _, v := range req {
-		result, err := repo.GetDetail(v.UserID, v.ProductID)
-		if err != nil {
-			return []model.Wishlist{}, errors.New("error get by user id after create")
-		}
-		res = append(res, result)
+		return fmt.Errorf("error publish data to RabbitMQ : %s", err.Error())
 	}
 	return
 }
@@ -2,12 +2,13 @@
 package service
 
 import (
+	"context"
 	"wishlist-go/model"
 )
 
 type Servicer interface {
 	Get(userID int) (res []model.Wishlist, err error)
 	GetDetail(userID, wishlistID int) (res model.Wishlist, err error)
-	Create(req []model.WishlistRequest) (res []model.Wishlist, err error)
+	Create(ctx context.Context, req []model.WishlistRequest) (err error)
 	Delete(wishlistID int) (err error)
-}
\ No newline at end of file
+}
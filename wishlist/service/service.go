@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"wishlist-go/model"
@@ -35,7 +36,7 @@ func (svc *service) GetDetail(userID, productID int) (res model.Wishlist, err er
 	return
 }
 
-func (svc *service) Create(req []model.WishlistRequest) (res []model.Wishlist, err error) {
+func (svc *service) Create(ctx context.Context, req []model.WishlistRequest) (err error) {
 	// check in each userID, ProductID already exist in wishlist or not
 	for _, v := range req {
 		_, err := svc.GetDetail(v.UserID, v.ProductID)
@@ -43,11 +44,11 @@ func (svc *service) Create(req []model.WishlistRequest) (res []model.Wishlist, e
 			continue
 		} else {
 			err = fmt.Errorf("wishlist with product_id %d in user_id %d already exist", v.ProductID, v.UserID)
-			return []model.Wishlist{}, err
+			return err
 		}
 	}
 
-	return svc.repo.Create(req)
+	return svc.repo.Create(ctx, req)
 }
 
 func (svc *service) Delete(wishlistID int) (err error) {
@@ -57,6 +58,6 @@ func (svc *service) Delete(wishlistID int) (err error) {
 	if res == emptyStruct {
 		return fmt.Errorf("item with id %d not found", wishlistID)
 	}
-	
+
 	return svc.repo.Delete(wishlistID)
-}
\ No newline at end of file
+}